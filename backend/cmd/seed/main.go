@@ -81,6 +81,9 @@ func runSeed(ctx context.Context, db *gorm.DB, adminPassword string) error {
 	if err := ensureProduct(ctx, db, company); err != nil {
 		return err
 	}
+	if err := ensureProfessional(ctx, db, company); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -163,6 +166,50 @@ func ensureProduct(ctx context.Context, db *gorm.DB, company domain.Company) err
 		Create(&product).Error
 }
 
+func ensureProfessional(ctx context.Context, db *gorm.DB, company domain.Company) error {
+	var count int64
+	if err := db.WithContext(ctx).
+		Model(&domain.Professional{}).
+		Where("tenant_id = ? AND name = ?", company.ID, "Profissional Demo").
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var admin domain.User
+	if err := db.WithContext(ctx).Where("tenant_id = ? AND email = ?", company.ID, demoEmail).First(&admin).Error; err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		professional := domain.Professional{
+			TenantModel: domain.TenantModel{TenantID: company.ID},
+			UserID:      &admin.ID,
+			Name:        "Profissional Demo",
+			MaxParallel: 1,
+			Active:      true,
+		}
+		if err := tx.Create(&professional).Error; err != nil {
+			return err
+		}
+
+		// Segunda a sábado, 09:00–18:00.
+		rules := make([]domain.AvailabilityRule, 0, 6)
+		for weekday := 1; weekday <= 6; weekday++ {
+			rules = append(rules, domain.AvailabilityRule{
+				TenantModel:    domain.TenantModel{TenantID: company.ID},
+				ProfessionalID: professional.ID,
+				Weekday:        weekday,
+				StartTime:      "09:00",
+				EndTime:        "18:00",
+			})
+		}
+		return tx.Create(&rules).Error
+	})
+}
+
 func resolveSeedPassword() string {
 	if value := strings.TrimSpace(os.Getenv("SEED_ADMIN_PASSWORD")); value != "" {
 		return value
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
+	"github.com/kusmin/gestao_updev/backend/internal/config"
+	"github.com/kusmin/gestao_updev/backend/internal/jobs"
+	"github.com/kusmin/gestao_updev/backend/internal/repository"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+	"github.com/kusmin/gestao_updev/backend/pkg/database"
+	"github.com/kusmin/gestao_updev/backend/pkg/logger"
+)
+
+// O worker consome a fila de tarefas em Postgres. Pode rodar em várias réplicas;
+// SIGINT/SIGTERM encerram a busca por tarefas e aguardam as que estão em andamento.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	zapLogger, err := logger.New(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("init logger: %v", err)
+	}
+	defer func() {
+		if err := zapLogger.Sync(); err != nil {
+			log.Printf("failed to sync zap logger: %v", err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.New(database.Config{
+		URL:             cfg.DatabaseURL,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	})
+	if err != nil {
+		zapLogger.Fatal("failed to initialize database", zap.Error(err))
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		zapLogger.Fatal("failed to expose database handle", zap.Error(err))
+	}
+	defer sqlDB.Close()
+
+	jwtManager := auth.NewJWTManager(cfg.JWTAccessSecret, cfg.JWTRefreshSecret, cfg.JWTAccessTTL, cfg.JWTRefreshTTL).
+		WithRefreshTokenLength(cfg.RefreshTokenLength)
+	svc := service.New(cfg, repository.New(db), jwtManager, zapLogger)
+
+	queue := jobs.New(db, zapLogger, jobs.Options{
+		Concurrency:       cfg.WorkerConcurrency,
+		TenantConcurrency: cfg.WorkerTenantLimit,
+		PollInterval:      cfg.WorkerPollInterval,
+	})
+	svc.RegisterJobs(queue)
+
+	if err := queue.Run(ctx); err != nil {
+		zapLogger.Fatal("worker stopped with error", zap.Error(err))
+	}
+	zapLogger.Info("worker stopped")
+}
@@ -1,6 +1,11 @@
 package auth
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
@@ -11,6 +16,8 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	TenantID string `json:"tenant_id"`
 	Role     string `json:"role"`
+	// SessionID identifica a sessão (família de refresh tokens) que emitiu o token.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -21,12 +28,26 @@ type TokenPair struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
-// JWTManager encapsula geração e validação de tokens.
+// Tamanho, em bytes aleatórios, dos refresh tokens.
+const (
+	defaultRefreshTokenLength = 64
+	minRefreshTokenLength     = 32
+)
+
+// mfaTokenTTL limita o intervalo entre a senha e o segundo fator no login.
+const mfaTokenTTL = 5 * time.Minute
+
+// JWTManager encapsula geração e validação de tokens. Access tokens são JWT
+// stateless; refresh tokens são valores opacos cujo hash é persistido por sessão.
 type JWTManager struct {
 	accessSecret  []byte
 	refreshSecret []byte
 	accessTTL     time.Duration
 	refreshTTL    time.Duration
+	refreshLength int
+	// mfaSecret assina os tokens de desafio MFA; derivado do segredo de acesso para que
+	// um desafio nunca seja aceito como access token.
+	mfaSecret []byte
 }
 
 // NewJWTManager cria o gerador/validador padrão.
@@ -36,16 +57,33 @@ func NewJWTManager(accessSecret, refreshSecret string, accessTTL, refreshTTL tim
 		refreshSecret: []byte(refreshSecret),
 		accessTTL:     accessTTL,
 		refreshTTL:    refreshTTL,
+		refreshLength: defaultRefreshTokenLength,
+		mfaSecret:     deriveKey([]byte(accessSecret), "mfa-challenge"),
+	}
+}
+
+// WithRefreshTokenLength define quantos bytes aleatórios compõem o refresh token.
+// Valores abaixo de 32 são elevados a 32.
+func (m *JWTManager) WithRefreshTokenLength(length int) *JWTManager {
+	if length < minRefreshTokenLength {
+		length = minRefreshTokenLength
 	}
+	m.refreshLength = length
+	return m
 }
 
-// GenerateTokens devolve o par access/refresh.
-func (m *JWTManager) GenerateTokens(userID, tenantID, role string) (*TokenPair, error) {
-	access, err := m.GenerateAccessToken(userID, tenantID, role)
+// RefreshTTL devolve a validade dos refresh tokens.
+func (m *JWTManager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// GenerateTokens devolve o access token da sessão e um novo refresh token opaco.
+func (m *JWTManager) GenerateTokens(userID, tenantID, role, sessionID string) (*TokenPair, error) {
+	access, err := m.generateToken(userID, tenantID, role, sessionID, m.accessTTL, m.accessSecret)
 	if err != nil {
 		return nil, err
 	}
-	refresh, err := m.GenerateRefreshToken(userID, tenantID, role)
+	refresh, err := m.GenerateRefreshToken()
 	if err != nil {
 		return nil, err
 	}
@@ -59,20 +97,33 @@ func (m *JWTManager) GenerateTokens(userID, tenantID, role string) (*TokenPair,
 
 // GenerateAccessToken cria um token de curta duração.
 func (m *JWTManager) GenerateAccessToken(userID, tenantID, role string) (string, error) {
-	return m.generateToken(userID, tenantID, role, m.accessTTL, m.accessSecret)
+	return m.generateToken(userID, tenantID, role, "", m.accessTTL, m.accessSecret)
+}
+
+// GenerateRefreshToken cria um refresh token opaco; apenas o hash deve ser armazenado.
+func (m *JWTManager) GenerateRefreshToken() (string, error) {
+	buf := make([]byte, m.refreshLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
 
-// GenerateRefreshToken cria um token de renovação.
-func (m *JWTManager) GenerateRefreshToken(userID, tenantID, role string) (string, error) {
-	return m.generateToken(userID, tenantID, role, m.refreshTTL, m.refreshSecret)
+// HashRefreshToken devolve o HMAC-SHA256 do refresh token com o segredo de refresh,
+// de modo que um vazamento da tabela não permite forjar nem reconhecer tokens.
+func (m *JWTManager) HashRefreshToken(token string) string {
+	mac := hmac.New(sha256.New, m.refreshSecret)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
-func (m *JWTManager) generateToken(userID, tenantID, role string, ttl time.Duration, secret []byte) (string, error) {
+func (m *JWTManager) generateToken(userID, tenantID, role, sessionID string, ttl time.Duration, secret []byte) (string, error) {
 	now := time.Now()
 	claims := &Claims{
-		UserID:   userID,
-		TenantID: tenantID,
-		Role:     role,
+		UserID:    userID,
+		TenantID:  tenantID,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
@@ -82,16 +133,27 @@ func (m *JWTManager) generateToken(userID, tenantID, role string, ttl time.Durat
 	return token.SignedString(secret)
 }
 
+// GenerateMFAToken emite o token de desafio entregue após a senha, trocado pelos tokens
+// da sessão quando o segundo fator é confirmado.
+func (m *JWTManager) GenerateMFAToken(userID, tenantID, role string) (string, error) {
+	return m.generateToken(userID, tenantID, role, "", mfaTokenTTL, m.mfaSecret)
+}
+
+// ValidateMFAToken valida o token de desafio MFA e extrai as claims.
+func (m *JWTManager) ValidateMFAToken(token string) (*Claims, error) {
+	return m.parseClaims(token, m.mfaSecret)
+}
+
+// MFATokenTTL devolve a validade dos tokens de desafio MFA.
+func (m *JWTManager) MFATokenTTL() time.Duration {
+	return mfaTokenTTL
+}
+
 // ValidateAccessToken valida e extrai as claims do access token.
 func (m *JWTManager) ValidateAccessToken(token string) (*Claims, error) {
 	return m.parseClaims(token, m.accessSecret)
 }
 
-// ValidateRefreshToken valida e extrai as claims do refresh token.
-func (m *JWTManager) ValidateRefreshToken(token string) (*Claims, error) {
-	return m.parseClaims(token, m.refreshSecret)
-}
-
 func (m *JWTManager) parseClaims(token string, secret []byte) (*Claims, error) {
 	parsed, err := jwt.ParseWithClaims(token, &Claims{}, func(_ *jwt.Token) (interface{}, error) {
 		return secret, nil
@@ -104,3 +166,9 @@ func (m *JWTManager) parseClaims(token string, secret []byte) (*Claims, error) {
 	}
 	return nil, jwt.ErrTokenInvalidClaims
 }
+
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
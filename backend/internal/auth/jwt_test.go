@@ -12,7 +12,7 @@ func TestGenerateTokensReturnsPair(t *testing.T) {
 
 	manager := NewJWTManager("access-secret", "refresh-secret", 2*time.Minute, time.Hour)
 
-	pair, err := manager.GenerateTokens("user-123", "tenant-abc", "admin")
+	pair, err := manager.GenerateTokens("user-123", "tenant-abc", "admin", "session-1")
 
 	require.NoError(t, err)
 	require.NotEmpty(t, pair.AccessToken)
@@ -36,18 +36,50 @@ func TestValidateAccessToken(t *testing.T) {
 	require.WithinDuration(t, time.Now().Add(time.Minute), claims.RegisteredClaims.ExpiresAt.Time, 5*time.Second)
 }
 
-func TestValidateRefreshTokenRequiresMatchingSecret(t *testing.T) {
+func TestGenerateTokensEmbedsSessionID(t *testing.T) {
+	t.Parallel()
+
+	manager := NewJWTManager("access-secret", "refresh-secret", time.Minute, time.Hour)
+	pair, err := manager.GenerateTokens("user-123", "tenant-abc", "admin", "session-1")
+	require.NoError(t, err)
+
+	claims, err := manager.ValidateAccessToken(pair.AccessToken)
+
+	require.NoError(t, err)
+	require.Equal(t, "session-1", claims.SessionID)
+}
+
+func TestGenerateRefreshTokenIsOpaqueAndRandom(t *testing.T) {
+	t.Parallel()
+
+	manager := NewJWTManager("access-secret", "refresh-secret", time.Minute, time.Hour).WithRefreshTokenLength(48)
+	first, err := manager.GenerateRefreshToken()
+	require.NoError(t, err)
+	second, err := manager.GenerateRefreshToken()
+	require.NoError(t, err)
+
+	require.Len(t, first, 64, "48 bytes em base64url sem padding")
+	require.NotEqual(t, first, second)
+
+	short := NewJWTManager("access-secret", "refresh-secret", time.Minute, time.Hour).WithRefreshTokenLength(8)
+	token, err := short.GenerateRefreshToken()
+	require.NoError(t, err)
+	require.Len(t, token, 43, "tamanho mínimo de 32 bytes")
+}
+
+func TestHashRefreshTokenRequiresMatchingSecret(t *testing.T) {
 	t.Parallel()
 
 	issuer := NewJWTManager("access-secret", "refresh-secret", time.Minute, time.Hour)
-	token, err := issuer.GenerateRefreshToken("user-123", "tenant-abc", "admin")
+	token, err := issuer.GenerateRefreshToken()
 	require.NoError(t, err)
 
-	otherManager := NewJWTManager("access-secret", "other-refresh", time.Minute, time.Hour)
-	claims, err := otherManager.ValidateRefreshToken(token)
+	hash := issuer.HashRefreshToken(token)
+	require.Equal(t, hash, issuer.HashRefreshToken(token))
+	require.NotContains(t, hash, token)
 
-	require.Error(t, err)
-	require.Nil(t, claims)
+	otherManager := NewJWTManager("access-secret", "other-refresh", time.Minute, time.Hour)
+	require.NotEqual(t, hash, otherManager.HashRefreshToken(token))
 }
 
 func TestValidateAccessTokenFailsWhenExpired(t *testing.T) {
@@ -62,3 +94,24 @@ func TestValidateAccessTokenFailsWhenExpired(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, claims)
 }
+
+func TestMFATokenIsNotAnAccessToken(t *testing.T) {
+	t.Parallel()
+
+	manager := NewJWTManager("access-secret", "refresh-secret", time.Minute, time.Hour)
+	token, err := manager.GenerateMFAToken("user-123", "tenant-abc", "admin")
+	require.NoError(t, err)
+
+	claims, err := manager.ValidateMFAToken(token)
+	require.NoError(t, err)
+	require.Equal(t, "user-123", claims.UserID)
+	require.WithinDuration(t, time.Now().Add(manager.MFATokenTTL()), claims.RegisteredClaims.ExpiresAt.Time, 5*time.Second)
+
+	_, err = manager.ValidateAccessToken(token)
+	require.Error(t, err)
+
+	access, err := manager.GenerateAccessToken("user-123", "tenant-abc", "admin")
+	require.NoError(t, err)
+	_, err = manager.ValidateMFAToken(access)
+	require.Error(t, err)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/base64"
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
@@ -22,26 +23,48 @@ var (
 
 // Config centraliza parâmetros de execução do backend.
 type Config struct {
-	AppEnv             string        `env:"APP_ENV" envDefault:"development"`
-	ServiceName        string        `env:"SERVICE_NAME" envDefault:"gestao-api"`
-	HTTPPort           int           `env:"HTTP_PORT" envDefault:"8080"`
-	TenantHeader       string        `env:"TENANT_HEADER" envDefault:"X-Tenant-ID"`
-	LogLevel           string        `env:"LOG_LEVEL" envDefault:"info"`
-	DatabaseURL        string        `env:"DATABASE_URL"`
-	DBMaxIdleConns     int           `env:"DB_MAX_IDLE_CONNS" envDefault:"5"`
-	DBMaxOpenConns     int           `env:"DB_MAX_OPEN_CONNS" envDefault:"20"`
-	DBConnMaxLifetime  time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"1h"`
-	JWTAccessSecret    string        `env:"JWT_ACCESS_SECRET"`
-	JWTRefreshSecret   string        `env:"JWT_REFRESH_SECRET"`
-	JWTAccessTTL       time.Duration `env:"JWT_ACCESS_TTL" envDefault:"15m"`
-	JWTRefreshTTL      time.Duration `env:"JWT_REFRESH_TTL" envDefault:"720h"`
-	BcryptCost         int           `env:"BCRYPT_COST" envDefault:"12"`
-	RefreshTokenLength int           `env:"REFRESH_TOKEN_LENGTH" envDefault:"64"`
-	TelemetryEnabled   bool          `env:"OTEL_ENABLED" envDefault:"false"`
-	OTLPEndpoint       string        `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
-	OTLPHeaders        string        `env:"OTEL_EXPORTER_OTLP_HEADERS"`
-	OTLPInsecure       bool          `env:"OTEL_EXPORTER_OTLP_INSECURE" envDefault:"false"`
-	MetricsRoute       string        `env:"METRICS_ROUTE" envDefault:"/metrics"`
+	AppEnv               string        `env:"APP_ENV" envDefault:"development"`
+	ServiceName          string        `env:"SERVICE_NAME" envDefault:"gestao-api"`
+	HTTPPort             int           `env:"HTTP_PORT" envDefault:"8080"`
+	TenantHeader         string        `env:"TENANT_HEADER" envDefault:"X-Tenant-ID"`
+	TrustedProxies       []string      `env:"TRUSTED_PROXIES" envSeparator:","`
+	LogLevel             string        `env:"LOG_LEVEL" envDefault:"info"`
+	DatabaseURL          string        `env:"DATABASE_URL"`
+	DBMaxIdleConns       int           `env:"DB_MAX_IDLE_CONNS" envDefault:"5"`
+	DBMaxOpenConns       int           `env:"DB_MAX_OPEN_CONNS" envDefault:"20"`
+	DBConnMaxLifetime    time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"1h"`
+	JWTAccessSecret      string        `env:"JWT_ACCESS_SECRET"`
+	JWTRefreshSecret     string        `env:"JWT_REFRESH_SECRET"`
+	JWTAccessTTL         time.Duration `env:"JWT_ACCESS_TTL" envDefault:"15m"`
+	JWTRefreshTTL        time.Duration `env:"JWT_REFRESH_TTL" envDefault:"720h"`
+	BcryptCost           int           `env:"BCRYPT_COST" envDefault:"12"`
+	RefreshTokenLength   int           `env:"REFRESH_TOKEN_LENGTH" envDefault:"64"`
+	AppBaseURL           string        `env:"APP_BASE_URL" envDefault:"http://localhost:5173"`
+	PasswordResetTTL     time.Duration `env:"PASSWORD_RESET_TTL" envDefault:"1h"`
+	EmailVerificationTTL time.Duration `env:"EMAIL_VERIFICATION_TTL" envDefault:"48h"`
+	LoginMaxFailures     int           `env:"LOGIN_MAX_FAILURES" envDefault:"5"`
+	LoginIPMaxFailures   int           `env:"LOGIN_IP_MAX_FAILURES" envDefault:"50"`
+	LoginFailureWindow   time.Duration `env:"LOGIN_FAILURE_WINDOW" envDefault:"15m"`
+	LoginLockoutDuration time.Duration `env:"LOGIN_LOCKOUT_DURATION" envDefault:"15m"`
+	TelemetryEnabled     bool          `env:"OTEL_ENABLED" envDefault:"false"`
+	OTLPEndpoint         string        `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTLPHeaders          string        `env:"OTEL_EXPORTER_OTLP_HEADERS"`
+	OTLPInsecure         bool          `env:"OTEL_EXPORTER_OTLP_INSECURE" envDefault:"false"`
+	MetricsRoute         string        `env:"METRICS_ROUTE" envDefault:"/metrics"`
+	SMTPHost             string        `env:"SMTP_HOST"`
+	SMTPPort             int           `env:"SMTP_PORT" envDefault:"587"`
+	SMTPUsername         string        `env:"SMTP_USERNAME"`
+	SMTPPassword         string        `env:"SMTP_PASSWORD"`
+	SMTPFrom             string        `env:"SMTP_FROM"`
+	SMSGatewayURL        string        `env:"SMS_GATEWAY_URL"`
+	SMSGatewayToken      string        `env:"SMS_GATEWAY_TOKEN"`
+	WhatsAppGatewayURL   string        `env:"WHATSAPP_GATEWAY_URL"`
+	WhatsAppGatewayToken string        `env:"WHATSAPP_GATEWAY_TOKEN"`
+	APIBackgroundTasks   bool          `env:"API_BACKGROUND_TASKS" envDefault:"true"`
+	WorkerConcurrency    int           `env:"WORKER_CONCURRENCY" envDefault:"4"`
+	WorkerTenantLimit    int           `env:"WORKER_TENANT_CONCURRENCY" envDefault:"2"`
+	WorkerPollInterval   time.Duration `env:"WORKER_POLL_INTERVAL" envDefault:"1s"`
+	WebhookAllowPrivate  bool          `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
 }
 
 // Load lê variáveis de ambiente e monta a configuração.
@@ -53,6 +76,9 @@ func Load() (*Config, error) {
 	if err := cfg.ensureProtectedValues(); err != nil {
 		return nil, err
 	}
+	if err := cfg.validateTrustedProxies(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -61,6 +87,16 @@ func (c *Config) Address() string {
 	return fmt.Sprintf(":%d", c.HTTPPort)
 }
 
+// IsDevelopment indica ambiente local ou de testes, em que exigências como HTTPS nos
+// webhooks são relaxadas.
+func (c *Config) IsDevelopment() bool {
+	switch strings.ToLower(c.AppEnv) {
+	case "", "development", "test":
+		return true
+	}
+	return false
+}
+
 func (c *Config) ensureProtectedValues() error {
 	isProd := strings.EqualFold(c.AppEnv, "production")
 
@@ -97,6 +133,21 @@ func (c *Config) ensureProtectedValues() error {
 	return nil
 }
 
+// validateTrustedProxies exige IPs ou CIDRs: só desses endereços o X-Forwarded-For é aceito.
+func (c *Config) validateTrustedProxies() error {
+	for i, proxy := range c.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		c.TrustedProxies[i] = proxy
+		if _, _, err := net.ParseCIDR(proxy); err == nil {
+			continue
+		}
+		if net.ParseIP(proxy) == nil {
+			return fmt.Errorf("TRUSTED_PROXIES must contain IPs or CIDRs, got %q", proxy)
+		}
+	}
+	return nil
+}
+
 func decodeDefaultSecret(encoded string) string {
 	decoded, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
@@ -11,8 +11,10 @@ import (
 const (
 	BookingStatusPending   = "pending"
 	BookingStatusConfirmed = "confirmed"
+	BookingStatusCheckedIn = "checked_in"
 	BookingStatusDone      = "done"
 	BookingStatusCanceled  = "canceled"
+	BookingStatusNoShow    = "no_show"
 )
 
 const (
@@ -27,12 +29,88 @@ const (
 	UserRoleUser  = "user"
 )
 
+const (
+	AvailabilityExceptionBlocked = "blocked"
+	AvailabilityExceptionOpen    = "open"
+)
+
+const (
+	WaitlistStatusWaiting  = "waiting"
+	WaitlistStatusOffered  = "offered"
+	WaitlistStatusBooked   = "booked"
+	WaitlistStatusExpired  = "expired"
+	WaitlistStatusCanceled = "canceled"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+const (
+	BookingReminderPending = "pending"
+	BookingReminderSent    = "sent"
+	BookingReminderFailed  = "failed"
+	BookingReminderSkipped = "skipped"
+)
+
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusDead    = "dead"
+)
+
+// Motivos de revogação de sessões.
+const (
+	SessionRevokedLogout    = "logout"
+	SessionRevokedLogoutAll = "logout_all"
+	SessionRevokedReuse     = "token_reuse"
+	SessionRevokedManual    = "revoked"
+	SessionRevokedPassword  = "password_reset"
+)
+
+// Chaves contabilizadas pelo limitador de tentativas de login.
+const (
+	LoginThrottleEmail = "email"
+	LoginThrottleIP    = "ip"
+)
+
+// Finalidades dos tokens de uso único enviados por e-mail.
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// Chaves dos templates de e-mail transacional.
+const (
+	EmailTemplateSignup              = "signup"
+	EmailTemplatePasswordReset       = "password_reset"
+	EmailTemplateEmailVerification   = "email_verification"
+	EmailTemplateBookingConfirmation = "booking_confirmation"
+	EmailTemplateBookingReminder     = "booking_reminder"
+	EmailTemplateSalesReceipt        = "sales_receipt"
+)
+
 const (
 	InventoryMovementIn         = "in"
 	InventoryMovementOut        = "out"
 	InventoryMovementAdjustment = "adjustment"
 )
 
+// Chaves reconhecidas em Company.Settings.
+const (
+	SettingAllowNegativeStock = "allow_negative_stock"
+	SettingAllowOverpayment   = "allow_overpayment"
+	SettingSlotGranularity    = "slot_granularity_minutes"
+	SettingWaitlistHold       = "waitlist_hold_minutes"
+	SettingRemindersEnabled   = "reminders_enabled"
+	SettingReminderOffsets    = "reminder_offsets_minutes"
+	SettingReminderChannels   = "reminder_channels"
+	SettingRequireAdminMFA    = "require_admin_mfa"
+)
+
 // BaseModel consolida campos comuns de auditoria.
 type BaseModel struct {
 	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
@@ -64,14 +142,20 @@ type Company struct {
 
 type User struct {
 	TenantModel
-	Name         string            `gorm:"size:120;not null" json:"name"`
-	Email        string            `gorm:"size:160;not null;index:idx_users_email_tenant,unique" json:"email"`
-	Phone        string            `gorm:"size:32" json:"phone"`
-	Role         string            `gorm:"size:32;not null" json:"role"`
-	PasswordHash string            `gorm:"size:255;not null" json:"-"`
-	Profile      datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"profile"`
-	Active       bool              `gorm:"default:true" json:"active"`
-	LastLoginAt  *time.Time        `json:"last_login_at,omitempty"`
+	Name            string            `gorm:"size:120;not null" json:"name"`
+	Email           string            `gorm:"size:160;not null;index:idx_users_email_tenant,unique" json:"email"`
+	Phone           string            `gorm:"size:32" json:"phone"`
+	Role            string            `gorm:"size:32;not null" json:"role"`
+	PasswordHash    string            `gorm:"size:255;not null" json:"-"`
+	Profile         datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"profile"`
+	Active          bool              `gorm:"default:true" json:"active"`
+	LastLoginAt     *time.Time        `json:"last_login_at,omitempty"`
+	EmailVerifiedAt *time.Time        `json:"email_verified_at,omitempty"`
+	// MFASecret é preenchido no início do cadastro do TOTP e passa a valer com MFAEnabledAt.
+	MFASecret    string     `gorm:"size:64;not null;default:''" json:"-"`
+	MFAEnabledAt *time.Time `json:"mfa_enabled_at,omitempty"`
+	// MFALastStep guarda o último passo TOTP aceito, impedindo reutilizar o mesmo código.
+	MFALastStep int64 `gorm:"not null;default:0" json:"-"`
 }
 
 type Client struct {
@@ -102,6 +186,17 @@ type AvailabilityRule struct {
 	EndTime        string    `gorm:"size:8;not null" json:"end_time"`
 }
 
+// AvailabilityException bloqueia ou abre a agenda em um intervalo específico.
+// Sem ProfessionalID a exceção vale para todos os profissionais da empresa.
+type AvailabilityException struct {
+	TenantModel
+	ProfessionalID *uuid.UUID `gorm:"type:uuid;index" json:"professional_id"`
+	Kind           string     `gorm:"size:16;not null" json:"kind"`
+	StartAt        time.Time  `gorm:"not null" json:"start_at"`
+	EndAt          time.Time  `gorm:"not null" json:"end_at"`
+	Reason         string     `gorm:"type:text" json:"reason"`
+}
+
 type Service struct {
 	TenantModel
 	Name            string            `gorm:"size:160;not null;index:idx_services_name_tenant,unique" json:"name"`
@@ -127,11 +222,13 @@ type Product struct {
 
 type InventoryMovement struct {
 	TenantModel
-	ProductID uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
-	OrderID   *uuid.UUID `gorm:"type:uuid" json:"order_id"`
-	Type      string     `gorm:"size:16;not null" json:"type"`
-	Quantity  int        `gorm:"not null" json:"quantity"`
-	Reason    string     `gorm:"size:160" json:"reason"`
+	ProductID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	OrderID      *uuid.UUID `gorm:"type:uuid" json:"order_id"`
+	Type         string     `gorm:"size:16;not null" json:"type"`
+	Quantity     int        `gorm:"not null" json:"quantity"`
+	Reason       string     `gorm:"size:160" json:"reason"`
+	BalanceAfter int        `gorm:"not null;default:0" json:"balance_after"`
+	Flagged      bool       `gorm:"not null;default:false" json:"flagged"`
 }
 
 type Booking struct {
@@ -139,11 +236,48 @@ type Booking struct {
 	ClientID       uuid.UUID         `gorm:"type:uuid;not null;index" json:"client_id"`
 	ProfessionalID uuid.UUID         `gorm:"type:uuid;not null;index" json:"professional_id"`
 	ServiceID      uuid.UUID         `gorm:"type:uuid;not null" json:"service_id"`
+	SeriesID       *uuid.UUID        `gorm:"type:uuid;index" json:"series_id"`
 	Status         string            `gorm:"size:32;not null" json:"status"`
 	StartAt        time.Time         `gorm:"not null" json:"start_at"`
 	EndAt          time.Time         `gorm:"not null" json:"end_at"`
 	Notes          string            `gorm:"type:text" json:"notes"`
 	Metadata       datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"metadata"`
+	ConfirmedAt    *time.Time        `json:"confirmed_at"`
+	CheckedInAt    *time.Time        `json:"checked_in_at"`
+	CompletedAt    *time.Time        `json:"completed_at"`
+	CanceledAt     *time.Time        `json:"canceled_at"`
+	NoShowAt       *time.Time        `json:"no_show_at"`
+	// Segments detalha agendamentos com vários serviços; vazio em agendamentos simples.
+	Segments []BookingSegment `gorm:"foreignKey:BookingID;references:ID" json:"segments,omitempty"`
+}
+
+// BookingSegment etapa de um agendamento com vários serviços, executada em sequência.
+type BookingSegment struct {
+	TenantModel
+	BookingID      uuid.UUID `gorm:"type:uuid;not null;index" json:"booking_id"`
+	Position       int       `gorm:"not null" json:"position"`
+	ServiceID      uuid.UUID `gorm:"type:uuid;not null" json:"service_id"`
+	ProfessionalID uuid.UUID `gorm:"type:uuid;not null;index" json:"professional_id"`
+	StartAt        time.Time `gorm:"not null" json:"start_at"`
+	EndAt          time.Time `gorm:"not null" json:"end_at"`
+}
+
+// WaitlistEntry cliente aguardando vaga para um serviço dentro de uma janela desejada.
+// Quando um horário compatível é liberado, a vaga fica reservada (Offer*) até OfferExpiresAt.
+type WaitlistEntry struct {
+	TenantModel
+	ClientID            uuid.UUID  `gorm:"type:uuid;not null;index" json:"client_id"`
+	ServiceID           uuid.UUID  `gorm:"type:uuid;not null" json:"service_id"`
+	ProfessionalID      *uuid.UUID `gorm:"type:uuid" json:"professional_id"`
+	DesiredFrom         time.Time  `gorm:"not null" json:"desired_from"`
+	DesiredTo           time.Time  `gorm:"not null" json:"desired_to"`
+	Status              string     `gorm:"size:16;not null" json:"status"`
+	Notes               string     `gorm:"type:text" json:"notes"`
+	OfferProfessionalID *uuid.UUID `gorm:"type:uuid" json:"offer_professional_id"`
+	OfferStartAt        *time.Time `json:"offer_start_at"`
+	OfferEndAt          *time.Time `json:"offer_end_at"`
+	OfferExpiresAt      *time.Time `json:"offer_expires_at"`
+	BookingID           *uuid.UUID `gorm:"type:uuid" json:"booking_id"`
 }
 
 type SalesOrder struct {
@@ -154,10 +288,18 @@ type SalesOrder struct {
 	PaymentType string      `gorm:"size:32" json:"payment_method"`
 	Total       float64     `gorm:"type:numeric(12,2);default:0" json:"total"`
 	Discount    float64     `gorm:"type:numeric(12,2);default:0" json:"discount"`
+	AmountPaid  float64     `gorm:"type:numeric(12,2);not null;default:0" json:"amount_paid"`
+	BalanceDue  float64     `gorm:"-" json:"balance_due"`
 	Notes       string      `gorm:"type:text" json:"notes"`
 	Items       []SalesItem `gorm:"foreignKey:OrderID;references:ID" json:"items"`
 }
 
+// AfterFind calcula o saldo em aberto do pedido.
+func (o *SalesOrder) AfterFind(*gorm.DB) error {
+	o.BalanceDue = o.Total - o.AmountPaid
+	return nil
+}
+
 type SalesItem struct {
 	TenantModel
 	OrderID   uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
@@ -169,17 +311,169 @@ type SalesItem struct {
 
 type Payment struct {
 	TenantModel
-	OrderID uuid.UUID         `gorm:"type:uuid;not null;index" json:"order_id"`
-	Method  string            `gorm:"size:32;not null" json:"method"`
-	Amount  float64           `gorm:"type:numeric(12,2);not null" json:"amount"`
-	PaidAt  time.Time         `gorm:"not null" json:"paid_at"`
-	Details datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"details"`
+	OrderID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"order_id"`
+	Method         string            `gorm:"size:32;not null" json:"method"`
+	Amount         float64           `gorm:"type:numeric(12,2);not null" json:"amount"`
+	PaidAt         time.Time         `gorm:"not null" json:"paid_at"`
+	Details        datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"details"`
+	Flagged        bool              `gorm:"not null;default:false" json:"flagged"`
+	RefundedAmount float64           `gorm:"type:numeric(12,2);not null;default:0" json:"refunded_amount"`
+}
+
+// Refund estorna total ou parcialmente um pagamento.
+type Refund struct {
+	TenantModel
+	OrderID    uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	PaymentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Amount     float64   `gorm:"type:numeric(12,2);not null" json:"amount"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	RefundedAt time.Time `gorm:"not null" json:"refunded_at"`
+}
+
+// WebhookSubscription endpoint do tenant que recebe os eventos de EventTypes ("*" para todos),
+// assinados com Secret.
+type WebhookSubscription struct {
+	TenantModel
+	URL         string         `gorm:"size:500;not null" json:"url"`
+	Secret      string         `gorm:"size:128;not null" json:"secret,omitempty"`
+	EventTypes  datatypes.JSON `gorm:"type:jsonb;not null" json:"event_types"`
+	Description string         `gorm:"type:text" json:"description"`
+	Active      bool           `gorm:"not null;default:true" json:"active"`
+}
+
+// WebhookDelivery entrada da outbox: gravada na mesma transação do evento e enviada
+// de forma assíncrona, com novas tentativas até ser entregue ou ir para dead.
+type WebhookDelivery struct {
+	TenantModel
+	SubscriptionID uuid.UUID      `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	EventID        uuid.UUID      `gorm:"type:uuid;not null" json:"event_id"`
+	EventType      string         `gorm:"size:64;not null" json:"event_type"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string         `gorm:"size:16;not null" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time      `gorm:"not null" json:"next_attempt_at"`
+	LastStatusCode int            `json:"last_status_code"`
+	LastError      string         `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+}
+
+// BookingReminder controla o envio de um lembrete por agendamento, antecedência e canal;
+// a unicidade dessa combinação torna o envio idempotente.
+type BookingReminder struct {
+	TenantModel
+	BookingID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:uq_booking_reminders_booking_offset_channel" json:"booking_id"`
+	OffsetMinutes int        `gorm:"not null;uniqueIndex:uq_booking_reminders_booking_offset_channel" json:"offset_minutes"`
+	Channel       string     `gorm:"size:16;not null;uniqueIndex:uq_booking_reminders_booking_offset_channel" json:"channel"`
+	Recipient     string     `gorm:"size:160" json:"recipient"`
+	Status        string     `gorm:"size:16;not null" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+}
+
+// EmailTemplate personalização do tenant para um template de e-mail. Inativo, vale o
+// template padrão do sistema; cada alteração gera uma EmailTemplateVersion.
+type EmailTemplate struct {
+	TenantModel
+	Key      string `gorm:"size:64;not null;index" json:"key"`
+	Subject  string `gorm:"type:text;not null" json:"subject"`
+	TextBody string `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody string `gorm:"type:text" json:"html_body"`
+	Version  int    `gorm:"not null" json:"version"`
+	Active   bool   `gorm:"not null;default:true" json:"active"`
+}
+
+// EmailTemplateVersion histórico imutável do conteúdo de um EmailTemplate.
+type EmailTemplateVersion struct {
+	TenantModel
+	TemplateKey string     `gorm:"size:64;not null;index" json:"template_key"`
+	Version     int        `gorm:"not null" json:"version"`
+	Subject     string     `gorm:"type:text;not null" json:"subject"`
+	TextBody    string     `gorm:"type:text;not null" json:"text_body"`
+	HTMLBody    string     `gorm:"type:text" json:"html_body"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by"`
 }
 
+// Job tarefa assíncrona da fila em Postgres. TenantID é nulo em tarefas do sistema;
+// UniqueKey impede duas tarefas pendentes ou em execução com a mesma chave.
+type Job struct {
+	BaseModel
+	TenantID    *uuid.UUID     `gorm:"type:uuid;index" json:"tenant_id"`
+	Type        string         `gorm:"size:64;not null" json:"type"`
+	Payload     datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	Status      string         `gorm:"size:16;not null" json:"status"`
+	Attempts    int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int            `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time      `gorm:"not null" json:"run_at"`
+	UniqueKey   *string        `gorm:"size:160;uniqueIndex:uq_jobs_unique_key,where:status = 'pending' OR status = 'running'" json:"unique_key"`
+	LockedAt    *time.Time     `json:"locked_at"`
+	LockedBy    string         `gorm:"size:64" json:"locked_by"`
+	LastError   string         `gorm:"type:text" json:"last_error"`
+	CompletedAt *time.Time     `json:"completed_at"`
+}
+
+// UserSession sessão criada em um login; agrupa a família de refresh tokens emitidos
+// a partir dele. Revogar a sessão invalida todos os tokens da família.
+type UserSession struct {
+	TenantModel
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Device        string     `gorm:"size:64" json:"device"`
+	UserAgent     string     `gorm:"size:255" json:"user_agent"`
+	IPAddress     string     `gorm:"size:64" json:"ip_address"`
+	LastUsedAt    time.Time  `gorm:"not null" json:"last_used_at"`
+	ExpiresAt     time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `gorm:"size:32" json:"revoked_reason,omitempty"`
+}
+
+// RefreshToken hash de um refresh token opaco. Cada token vale para uma única rotação:
+// ao ser usado recebe UsedAt, e reapresentá-lo revoga a sessão inteira.
+type RefreshToken struct {
+	TenantModel
+	SessionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// UserToken token de uso único enviado por e-mail (redefinição de senha, verificação
+// de e-mail). Apenas o hash SHA-256 é persistido.
+type UserToken struct {
+	TenantModel
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:32;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// UserRecoveryCode código de recuperação de uso único do MFA; apenas o hash SHA-256 é persistido.
+type UserRecoveryCode struct {
+	TenantModel
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+}
+
+// LoginThrottle contabiliza as falhas de login de um e-mail ou IP. Fica no banco para
+// que o limite valha entre todas as instâncias da API.
+type LoginThrottle struct {
+	BaseModel
+	Scope         string     `gorm:"size:16;not null;uniqueIndex:uq_login_throttles_scope_key" json:"scope"`
+	Key           string     `gorm:"size:160;not null;uniqueIndex:uq_login_throttles_scope_key" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt *time.Time `json:"last_failure_at,omitempty"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// AuditLog registra uma mutação: quem (ActorID, nulo em ações do sistema ou anônimas),
+// em qual requisição e, em Metadata["changes"], o antes/depois de cada campo alterado.
 type AuditLog struct {
 	TenantModel
-	Entity   string            `gorm:"size:64;not null" json:"entity"`
-	Action   string            `gorm:"size:64;not null" json:"action"`
-	ActorID  uuid.UUID         `gorm:"type:uuid;not null" json:"actor_id"`
-	Metadata datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"metadata"`
+	Entity    string            `gorm:"size:64;not null" json:"entity"`
+	EntityID  *uuid.UUID        `gorm:"type:uuid" json:"entity_id"`
+	Action    string            `gorm:"size:64;not null" json:"action"`
+	ActorID   *uuid.UUID        `gorm:"type:uuid" json:"actor_id"`
+	RequestID string            `gorm:"size:64" json:"request_id"`
+	Metadata  datatypes.JSONMap `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 }
@@ -34,3 +34,30 @@ func UserID(c *gin.Context) (uuid.UUID, error) {
 	}
 	return uuid.Parse(userStr)
 }
+
+// UserRole extrai o papel do usuário autenticado.
+func UserRole(c *gin.Context) (string, error) {
+	value, exists := c.Get(middleware.ContextUserRoleKey)
+	if !exists {
+		return "", fmt.Errorf("user_role not found in context")
+	}
+	role, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("user_role inválido")
+	}
+	return role, nil
+}
+
+// SessionID extrai a sessão do access token; tokens sem sessão resultam em erro.
+func SessionID(c *gin.Context) (uuid.UUID, error) {
+	value := c.GetString(middleware.ContextSessionIDKey)
+	if value == "" {
+		return uuid.Nil, fmt.Errorf("session_id not found in context")
+	}
+	return uuid.Parse(value)
+}
+
+// RequestID devolve o identificador da requisição, ou vazio quando ausente.
+func RequestID(c *gin.Context) string {
+	return c.GetString(middleware.ContextRequestIDKey)
+}
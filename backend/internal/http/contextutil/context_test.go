@@ -77,8 +77,61 @@ func TestUserIDErrorsWhenTypeInvalid(t *testing.T) {
 	require.Equal(t, uuid.Nil, uid)
 }
 
+func TestUserRoleSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := testContext()
+	ctx.Set(middleware.ContextUserRoleKey, "admin")
+
+	role, err := UserRole(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, "admin", role)
+}
+
+func TestUserRoleErrorsWhenMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := testContext()
+
+	role, err := UserRole(ctx)
+
+	require.Error(t, err)
+	require.Empty(t, role)
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := testContext()
+	require.Empty(t, RequestID(ctx))
+
+	ctx.Set(middleware.ContextRequestIDKey, "req-123")
+	require.Equal(t, "req-123", RequestID(ctx))
+}
+
 func testContext() *gin.Context {
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
 	return ctx
 }
+
+func TestSessionIDSuccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := testContext()
+	expected := uuid.New()
+	ctx.Set(middleware.ContextSessionIDKey, expected.String())
+
+	actual, err := SessionID(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestSessionIDErrorsWhenEmpty(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := testContext()
+	ctx.Set(middleware.ContextSessionIDKey, "")
+
+	session, err := SessionID(ctx)
+
+	require.Error(t, err)
+	require.Equal(t, uuid.Nil, session)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *API) RegisterAdminAuditRoutes(router *gin.RouterGroup) {
+	router.GET("/audit-logs", h.ListAllAuditLogs)
+}
+
+// ListAllAuditLogs lista a auditoria de todos os tenants; aceita tenant_id além dos
+// filtros de GET /audit-logs.
+func (h *API) ListAllAuditLogs(c *gin.Context) {
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, total, err := h.svc.ListAllAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": logs, "meta": metaPagination(filter.Page, filter.PerPage, total)})
+}
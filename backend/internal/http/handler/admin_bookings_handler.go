@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -43,7 +44,13 @@ func (h *API) ListAllBookings(c *gin.Context) {
 
 type AdminCreateBookingInput struct {
 	service.BookingInput
-	TenantID string `json:"tenant_id" binding:"required"`
+	TenantID             string `json:"tenant_id" binding:"required"`
+	OverrideAvailability bool   `json:"override_availability"`
+}
+
+type AdminUpdateBookingInput struct {
+	service.BookingUpdateInput
+	OverrideAvailability bool `json:"override_availability"`
 }
 
 func (h *API) AdminCreateBooking(c *gin.Context) {
@@ -63,10 +70,11 @@ func (h *API) AdminCreateBooking(c *gin.Context) {
 		BookingInput: input.BookingInput,
 		TenantID:     tenantID,
 	}
+	adminInput.OverrideAvailability = input.OverrideAvailability
 
 	booking, err := h.svc.AdminCreateBooking(c.Request.Context(), adminInput)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(adminBookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -80,15 +88,16 @@ func (h *API) AdminUpdateBooking(c *gin.Context) {
 		return
 	}
 
-	var input service.BookingUpdateInput
+	var input AdminUpdateBookingInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	input.BookingUpdateInput.OverrideAvailability = input.OverrideAvailability
 
-	booking, err := h.svc.AdminUpdateBooking(c.Request.Context(), id, input)
+	booking, err := h.svc.AdminUpdateBooking(c.Request.Context(), id, input.BookingUpdateInput)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(adminBookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -109,3 +118,16 @@ func (h *API) AdminDeleteBooking(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// adminBookingErrorStatus separa erros de regra de negócio de falhas internas.
+func adminBookingErrorStatus(err error) int {
+	var transitionErr *service.InvalidTransitionError
+	switch {
+	case errors.As(err, &transitionErr), errors.Is(err, service.ErrOutsideAvailability):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrBookingConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/middleware"
+)
+
+// adminOnlyRoute descreve uma rota que deve recusar usuários sem papel de administrador.
+type adminOnlyRoute struct {
+	method  string
+	path    string
+	handler func(*API) gin.HandlerFunc
+}
+
+var adminOnlyRoutes = []adminOnlyRoute{
+	{http.MethodGet, "/webhooks", func(h *API) gin.HandlerFunc { return h.ListWebhooks }},
+	{http.MethodPost, "/webhooks", func(h *API) gin.HandlerFunc { return h.CreateWebhook }},
+	{http.MethodPut, "/webhooks/:id", func(h *API) gin.HandlerFunc { return h.UpdateWebhook }},
+	{http.MethodDelete, "/webhooks/:id", func(h *API) gin.HandlerFunc { return h.DeleteWebhook }},
+	{http.MethodGet, "/webhook-deliveries", func(h *API) gin.HandlerFunc { return h.ListWebhookDeliveries }},
+	{http.MethodPost, "/webhook-deliveries/:id/retry", func(h *API) gin.HandlerFunc { return h.RetryWebhookDelivery }},
+	{http.MethodPost, "/professionals", func(h *API) gin.HandlerFunc { return h.CreateProfessional }},
+	{http.MethodPut, "/professionals/:id", func(h *API) gin.HandlerFunc { return h.UpdateProfessional }},
+	{http.MethodDelete, "/professionals/:id", func(h *API) gin.HandlerFunc { return h.DeactivateProfessional }},
+	{http.MethodPut, "/professionals/:id/availability", func(h *API) gin.HandlerFunc { return h.ReplaceProfessionalAvailability }},
+	{http.MethodPost, "/availability/exceptions", func(h *API) gin.HandlerFunc { return h.CreateAvailabilityException }},
+	{http.MethodDelete, "/availability/exceptions/:id", func(h *API) gin.HandlerFunc { return h.DeleteAvailabilityException }},
+	{http.MethodPost, "/inventory/products/:id/rebuild", func(h *API) gin.HandlerFunc { return h.RebuildProductStock }},
+}
+
+// TestAdminOnlyRoutesRejectMembers confere que a checagem de papel vem antes de qualquer
+// acesso ao service, que aqui nem existe.
+func TestAdminOnlyRoutesRejectMembers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	api := New(nil, zap.NewNop())
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextTenantIDKey, uuid.NewString())
+		c.Set(middleware.ContextUserIDKey, uuid.NewString())
+		c.Set(middleware.ContextUserRoleKey, domain.UserRoleUser)
+		c.Next()
+	})
+	for _, route := range adminOnlyRoutes {
+		router.Handle(route.method, route.path, route.handler(api))
+	}
+
+	params := strings.NewReplacer(":id", uuid.NewString())
+	for _, route := range adminOnlyRoutes {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(route.method, params.Replace(route.path), nil)
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code, "%s %s", route.method, route.path)
+	}
+}
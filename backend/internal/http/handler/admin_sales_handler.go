@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -70,6 +71,11 @@ func (h *API) AdminUpdateSalesOrder(c *gin.Context) {
 	}
 
 	salesOrder, err := h.svc.AdminUpdateSalesOrder(c.Request.Context(), id, input)
+	var transitionErr *service.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/contextutil"
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+)
+
+// AuditActor repassa ao service layer o usuário autenticado (quando houver), o
+// request ID e o cliente, para que as mutações sejam auditadas com a origem correta.
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := service.AuditActor{
+			RequestID: contextutil.RequestID(c),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if userID, err := contextutil.UserID(c); err == nil {
+			actor.UserID = &userID
+		}
+		c.Request = c.Request.WithContext(service.WithAuditActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
+
+// ListAuditLogs
+// @Summary Lista o log de auditoria do tenant (admin)
+// @Tags Auditoria
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param entity query string false "Entidade (ex.: client, booking)"
+// @Param entity_id query string false "ID do registro"
+// @Param actor_id query string false "Usuário que realizou a mudança"
+// @Param from query string false "Início (RFC3339)"
+// @Param to query string false "Fim, exclusivo (RFC3339)"
+// @Param page query int false "Página" default(1)
+// @Param per_page query int false "Itens por página" default(20)
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /audit-logs [get]
+func (api *API) ListAuditLogs(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	filter, err := auditLogFilter(c)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	logs, total, err := api.svc.ListAuditLogs(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, logs, metaPagination(filter.Page, filter.PerPage, total))
+}
+
+// auditLogFilter lê os filtros comuns às consultas de auditoria.
+func auditLogFilter(c *gin.Context) (service.AuditLogFilter, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	filter := service.AuditLogFilter{
+		Entity:  c.Query("entity"),
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	ids := map[string]**uuid.UUID{
+		"entity_id": &filter.EntityID,
+		"actor_id":  &filter.ActorID,
+		"tenant_id": &filter.TenantID,
+	}
+	for param, target := range ids {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s inválido", param)
+		}
+		*target = &id
+	}
+
+	times := map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}
+	for param, target := range times {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%s deve estar em RFC3339", param)
+		}
+		*target = &t
+	}
+	return filter, nil
+}
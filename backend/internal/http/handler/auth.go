@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +33,23 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 // Signup
 // @Summary Cria empresa e usuário administrador
 // @Description Fluxo inicial da plataforma: cria empresa, usuário admin e retorna tokens.
@@ -74,6 +92,7 @@ func (api *API) Signup(c *gin.Context) {
 
 // Login
 // @Summary Autentica usuário
+// @Description Com MFA ativo (ou exigido pelo tenant), responde `mfa_required` e um `mfa_token` a ser concluído em /auth/mfa/verify.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -90,6 +109,16 @@ func (api *API) Login(c *gin.Context) {
 	}
 
 	_, tokens, err := api.svc.Login(c.Request.Context(), req.Email, req.Password)
+	var challenge *service.MFAChallenge
+	if errors.As(err, &challenge) {
+		response.Success(c, http.StatusOK, gin.H{
+			"mfa_required":   true,
+			"mfa_token":      challenge.Token,
+			"expires_in":     challenge.ExpiresIn,
+			"setup_required": challenge.SetupRequired,
+		}, nil)
+		return
+	}
 	if err != nil {
 		api.handleError(c, err)
 		return
@@ -100,6 +129,7 @@ func (api *API) Login(c *gin.Context) {
 
 // RefreshToken
 // @Summary Atualiza tokens de acesso
+// @Description Cada refresh token vale para uma única troca. Reutilizar um token já trocado revoga a sessão inteira.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -123,3 +153,152 @@ func (api *API) RefreshToken(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, tokens, nil)
 }
+
+// Logout
+// @Summary Encerra a sessão do refresh token
+// @Description Revoga a sessão e todos os seus refresh tokens. Access tokens já emitidos valem até expirar.
+// @Tags Auth
+// @Accept json
+// @Param request body LogoutRequest true "Refresh token da sessão"
+// @Success 204
+// @Failure 400 {object} response.APIResponse
+// @Router /auth/logout [post]
+func (api *API) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	if err := api.svc.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll
+// @Summary Encerra todas as sessões do usuário autenticado
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/logout-all [post]
+func (api *API) LogoutAll(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := api.svc.LogoutAll(c.Request.Context(), tenantID, userID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"revoked_sessions": revoked}, nil)
+}
+
+// ForgotPassword
+// @Summary Solicita a redefinição de senha
+// @Description Envia por e-mail um link de uso único para cada conta ativa com o endereço. A resposta é sempre 202, exista ou não a conta.
+// @Tags Auth
+// @Accept json
+// @Param request body ForgotPasswordRequest true "E-mail da conta"
+// @Success 202
+// @Failure 400 {object} response.APIResponse
+// @Router /auth/password/forgot [post]
+func (api *API) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	if err := api.svc.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword
+// @Summary Redefine a senha com o token recebido por e-mail
+// @Description O token vale uma única vez. Todas as sessões do usuário são encerradas.
+// @Tags Auth
+// @Accept json
+// @Param request body ResetPasswordRequest true "Token e nova senha"
+// @Success 204
+// @Failure 400 {object} response.APIResponse
+// @Router /auth/password/reset [post]
+func (api *API) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	if err := api.svc.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// VerifyEmail
+// @Summary Confirma o e-mail com o token recebido
+// @Tags Auth
+// @Accept json
+// @Param request body VerifyEmailRequest true "Token de verificação"
+// @Success 204
+// @Failure 400 {object} response.APIResponse
+// @Router /auth/email/verify [post]
+func (api *API) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	if err := api.svc.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResendEmailVerification
+// @Summary Reenvia o link de verificação de e-mail do usuário autenticado
+// @Tags Auth
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 202
+// @Failure 409 {object} response.APIResponse
+// @Router /me/email/verification [post]
+func (api *API) ResendEmailVerification(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := api.svc.SendEmailVerification(c.Request.Context(), tenantID, userID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
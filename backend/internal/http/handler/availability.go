@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+)
+
+// ListAvailableSlots
+// @Summary Busca horários livres
+// @Description Combina disponibilidade, duração do serviço, agendamentos e capacidade do profissional.
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param service_id query string true "Serviço"
+// @Param professional_id query string false "Profissional (omitido: todos aptos ao serviço)"
+// @Param from query string false "Início RFC3339 (padrão: agora)"
+// @Param to query string false "Fim RFC3339 (padrão: from + 7 dias)"
+// @Param granularity query int false "Intervalo entre horários em minutos"
+// @Success 200 {object} response.APIResponse
+// @Router /availability/slots [get]
+func (api *API) ListAvailableSlots(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	serviceID, err := uuid.Parse(c.Query("service_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "service_id inválido", nil)
+		return
+	}
+	query := service.SlotQuery{ServiceID: serviceID}
+
+	if raw := c.Query("professional_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "professional_id inválido", nil)
+			return
+		}
+		query.ProfessionalID = &id
+	}
+	if raw := c.Query("from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "from deve estar em RFC3339", nil)
+			return
+		}
+		query.From = &t
+	}
+	if raw := c.Query("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "to deve estar em RFC3339", nil)
+			return
+		}
+		query.To = &t
+	}
+	if raw := c.Query("granularity"); raw != "" {
+		minutes, err := strconv.Atoi(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "granularity inválido", nil)
+			return
+		}
+		query.Granularity = minutes
+	}
+
+	slots, err := api.svc.ListAvailableSlots(c.Request.Context(), tenantID, query)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, slots, nil)
+}
+
+type AvailabilityExceptionRequest struct {
+	ProfessionalID *uuid.UUID `json:"professional_id"`
+	Kind           string     `json:"kind" binding:"required,oneof=blocked open"`
+	StartAt        time.Time  `json:"start_at" binding:"required"`
+	EndAt          time.Time  `json:"end_at" binding:"required"`
+	Reason         string     `json:"reason"`
+}
+
+// ListAvailabilityExceptions
+// @Summary Lista folgas, feriados e aberturas avulsas
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param professional_id query string false "Profissional (inclui exceções gerais da empresa)"
+// @Param from query string false "Início RFC3339"
+// @Param to query string false "Fim RFC3339"
+// @Success 200 {object} response.APIResponse
+// @Router /availability/exceptions [get]
+func (api *API) ListAvailabilityExceptions(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var filter service.AvailabilityExceptionFilter
+	if raw := c.Query("professional_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "professional_id inválido", nil)
+			return
+		}
+		filter.ProfessionalID = &id
+	}
+	if raw := c.Query("from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "from deve estar em RFC3339", nil)
+			return
+		}
+		filter.From = &t
+	}
+	if raw := c.Query("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "to deve estar em RFC3339", nil)
+			return
+		}
+		filter.To = &t
+	}
+
+	exceptions, err := api.svc.ListAvailabilityExceptions(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, exceptions, nil)
+}
+
+// CreateAvailabilityException
+// @Summary Cria folga, feriado ou abertura avulsa
+// @Description Bloqueios retornam os agendamentos ativos que passaram a conflitar com o intervalo.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body AvailabilityExceptionRequest true "Exceção"
+// @Success 201 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /availability/exceptions [post]
+func (api *API) CreateAvailabilityException(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	var req AvailabilityExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	result, err := api.svc.CreateAvailabilityException(c.Request.Context(), tenantID, service.AvailabilityExceptionInput{
+		ProfessionalID: req.ProfessionalID,
+		Kind:           req.Kind,
+		StartAt:        req.StartAt,
+		EndAt:          req.EndAt,
+		Reason:         req.Reason,
+	})
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, result, nil)
+}
+
+// DeleteAvailabilityException
+// @Summary Remove exceção de disponibilidade
+// @Tags Bookings
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Exception ID"
+// @Success 204
+// @Failure 403 {object} response.APIResponse
+// @Router /availability/exceptions/{id} [delete]
+func (api *API) DeleteAvailabilityException(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	exceptionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.DeleteAvailabilityException(c.Request.Context(), tenantID, exceptionID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
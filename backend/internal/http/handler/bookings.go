@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
@@ -14,11 +16,29 @@ import (
 type BookingRequest struct {
 	ClientID       uuid.UUID  `json:"client_id" binding:"required"`
 	ProfessionalID uuid.UUID  `json:"professional_id" binding:"required"`
-	ServiceID      uuid.UUID  `json:"service_id" binding:"required"`
+	ServiceID      uuid.UUID  `json:"service_id" binding:"required_without=Services"`
 	Status         string     `json:"status"`
 	StartAt        time.Time  `json:"start_at" binding:"required"`
 	EndAt          *time.Time `json:"end_at"`
 	Notes          string     `json:"notes"`
+	// Services agenda vários serviços em sequência (substitui service_id).
+	Services []BookingServiceRequest `json:"services" binding:"dive"`
+	// OverrideAvailability ignora a disponibilidade do profissional (somente admin).
+	OverrideAvailability bool `json:"override_availability"`
+	// Recurrence gera uma série semanal/quinzenal a partir de start_at.
+	Recurrence *RecurrenceRequest `json:"recurrence"`
+}
+
+type BookingServiceRequest struct {
+	ServiceID      uuid.UUID  `json:"service_id" binding:"required"`
+	ProfessionalID *uuid.UUID `json:"professional_id"`
+}
+
+type RecurrenceRequest struct {
+	Interval      int        `json:"interval" binding:"min=0"`
+	Count         int        `json:"count" binding:"min=0"`
+	Until         *time.Time `json:"until"`
+	SkipConflicts bool       `json:"skip_conflicts"`
 }
 
 type BookingUpdateRequest struct {
@@ -26,10 +46,29 @@ type BookingUpdateRequest struct {
 	StartAt *time.Time `json:"start_at"`
 	EndAt   *time.Time `json:"end_at"`
 	Notes   *string    `json:"notes"`
+	// OverrideAvailability ignora a disponibilidade do profissional (somente admin).
+	OverrideAvailability bool `json:"override_availability"`
+	// Scope em séries: this (padrão), following ou all.
+	Scope string `json:"scope" binding:"omitempty,oneof=this following all"`
 }
 
 type BookingCancelRequest struct {
 	Reason string `json:"reason"`
+	// Scope em séries: this (padrão), following ou all.
+	Scope string `json:"scope" binding:"omitempty,oneof=this following all"`
+}
+
+type CheckoutRequest struct {
+	Products []CheckoutProductRequest `json:"products" binding:"dive"`
+	Discount float64                  `json:"discount" binding:"min=0"`
+	Notes    string                   `json:"notes"`
+}
+
+type CheckoutProductRequest struct {
+	ProductID uuid.UUID `json:"product_id" binding:"required"`
+	Quantity  int       `json:"quantity" binding:"required,min=1"`
+	// UnitPrice opcional; sem ele, vale o preço atual do produto.
+	UnitPrice *float64 `json:"unit_price" binding:"omitempty,min=0"`
 }
 
 // ListBookings
@@ -41,6 +80,7 @@ type BookingCancelRequest struct {
 // @Param date query string false "Data (YYYY-MM-DD)"
 // @Param professional_id query string false "Profissional"
 // @Param status query string false "Status"
+// @Param series_id query string false "Série recorrente"
 // @Success 200 {object} response.APIResponse
 // @Router /bookings [get]
 func (api *API) ListBookings(c *gin.Context) {
@@ -50,8 +90,9 @@ func (api *API) ListBookings(c *gin.Context) {
 	}
 
 	var (
-		datePtr *time.Time
-		profID  *uuid.UUID
+		datePtr  *time.Time
+		profID   *uuid.UUID
+		seriesID *uuid.UUID
 	)
 	if raw := c.Query("date"); raw != "" {
 		if t, err := time.Parse("2006-01-02", raw); err == nil {
@@ -63,10 +104,16 @@ func (api *API) ListBookings(c *gin.Context) {
 			profID = &id
 		}
 	}
+	if raw := c.Query("series_id"); raw != "" {
+		if id, err := uuid.Parse(raw); err == nil {
+			seriesID = &id
+		}
+	}
 
 	bookings, err := api.svc.ListBookings(c.Request.Context(), tenantID, service.BookingFilter{
 		Date:           datePtr,
 		ProfessionalID: profID,
+		SeriesID:       seriesID,
 		Status:         c.Query("status"),
 	})
 	if err != nil {
@@ -78,6 +125,7 @@ func (api *API) ListBookings(c *gin.Context) {
 
 // CreateBooking
 // @Summary Cria agendamento
+// @Description Com `recurrence`, cria uma série e retorna as ocorrências criadas e as ignoradas por conflito.
 // @Tags Bookings
 // @Accept json
 // @Produce json
@@ -97,16 +145,42 @@ func (api *API) CreateBooking(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
 		return
 	}
+	if !api.requireAdminOverride(c, req.OverrideAvailability) {
+		return
+	}
 
-	booking, err := api.svc.CreateBooking(c.Request.Context(), tenantID, service.BookingInput{
-		ClientID:       req.ClientID,
-		ProfessionalID: req.ProfessionalID,
-		ServiceID:      req.ServiceID,
-		Status:         req.Status,
-		StartAt:        req.StartAt,
-		EndAt:          req.EndAt,
-		Notes:          req.Notes,
-	})
+	input := service.BookingInput{
+		ClientID:             req.ClientID,
+		ProfessionalID:       req.ProfessionalID,
+		ServiceID:            req.ServiceID,
+		Status:               req.Status,
+		StartAt:              req.StartAt,
+		EndAt:                req.EndAt,
+		Notes:                req.Notes,
+		OverrideAvailability: req.OverrideAvailability,
+	}
+	for _, item := range req.Services {
+		input.Services = append(input.Services, service.BookingServiceInput{
+			ServiceID:      item.ServiceID,
+			ProfessionalID: item.ProfessionalID,
+		})
+	}
+	if req.Recurrence != nil {
+		series, err := api.svc.CreateBookingSeries(c.Request.Context(), tenantID, input, service.RecurrenceRule{
+			Interval:      req.Recurrence.Interval,
+			Count:         req.Recurrence.Count,
+			Until:         req.Recurrence.Until,
+			SkipConflicts: req.Recurrence.SkipConflicts,
+		})
+		if err != nil {
+			api.handleError(c, err)
+			return
+		}
+		response.Success(c, http.StatusCreated, series, nil)
+		return
+	}
+
+	booking, err := api.svc.CreateBooking(c.Request.Context(), tenantID, input)
 	if err != nil {
 		api.handleError(c, err)
 		return
@@ -116,6 +190,7 @@ func (api *API) CreateBooking(c *gin.Context) {
 
 // UpdateBooking
 // @Summary Atualiza agendamento
+// @Description Com `scope` following/all, aplica a edição às ocorrências da série e retorna a lista alterada.
 // @Tags Bookings
 // @Accept json
 // @Produce json
@@ -142,13 +217,28 @@ func (api *API) UpdateBooking(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
 		return
 	}
+	if !api.requireAdminOverride(c, req.OverrideAvailability) {
+		return
+	}
 
-	booking, err := api.svc.UpdateBooking(c.Request.Context(), tenantID, bookingID, service.BookingUpdateInput{
-		Status:  req.Status,
-		StartAt: req.StartAt,
-		EndAt:   req.EndAt,
-		Notes:   req.Notes,
-	})
+	input := service.BookingUpdateInput{
+		Status:               req.Status,
+		StartAt:              req.StartAt,
+		EndAt:                req.EndAt,
+		Notes:                req.Notes,
+		OverrideAvailability: req.OverrideAvailability,
+	}
+	if req.Scope != "" && req.Scope != service.BookingScopeThis {
+		bookings, err := api.svc.UpdateBookingSeries(c.Request.Context(), tenantID, bookingID, req.Scope, input)
+		if err != nil {
+			api.handleError(c, err)
+			return
+		}
+		response.Success(c, http.StatusOK, bookings, nil)
+		return
+	}
+
+	booking, err := api.svc.UpdateBooking(c.Request.Context(), tenantID, bookingID, input)
 	if err != nil {
 		api.handleError(c, err)
 		return
@@ -158,6 +248,7 @@ func (api *API) UpdateBooking(c *gin.Context) {
 
 // CancelBooking
 // @Summary Cancela agendamento
+// @Description Com `scope` following/all, cancela as ocorrências da série e retorna a lista alterada.
 // @Tags Bookings
 // @Accept json
 // @Produce json
@@ -185,6 +276,16 @@ func (api *API) CancelBooking(c *gin.Context) {
 		return
 	}
 
+	if req.Scope != "" && req.Scope != service.BookingScopeThis {
+		bookings, err := api.svc.CancelBookingSeries(c.Request.Context(), tenantID, bookingID, req.Scope, req.Reason)
+		if err != nil {
+			api.handleError(c, err)
+			return
+		}
+		response.Success(c, http.StatusOK, bookings, nil)
+		return
+	}
+
 	booking, err := api.svc.CancelBooking(c.Request.Context(), tenantID, bookingID, req.Reason)
 	if err != nil {
 		api.handleError(c, err)
@@ -192,3 +293,85 @@ func (api *API) CancelBooking(c *gin.Context) {
 	}
 	response.Success(c, http.StatusOK, booking, nil)
 }
+
+// CheckoutBooking
+// @Summary Fecha o atendimento e gera o pedido de venda
+// @Description Cria o pedido em rascunho com os serviços do agendamento ao preço atual e os produtos adicionais, e marca o agendamento como done.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Booking ID"
+// @Param request body CheckoutRequest false "Produtos adicionais"
+// @Success 201 {object} response.APIResponse
+// @Router /bookings/{id}/checkout [post]
+func (api *API) CheckoutBooking(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	// O corpo é opcional: sem produtos adicionais, o pedido traz apenas os serviços.
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	products := make([]service.CheckoutProductInput, len(req.Products))
+	for i, product := range req.Products {
+		products[i] = service.CheckoutProductInput{
+			ProductID: product.ProductID,
+			Quantity:  product.Quantity,
+			UnitPrice: product.UnitPrice,
+		}
+	}
+
+	order, err := api.svc.CheckoutBooking(c.Request.Context(), tenantID, bookingID, service.CheckoutInput{
+		Products: products,
+		Discount: req.Discount,
+		Notes:    req.Notes,
+	})
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, order, nil)
+}
+
+// ListBookingReminders
+// @Summary Lista os lembretes enviados para o agendamento
+// @Description Um registro por antecedência e canal, com status pending, sent, failed ou skipped.
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Booking ID"
+// @Success 200 {object} response.APIResponse
+// @Router /bookings/{id}/reminders [get]
+func (api *API) ListBookingReminders(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	bookingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	reminders, err := api.svc.ListBookingReminders(c.Request.Context(), tenantID, bookingID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, reminders, nil)
+}
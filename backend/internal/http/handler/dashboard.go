@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
 )
 
 // DashboardDaily
@@ -47,3 +48,40 @@ func (api *API) DashboardDaily(c *gin.Context) {
 	}
 	response.Success(c, http.StatusOK, result, nil)
 }
+
+// DashboardAttendance
+// @Summary Métricas de comparecimento (no-show e pontualidade)
+// @Tags Dashboard
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param group_by query string false "professional ou client"
+// @Param from query string false "Data inicial (YYYY-MM-DD)"
+// @Param to query string false "Data final exclusiva (YYYY-MM-DD)"
+// @Success 200 {object} response.APIResponse
+// @Router /dashboard/attendance [get]
+func (api *API) DashboardAttendance(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	filter := service.AttendanceFilter{GroupBy: c.Query("group_by")}
+	if raw := c.Query("from"); raw != "" {
+		if d, err := time.Parse("2006-01-02", raw); err == nil {
+			filter.StartDate = &d
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if d, err := time.Parse("2006-01-02", raw); err == nil {
+			filter.EndDate = &d
+		}
+	}
+
+	result, err := api.svc.DashboardAttendance(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, result, nil)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+)
+
+type EmailTemplateRequest struct {
+	Subject  string `json:"subject" binding:"required"`
+	TextBody string `json:"text_body" binding:"required"`
+	HTMLBody string `json:"html_body"`
+}
+
+// EmailTemplatePreviewRequest rascunho opcional e registros usados como dados da prévia.
+type EmailTemplatePreviewRequest struct {
+	Subject      *string    `json:"subject"`
+	TextBody     *string    `json:"text_body"`
+	HTMLBody     *string    `json:"html_body"`
+	BookingID    *uuid.UUID `json:"booking_id"`
+	SalesOrderID *uuid.UUID `json:"sales_order_id"`
+	ClientID     *uuid.UUID `json:"client_id"`
+}
+
+// ListEmailTemplates
+// @Summary Lista os templates de e-mail
+// @Description Conteúdo efetivo de cada template: o personalizado pela empresa ou o padrão do sistema.
+// @Tags EmailTemplates
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 200 {object} response.APIResponse
+// @Router /email-templates [get]
+func (api *API) ListEmailTemplates(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	templates, err := api.svc.ListEmailTemplates(c.Request.Context(), tenantID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, templates, nil)
+}
+
+// GetEmailTemplate
+// @Summary Detalha um template de e-mail
+// @Tags EmailTemplates
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Success 200 {object} response.APIResponse
+// @Router /email-templates/{key} [get]
+func (api *API) GetEmailTemplate(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	template, err := api.svc.GetEmailTemplate(c.Request.Context(), tenantID, c.Param("key"))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, template, nil)
+}
+
+// UpdateEmailTemplate
+// @Summary Personaliza um template de e-mail
+// @Description Grava uma nova versão; o conteúdo é validado renderizando-o com dados de exemplo.
+// @Tags EmailTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Param request body EmailTemplateRequest true "Template"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /email-templates/{key} [put]
+func (api *API) UpdateEmailTemplate(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	var req EmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	template, err := api.svc.UpdateEmailTemplate(c.Request.Context(), tenantID, c.Param("key"), service.EmailTemplateInput{
+		Subject:  req.Subject,
+		TextBody: req.TextBody,
+		HTMLBody: req.HTMLBody,
+	})
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, template, nil)
+}
+
+// ResetEmailTemplate
+// @Summary Volta ao template padrão
+// @Description O histórico de versões é mantido e pode ser restaurado.
+// @Tags EmailTemplates
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Success 204
+// @Failure 403 {object} response.APIResponse
+// @Router /email-templates/{key} [delete]
+func (api *API) ResetEmailTemplate(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	if err := api.svc.ResetEmailTemplate(c.Request.Context(), tenantID, c.Param("key")); err != nil {
+		api.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListEmailTemplateVersions
+// @Summary Histórico de versões do template
+// @Tags EmailTemplates
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Success 200 {object} response.APIResponse
+// @Router /email-templates/{key}/versions [get]
+func (api *API) ListEmailTemplateVersions(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	versions, err := api.svc.ListEmailTemplateVersions(c.Request.Context(), tenantID, c.Param("key"))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, versions, nil)
+}
+
+// RestoreEmailTemplateVersion
+// @Summary Restaura uma versão do template
+// @Description O conteúdo da versão é gravado como uma nova versão.
+// @Tags EmailTemplates
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Param version path int true "Versão"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /email-templates/{key}/versions/{version}/restore [post]
+func (api *API) RestoreEmailTemplateVersion(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", "Versão inválida", nil)
+		return
+	}
+
+	template, err := api.svc.RestoreEmailTemplateVersion(c.Request.Context(), tenantID, c.Param("key"), version)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, template, nil)
+}
+
+// PreviewEmailTemplate
+// @Summary Pré-visualiza um template de e-mail
+// @Description Com subject/text_body/html_body, renderiza o rascunho; sem eles, o template efetivo. booking_id, sales_order_id e client_id substituem os dados de exemplo.
+// @Tags EmailTemplates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param key path string true "Chave do template"
+// @Param request body EmailTemplatePreviewRequest false "Rascunho e dados"
+// @Success 200 {object} response.APIResponse
+// @Router /email-templates/{key}/preview [post]
+func (api *API) PreviewEmailTemplate(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	// O corpo é opcional: sem ele, a prévia usa o template efetivo com dados de exemplo.
+	var req EmailTemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	input := service.EmailTemplatePreviewInput{
+		BookingID:    req.BookingID,
+		SalesOrderID: req.SalesOrderID,
+		ClientID:     req.ClientID,
+	}
+	if req.Subject != nil || req.TextBody != nil || req.HTMLBody != nil {
+		input.Draft = &service.EmailTemplateInput{
+			Subject:  valueOrEmpty(req.Subject),
+			TextBody: valueOrEmpty(req.TextBody),
+			HTMLBody: valueOrEmpty(req.HTMLBody),
+		}
+	}
+
+	preview, err := api.svc.PreviewEmailTemplate(c.Request.Context(), tenantID, c.Param("key"), input)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, preview, nil)
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
 	"github.com/kusmin/gestao_updev/backend/internal/http/contextutil"
 	"github.com/kusmin/gestao_updev/backend/internal/http/response"
 	"github.com/kusmin/gestao_updev/backend/internal/service"
@@ -36,6 +39,38 @@ func (api *API) tenantID(c *gin.Context) (uuid.UUID, bool) {
 	return tenantID, true
 }
 
+func (api *API) currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := contextutil.UserID(c)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Usuário não identificado", nil)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// requireAdminOverride garante que apenas administradores usem flags de override.
+func (api *API) requireAdminOverride(c *gin.Context, override bool) bool {
+	if !override {
+		return true
+	}
+	role, err := contextutil.UserRole(c)
+	if err != nil || role != domain.UserRoleAdmin {
+		response.Error(c, http.StatusForbidden, "FORBIDDEN", "Override permitido apenas para administradores", nil)
+		return false
+	}
+	return true
+}
+
+// requireAdmin restringe a rota a administradores do tenant.
+func (api *API) requireAdmin(c *gin.Context) bool {
+	role, err := contextutil.UserRole(c)
+	if err != nil || role != domain.UserRoleAdmin {
+		response.Error(c, http.StatusForbidden, "FORBIDDEN", "Operação permitida apenas para administradores", nil)
+		return false
+	}
+	return true
+}
+
 func (api *API) handleError(c *gin.Context, err error) {
 	if errors.Is(err, service.ErrInvalidCredentials) {
 		response.Error(c, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Credenciais inválidas", nil)
@@ -45,6 +80,129 @@ func (api *API) handleError(c *gin.Context, err error) {
 		response.Error(c, http.StatusConflict, "BOOKING_CONFLICT", err.Error(), nil)
 		return
 	}
+	if errors.Is(err, service.ErrOutsideAvailability) {
+		response.Error(c, http.StatusUnprocessableEntity, "OUTSIDE_AVAILABILITY", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidInventoryType) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrBookingFinalized) {
+		response.Error(c, http.StatusUnprocessableEntity, "BOOKING_FINALIZED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInsufficientStock) {
+		response.Error(c, http.StatusUnprocessableEntity, "INSUFFICIENT_STOCK", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidBookingStatus) ||
+		errors.Is(err, service.ErrInvalidAttendanceGroup) ||
+		errors.Is(err, service.ErrInvalidSlotQuery) ||
+		errors.Is(err, service.ErrInvalidAvailabilityRule) ||
+		errors.Is(err, service.ErrInvalidAvailabilityException) ||
+		errors.Is(err, service.ErrInvalidRecurrence) ||
+		errors.Is(err, service.ErrInvalidSeriesScope) ||
+		errors.Is(err, service.ErrInvalidWaitlistEntry) ||
+		errors.Is(err, service.ErrInvalidBookingServices) ||
+		errors.Is(err, service.ErrInvalidWebhook) ||
+		errors.Is(err, service.ErrInvalidEmailTemplate) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrUnknownEmailTemplate) {
+		response.Error(c, http.StatusNotFound, "EMAIL_TEMPLATE_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidToken) {
+		response.Error(c, http.StatusBadRequest, "INVALID_TOKEN", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrEmailAlreadyVerified) {
+		response.Error(c, http.StatusConflict, "EMAIL_ALREADY_VERIFIED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidMFACode) {
+		response.Error(c, http.StatusUnauthorized, "INVALID_MFA_CODE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+		response.Error(c, http.StatusConflict, "MFA_ALREADY_ENABLED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrMFANotEnabled) {
+		response.Error(c, http.StatusConflict, "MFA_NOT_ENABLED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrMFASetupNotStarted) {
+		response.Error(c, http.StatusConflict, "MFA_SETUP_NOT_STARTED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrMFAEnforced) {
+		response.Error(c, http.StatusConflict, "MFA_ENFORCED", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrSessionNotFound) {
+		response.Error(c, http.StatusNotFound, "SESSION_NOT_FOUND", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrBookingAlreadyCheckedOut) {
+		response.Error(c, http.StatusConflict, "BOOKING_ALREADY_CHECKED_OUT", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrWaitlistOfferUnavailable) {
+		response.Error(c, http.StatusConflict, "WAITLIST_OFFER_UNAVAILABLE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrProfessionalUserTaken) {
+		response.Error(c, http.StatusConflict, "PROFESSIONAL_USER_TAKEN", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidPaymentAmount) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrOverpayment) {
+		response.Error(c, http.StatusUnprocessableEntity, "OVERPAYMENT", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrOrderNotPayable) {
+		response.Error(c, http.StatusUnprocessableEntity, "ORDER_NOT_PAYABLE", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidRefundAmount) {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+	if errors.Is(err, service.ErrRefundExceedsPayment) {
+		response.Error(c, http.StatusUnprocessableEntity, "REFUND_EXCEEDS_PAYMENT", err.Error(), nil)
+		return
+	}
+	var throttledErr *service.LoginThrottledError
+	if errors.As(err, &throttledErr) {
+		retryAfter := int(math.Ceil(throttledErr.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		response.Error(c, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", err.Error(), gin.H{
+			"retry_after": retryAfter,
+			"locked":      throttledErr.Locked,
+		})
+		return
+	}
+	var seriesErr *service.SeriesConflictError
+	if errors.As(err, &seriesErr) {
+		response.Error(c, http.StatusConflict, "SERIES_CONFLICT", err.Error(), gin.H{
+			"conflicts": seriesErr.Conflicts,
+		})
+		return
+	}
+	var transitionErr *service.InvalidTransitionError
+	if errors.As(err, &transitionErr) {
+		response.Error(c, http.StatusUnprocessableEntity, "INVALID_STATUS_TRANSITION", err.Error(), gin.H{
+			"from": transitionErr.From,
+			"to":   transitionErr.To,
+		})
+		return
+	}
 	response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error(), nil)
 }
 
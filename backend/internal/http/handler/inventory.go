@@ -107,3 +107,37 @@ func (api *API) CreateInventoryMovement(c *gin.Context) {
 	}
 	response.Success(c, http.StatusCreated, movement, nil)
 }
+
+// RebuildProductStock
+// @Summary Reconstrói o saldo de estoque de um produto
+// @Description Recalcula stock_qty a partir do histórico de movimentações do produto.
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /inventory/products/{id}/rebuild [post]
+func (api *API) RebuildProductStock(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	productID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	result, err := api.svc.RebuildProductStock(c.Request.Context(), tenantID, productID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, result, nil)
+}
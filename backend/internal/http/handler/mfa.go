@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+)
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	// Code aceita o código do autenticador ou um código de recuperação.
+	Code string `json:"code" binding:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// VerifyMFA
+// @Summary Conclui o login com o segundo fator
+// @Description Troca o `mfa_token` devolvido pelo login e um código do autenticador (ou de recuperação) pelos tokens da sessão.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "Desafio e código"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/mfa/verify [post]
+func (api *API) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	_, tokens, err := api.svc.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, tokens, nil)
+}
+
+// SetupMFAWithChallenge
+// @Summary Inicia o cadastro do autenticador durante o login
+// @Description Para administradores de tenants que exigem MFA e ainda não o configuraram (`setup_required`).
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body MFAChallengeRequest true "Desafio do login"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/mfa/setup [post]
+func (api *API) SetupMFAWithChallenge(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	setup, err := api.svc.SetupMFAWithChallenge(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, setup, nil)
+}
+
+// EnableMFAWithChallenge
+// @Summary Ativa o autenticador cadastrado no login e conclui a autenticação
+// @Description Devolve os tokens da sessão e os códigos de recuperação, exibidos apenas nesta resposta.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "Desafio e código do autenticador"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/mfa/enable [post]
+func (api *API) EnableMFAWithChallenge(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	tokens, codes, err := api.svc.EnableMFAWithChallenge(c.Request.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{
+		"access_token":   tokens.AccessToken,
+		"refresh_token":  tokens.RefreshToken,
+		"expires_in":     tokens.ExpiresIn,
+		"recovery_codes": codes,
+	}, nil)
+}
+
+// StartMFASetup
+// @Summary Inicia o cadastro do autenticador do usuário autenticado
+// @Description Gera um segredo TOTP pendente; o MFA só passa a valer após /me/mfa/enable.
+// @Tags MFA
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 200 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /me/mfa/setup [post]
+func (api *API) StartMFASetup(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	setup, err := api.svc.StartMFASetup(c.Request.Context(), tenantID, userID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, setup, nil)
+}
+
+// EnableMFA
+// @Summary Ativa o MFA com um código do autenticador
+// @Description Devolve os códigos de recuperação, exibidos apenas nesta resposta.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body MFACodeRequest true "Código do autenticador"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /me/mfa/enable [post]
+func (api *API) EnableMFA(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	codes, err := api.svc.EnableMFA(c.Request.Context(), tenantID, userID, req.Code)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"recovery_codes": codes}, nil)
+}
+
+// DisableMFA
+// @Summary Desativa o MFA do usuário autenticado
+// @Description Exige um código do autenticador ou de recuperação. Bloqueado para administradores quando o tenant exige MFA.
+// @Tags MFA
+// @Accept json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body MFACodeRequest true "Código do autenticador ou de recuperação"
+// @Success 204 "No Content"
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /me/mfa/disable [post]
+func (api *API) DisableMFA(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	if err := api.svc.DisableMFA(c.Request.Context(), tenantID, userID, req.Code); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes
+// @Summary Gera novos códigos de recuperação
+// @Description Invalida os códigos anteriores. Exige um código do autenticador ou de recuperação.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body MFACodeRequest true "Código do autenticador ou de recuperação"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /me/mfa/recovery-codes [post]
+func (api *API) RegenerateRecoveryCodes(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	codes, err := api.svc.RegenerateRecoveryCodes(c.Request.Context(), tenantID, userID, req.Code)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, gin.H{"recovery_codes": codes}, nil)
+}
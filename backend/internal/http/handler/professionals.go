@@ -4,10 +4,30 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
 )
 
+type ProfessionalRequest struct {
+	UserID      *uuid.UUID `json:"user_id"`
+	Name        string     `json:"name" binding:"required"`
+	Specialties []string   `json:"specialties"`
+	MaxParallel int        `json:"max_parallel"`
+	Active      *bool      `json:"active"`
+}
+
+type AvailabilityRuleRequest struct {
+	Weekday   int    `json:"weekday" binding:"min=0,max=6"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+type AvailabilityRequest struct {
+	Rules []AvailabilityRuleRequest `json:"rules" binding:"dive"`
+}
+
 // ListProfessionals
 // @Summary Lista profissionais ativos
 // @Tags Professionals
@@ -29,3 +49,228 @@ func (api *API) ListProfessionals(c *gin.Context) {
 	}
 	response.Success(c, http.StatusOK, professionals, nil)
 }
+
+// CreateProfessional
+// @Summary Cria profissional
+// @Tags Professionals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body ProfessionalRequest true "Profissional"
+// @Success 201 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /professionals [post]
+func (api *API) CreateProfessional(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	var req ProfessionalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	professional, err := api.svc.CreateProfessional(c.Request.Context(), tenantID, professionalInput(req))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, professional, nil)
+}
+
+// GetProfessional
+// @Summary Detalhe do profissional
+// @Tags Professionals
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Professional ID"
+// @Success 200 {object} response.APIResponse
+// @Router /professionals/{id} [get]
+func (api *API) GetProfessional(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	professionalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	professional, err := api.svc.GetProfessional(c.Request.Context(), tenantID, professionalID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, professional, nil)
+}
+
+// UpdateProfessional
+// @Summary Atualiza profissional
+// @Tags Professionals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Professional ID"
+// @Param request body ProfessionalRequest true "Profissional"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /professionals/{id} [put]
+func (api *API) UpdateProfessional(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	professionalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	var req ProfessionalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	professional, err := api.svc.UpdateProfessional(c.Request.Context(), tenantID, professionalID, professionalInput(req))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, professional, nil)
+}
+
+// DeactivateProfessional
+// @Summary Desativa profissional
+// @Tags Professionals
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Professional ID"
+// @Success 204
+// @Failure 403 {object} response.APIResponse
+// @Router /professionals/{id} [delete]
+func (api *API) DeactivateProfessional(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	professionalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.DeactivateProfessional(c.Request.Context(), tenantID, professionalID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetProfessionalAvailability
+// @Summary Lista a disponibilidade semanal do profissional
+// @Tags Professionals
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Professional ID"
+// @Success 200 {object} response.APIResponse
+// @Router /professionals/{id}/availability [get]
+func (api *API) GetProfessionalAvailability(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	professionalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	professional, err := api.svc.GetProfessional(c.Request.Context(), tenantID, professionalID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, professional.Availability, nil)
+}
+
+// ReplaceProfessionalAvailability
+// @Summary Substitui a disponibilidade semanal do profissional
+// @Description Remove as regras atuais e grava o novo conjunto na mesma transação.
+// @Tags Professionals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Professional ID"
+// @Param request body AvailabilityRequest true "Regras semanais"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /professionals/{id}/availability [put]
+func (api *API) ReplaceProfessionalAvailability(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	professionalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	var req AvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	inputs := make([]service.AvailabilityRuleInput, len(req.Rules))
+	for i, rule := range req.Rules {
+		inputs[i] = service.AvailabilityRuleInput{
+			Weekday:   rule.Weekday,
+			StartTime: rule.StartTime,
+			EndTime:   rule.EndTime,
+		}
+	}
+
+	rules, err := api.svc.ReplaceAvailability(c.Request.Context(), tenantID, professionalID, inputs)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, rules, nil)
+}
+
+func professionalInput(req ProfessionalRequest) service.ProfessionalInput {
+	return service.ProfessionalInput{
+		UserID:      req.UserID,
+		Name:        req.Name,
+		Specialties: req.Specialties,
+		MaxParallel: req.MaxParallel,
+		Active:      req.Active,
+	}
+}
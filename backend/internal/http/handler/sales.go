@@ -21,7 +21,7 @@ type SalesOrderItemRequest struct {
 type SalesOrderRequest struct {
 	ClientID  uuid.UUID               `json:"client_id" binding:"required"`
 	BookingID *uuid.UUID              `json:"booking_id"`
-	Items     []SalesOrderItemRequest `json:"items" binding:"required,dive"`
+	Items     []SalesOrderItemRequest `json:"items" binding:"required_without=BookingID,dive"`
 	Discount  float64                 `json:"discount"`
 	Notes     string                  `json:"notes"`
 }
@@ -38,6 +38,13 @@ type PaymentRequest struct {
 	Details map[string]interface{} `json:"details"`
 }
 
+type RefundRequest struct {
+	PaymentID  uuid.UUID `json:"payment_id" binding:"required"`
+	Amount     float64   `json:"amount"`
+	Reason     string    `json:"reason"`
+	RefundedAt time.Time `json:"refunded_at"`
+}
+
 // ListSalesOrders
 // @Summary Lista pedidos/vendas
 // @Tags Sales
@@ -209,6 +216,49 @@ func (api *API) CreatePayment(c *gin.Context) {
 	response.Success(c, http.StatusCreated, payment, nil)
 }
 
+// CreateRefund
+// @Summary Estorna pagamento
+// @Description Estorno total (amount omitido) ou parcial de um pagamento do pedido.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Order ID"
+// @Param request body RefundRequest true "Estorno"
+// @Success 201 {object} response.APIResponse
+// @Router /sales/orders/{id}/refunds [post]
+func (api *API) CreateRefund(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	refund, err := api.svc.RefundPayment(c.Request.Context(), tenantID, orderID, service.RefundInput{
+		PaymentID:  req.PaymentID,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		RefundedAt: req.RefundedAt,
+	})
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, refund, nil)
+}
+
 // ListPayments
 // @Summary Lista pagamentos
 // @Tags Payments
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/contextutil"
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+)
+
+// ListMySessions
+// @Summary Lista as sessões ativas do usuário autenticado
+// @Description Cada login abre uma sessão; `current` marca a sessão do token usado na requisição.
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 200 {object} response.APIResponse
+// @Router /me/sessions [get]
+func (api *API) ListMySessions(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	// Tokens sem sessão (emitidos fora do login) não marcam nenhuma como atual.
+	currentSessionID, _ := contextutil.SessionID(c)
+	sessions, err := api.svc.ListUserSessions(c.Request.Context(), tenantID, userID, currentSessionID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, sessions, nil)
+}
+
+// RevokeMySession
+// @Summary Encerra uma sessão do usuário autenticado
+// @Description Revoga a sessão e seus refresh tokens; access tokens já emitidos valem até expirar.
+// @Tags Sessions
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} response.APIResponse
+// @Router /me/sessions/{id} [delete]
+func (api *API) RevokeMySession(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	userID, ok := api.currentUserID(c)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.RevokeUserSession(c.Request.Context(), tenantID, userID, sessionID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUserSessions
+// @Summary Lista as sessões ativas de um usuário do tenant (admin)
+// @Tags Sessions
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "User ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /users/{id}/sessions [get]
+func (api *API) ListUserSessions(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	currentSessionID, _ := contextutil.SessionID(c)
+	sessions, err := api.svc.ListUserSessions(c.Request.Context(), tenantID, userID, currentSessionID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	response.Success(c, http.StatusOK, sessions, nil)
+}
+
+// RevokeUserSession
+// @Summary Encerra uma sessão de um usuário do tenant (admin)
+// @Tags Sessions
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/{id}/sessions/{session_id} [delete]
+func (api *API) RevokeUserSession(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.RevokeUserSession(c.Request.Context(), tenantID, userID, sessionID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -198,3 +198,35 @@ func (api *API) DeleteUser(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// UnlockUser
+// @Summary Remove o bloqueio de login de um usuário (admin)
+// @Description Zera as falhas de login do e-mail do usuário. O bloqueio por IP não é afetado.
+// @Tags Users
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 403 {object} response.APIResponse
+// @Router /users/{id}/unlock [post]
+func (api *API) UnlockUser(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.UnlockUser(c.Request.Context(), tenantID, userID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -42,6 +42,8 @@ var (
 		&domain.SalesItem{},
 		&domain.Payment{},
 		&domain.InventoryMovement{},
+		&domain.AuditLog{},
+		&domain.UserToken{},
 	}
 )
 
@@ -91,7 +93,7 @@ func setupTestAPI(t *testing.T) (*gin.Engine, *domain.Company, string) {
 	router := gin.New()
 	api := router.Group("/v1")
 	api.Use(middleware.TenantEnforcer(cfg.TenantHeader))
-	api.Use(middleware.Auth(jwtManager, cfg.TenantHeader))
+	api.Use(middleware.Auth(jwtManager, cfg.TenantHeader, svc))
 	registerUserRoutes(api, apiHandler) // Função helper para registrar apenas rotas de usuário
 
 	return router, tenant, token
@@ -368,7 +370,7 @@ func createTestTenant() (*domain.Company, error) {
 
 func clearAllData() {
 	tables := []string{
-		"payments", "sales_items", "sales_orders", "inventory_movements",
+		"audit_logs", "user_tokens", "payments", "sales_items", "sales_orders", "inventory_movements",
 		"bookings", "products", "services", "clients", "users", "companies",
 	}
 	for _, table := range tables {
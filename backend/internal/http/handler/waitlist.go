@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+)
+
+type WaitlistRequest struct {
+	ClientID       uuid.UUID  `json:"client_id" binding:"required"`
+	ServiceID      uuid.UUID  `json:"service_id" binding:"required"`
+	ProfessionalID *uuid.UUID `json:"professional_id"`
+	DesiredFrom    time.Time  `json:"desired_from" binding:"required"`
+	DesiredTo      time.Time  `json:"desired_to" binding:"required"`
+	Notes          string     `json:"notes"`
+}
+
+// ListWaitlist
+// @Summary Lista a fila de espera
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param status query string false "Status (waiting, offered, booked, expired, canceled)"
+// @Success 200 {object} response.APIResponse
+// @Router /waitlist [get]
+func (api *API) ListWaitlist(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	entries, err := api.svc.ListWaitlist(c.Request.Context(), tenantID, c.Query("status"))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, entries, nil)
+}
+
+// CreateWaitlistEntry
+// @Summary Inclui cliente na fila de espera
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body WaitlistRequest true "Entrada"
+// @Success 201 {object} response.APIResponse
+// @Router /waitlist [post]
+func (api *API) CreateWaitlistEntry(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	var req WaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	entry, err := api.svc.CreateWaitlistEntry(c.Request.Context(), tenantID, service.WaitlistInput{
+		ClientID:       req.ClientID,
+		ServiceID:      req.ServiceID,
+		ProfessionalID: req.ProfessionalID,
+		DesiredFrom:    req.DesiredFrom,
+		DesiredTo:      req.DesiredTo,
+		Notes:          req.Notes,
+	})
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, entry, nil)
+}
+
+// CancelWaitlistEntry
+// @Summary Remove cliente da fila de espera
+// @Description Se havia vaga reservada, ela é oferecida ao próximo da fila.
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Entry ID"
+// @Success 200 {object} response.APIResponse
+// @Router /waitlist/{id} [delete]
+func (api *API) CancelWaitlistEntry(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	entry, err := api.svc.CancelWaitlistEntry(c.Request.Context(), tenantID, entryID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, entry, nil)
+}
+
+// AcceptWaitlistOffer
+// @Summary Confirma a vaga oferecida pela fila de espera
+// @Tags Bookings
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Entry ID"
+// @Success 201 {object} response.APIResponse
+// @Router /waitlist/{id}/accept [post]
+func (api *API) AcceptWaitlistOffer(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	booking, err := api.svc.AcceptWaitlistOffer(c.Request.Context(), tenantID, entryID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, booking, nil)
+}
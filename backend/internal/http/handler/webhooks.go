@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/http/response"
+	"github.com/kusmin/gestao_updev/backend/internal/service"
+)
+
+type WebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	// Secret opcional; na criação, um segredo é gerado quando omitido.
+	Secret      string `json:"secret"`
+	Description string `json:"description"`
+	Active      *bool  `json:"active"`
+}
+
+// ListWebhooks
+// @Summary Lista assinaturas de webhook
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /webhooks [get]
+func (api *API) ListWebhooks(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	subscriptions, err := api.svc.ListWebhooks(c.Request.Context(), tenantID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, subscriptions, nil)
+}
+
+// CreateWebhook
+// @Summary Cria assinatura de webhook
+// @Description O segredo usado na assinatura HMAC é devolvido apenas nesta resposta.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param request body WebhookRequest true "Assinatura"
+// @Success 201 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /webhooks [post]
+func (api *API) CreateWebhook(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	subscription, err := api.svc.CreateWebhook(c.Request.Context(), tenantID, webhookInput(req))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusCreated, subscription, nil)
+}
+
+// UpdateWebhook
+// @Summary Atualiza assinatura de webhook
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Assinatura"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /webhooks/{id} [put]
+func (api *API) UpdateWebhook(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), nil)
+		return
+	}
+
+	subscription, err := api.svc.UpdateWebhook(c.Request.Context(), tenantID, webhookID, webhookInput(req))
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, subscription, nil)
+}
+
+// DeleteWebhook
+// @Summary Remove assinatura de webhook
+// @Tags Webhooks
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 403 {object} response.APIResponse
+// @Router /webhooks/{id} [delete]
+func (api *API) DeleteWebhook(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	if err := api.svc.DeleteWebhook(c.Request.Context(), tenantID, webhookID); err != nil {
+		api.handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries
+// @Summary Lista entregas de webhook
+// @Description Com `status=dead`, funciona como fila de dead-letter.
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param status query string false "Status (pending, delivered, dead)"
+// @Param subscription_id query string false "Assinatura"
+// @Param page query int false "Página" default(1)
+// @Param per_page query int false "Itens por página" default(20)
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /webhook-deliveries [get]
+func (api *API) ListWebhookDeliveries(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	filter := service.WebhookDeliveryFilter{
+		Status:  c.Query("status"),
+		Page:    page,
+		PerPage: perPage,
+	}
+	if raw := c.Query("subscription_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+			return
+		}
+		filter.SubscriptionID = &id
+	}
+
+	deliveries, err := api.svc.ListWebhookDeliveries(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, deliveries, nil)
+}
+
+// RetryWebhookDelivery
+// @Summary Reenvia entrega de webhook em dead-letter
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Security TenantHeader
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /webhook-deliveries/{id}/retry [post]
+func (api *API) RetryWebhookDelivery(c *gin.Context) {
+	tenantID, ok := api.tenantID(c)
+	if !ok {
+		return
+	}
+	if !api.requireAdmin(c) {
+		return
+	}
+
+	deliveryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "INVALID_ID", "ID inválido", nil)
+		return
+	}
+
+	delivery, err := api.svc.RetryWebhookDelivery(c.Request.Context(), tenantID, deliveryID)
+	if err != nil {
+		api.handleError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, delivery, nil)
+}
+
+func webhookInput(req WebhookRequest) service.WebhookInput {
+	return service.WebhookInput{
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+	}
+}
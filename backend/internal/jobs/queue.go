@@ -0,0 +1,209 @@
+// Package jobs implementa uma fila de tarefas durável em Postgres. Os workers reservam
+// tarefas com SELECT ... FOR UPDATE SKIP LOCKED, o que permite várias instâncias
+// consumindo a mesma tabela sem processar a mesma tarefa duas vezes.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const (
+	defaultMaxAttempts       = 10
+	defaultConcurrency       = 4
+	defaultTenantConcurrency = 2
+	defaultPollInterval      = time.Second
+	defaultLease             = 10 * time.Minute
+	defaultShutdownTimeout   = 30 * time.Second
+
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// ErrDuplicateJob sinaliza que já existe tarefa pendente ou em execução com a mesma UniqueKey.
+var ErrDuplicateJob = errors.New("jobs: tarefa duplicada")
+
+// Handler processa uma tarefa. Erros geram nova tentativa com backoff exponencial até
+// MaxAttempts; erros marcados com Permanent encerram a tarefa imediatamente.
+type Handler func(ctx context.Context, job *domain.Job) error
+
+// Options parâmetros do worker; valores zerados usam os padrões.
+type Options struct {
+	// Concurrency limita as tarefas simultâneas deste processo.
+	Concurrency int
+	// TenantConcurrency limita as tarefas em execução por tenant, somando todos os workers.
+	TenantConcurrency int
+	PollInterval      time.Duration
+	// Lease é o tempo máximo de execução; tarefas presas em running além dele são retomadas.
+	Lease time.Duration
+	// ShutdownTimeout é a espera pelas tarefas em andamento ao desligar.
+	ShutdownTimeout time.Duration
+	WorkerID        string
+}
+
+// EnqueueOptions opções de agendamento de uma tarefa.
+type EnqueueOptions struct {
+	TenantID    *uuid.UUID
+	RunAt       time.Time
+	UniqueKey   string
+	MaxAttempts int
+}
+
+// Queue registra os handlers e executa as tarefas.
+type Queue struct {
+	db        *gorm.DB
+	logger    *zap.Logger
+	opts      Options
+	handlers  map[string]Handler
+	recurring map[string]time.Duration
+}
+
+// New cria a fila sobre a conexão informada.
+func New(db *gorm.DB, logger *zap.Logger, opts Options) *Queue {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.TenantConcurrency <= 0 {
+		opts.TenantConcurrency = defaultTenantConcurrency
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = defaultLease
+	}
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if opts.WorkerID == "" {
+		opts.WorkerID = defaultWorkerID()
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Queue{
+		db:        db,
+		logger:    logger,
+		opts:      opts,
+		handlers:  make(map[string]Handler),
+		recurring: make(map[string]time.Duration),
+	}
+}
+
+// Register associa um handler tipado ao tipo de tarefa: o payload JSON é decodificado
+// em T antes da chamada. Payloads inválidos encerram a tarefa sem novas tentativas.
+func Register[T any](q *Queue, jobType string, fn func(ctx context.Context, job *domain.Job, payload T) error) {
+	q.handlers[jobType] = func(ctx context.Context, job *domain.Job) error {
+		var payload T
+		if len(job.Payload) > 0 {
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return Permanent(fmt.Errorf("decodificar payload: %w", err))
+			}
+		}
+		return fn(ctx, job, payload)
+	}
+}
+
+// Every agenda o tipo (já registrado) para rodar a cada intervalo. Há no máximo uma
+// ocorrência pendente por tipo; falhas não são repetidas, a próxima execução já é a nova tentativa.
+func (q *Queue) Every(jobType string, interval time.Duration) {
+	q.recurring[jobType] = interval
+}
+
+// Enqueue grava a tarefa usando db, que pode ser a transação do caso de uso, para que a
+// tarefa só exista se a operação for confirmada.
+func Enqueue(db *gorm.DB, jobType string, payload interface{}, opts EnqueueOptions) (*domain.Job, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	if opts.RunAt.IsZero() {
+		opts.RunAt = time.Now().UTC()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+
+	job := &domain.Job{
+		TenantID:    opts.TenantID,
+		Type:        jobType,
+		Payload:     body,
+		Status:      domain.JobStatusPending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if opts.UniqueKey == "" {
+		return job, db.Create(job).Error
+	}
+
+	job.UniqueKey = &opts.UniqueKey
+	result := db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "unique_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status = 'pending' OR status = 'running'"}}},
+		DoNothing:   true,
+	}).Create(job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrDuplicateJob
+	}
+	return job, nil
+}
+
+// permanentError encerra a tarefa sem novas tentativas.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca o erro como definitivo: a tarefa vai direto para dead.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// backoff devolve a espera antes da próxima tentativa: 10 s, 20 s, 40 s… até 1 h.
+func backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	id := fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+	if len(id) > 64 {
+		id = id[len(id)-64:]
+	}
+	return id
+}
@@ -0,0 +1,302 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// claimOverfetch amplia o lote de candidatas para compensar as que excedem o limite por tenant.
+const claimOverfetch = 4
+
+const recurringKeyPrefix = "recurring:"
+
+// Run processa tarefas até o contexto ser cancelado. No desligamento, para de reservar
+// novas tarefas e aguarda as em andamento por até ShutdownTimeout; as interrompidas
+// voltam para a fila como uma tentativa com falha.
+func (q *Queue) Run(ctx context.Context) error {
+	for jobType := range q.recurring {
+		if _, ok := q.handlers[jobType]; !ok {
+			return fmt.Errorf("jobs: tarefa recorrente %q sem handler", jobType)
+		}
+	}
+	q.scheduleRecurring(ctx)
+
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	slots := make(chan struct{}, q.opts.Concurrency)
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	q.logger.Info("job worker starting", zap.String("worker_id", q.opts.WorkerID), zap.Int("concurrency", q.opts.Concurrency))
+	for ctx.Err() == nil {
+		if err := q.reap(ctx); err != nil && ctx.Err() == nil {
+			q.logger.Warn("failed to reap expired jobs", zap.Error(err))
+		}
+
+		claimed, free := 0, cap(slots)-len(slots)
+		if free > 0 {
+			jobs, err := q.claim(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				q.logger.Warn("failed to claim jobs", zap.Error(err))
+			}
+			claimed = len(jobs)
+			for i := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(job domain.Job) {
+					defer wg.Done()
+					defer func() { <-slots }()
+					q.execute(jobCtx, &job)
+				}(jobs[i])
+			}
+		}
+
+		// Lote cheio indica fila com trabalho acumulado: busca de novo sem esperar.
+		if claimed > 0 && claimed == free {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+
+	q.logger.Info("job worker stopping", zap.String("worker_id", q.opts.WorkerID))
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(q.opts.ShutdownTimeout):
+		cancelJobs()
+		<-done
+	}
+	return nil
+}
+
+// claim reserva até limit tarefas prontas, respeitando o limite por tenant. Um advisory
+// lock por tenant serializa a contagem das tarefas em execução entre workers.
+func (q *Queue) claim(ctx context.Context, limit int) ([]domain.Job, error) {
+	types := make([]string, 0, len(q.handlers))
+	for jobType := range q.handlers {
+		types = append(types, jobType)
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	var claimed []domain.Job
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var candidates []domain.Job
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ? AND type IN ?", domain.JobStatusPending, now, types).
+			Order("run_at ASC").
+			Limit(limit * claimOverfetch).
+			Find(&candidates).Error; err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		running, err := lockTenantSlots(tx, candidates)
+		if err != nil {
+			return err
+		}
+		ids := make([]uuid.UUID, 0, limit)
+		for _, job := range candidates {
+			if len(ids) == limit {
+				break
+			}
+			if job.TenantID != nil {
+				if running[*job.TenantID] >= q.opts.TenantConcurrency {
+					continue
+				}
+				running[*job.TenantID]++
+			}
+			job.Status = domain.JobStatusRunning
+			job.Attempts++
+			job.LockedAt = &now
+			job.LockedBy = q.opts.WorkerID
+			claimed = append(claimed, job)
+			ids = append(ids, job.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&domain.Job{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":    domain.JobStatusRunning,
+				"attempts":  gorm.Expr("attempts + 1"),
+				"locked_at": now,
+				"locked_by": q.opts.WorkerID,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// lockTenantSlots bloqueia, em ordem, os tenants das candidatas e devolve quantas
+// tarefas de cada um já estão em execução.
+func lockTenantSlots(tx *gorm.DB, candidates []domain.Job) (map[uuid.UUID]int, error) {
+	seen := make(map[uuid.UUID]bool)
+	tenants := make([]uuid.UUID, 0)
+	for _, job := range candidates {
+		if job.TenantID != nil && !seen[*job.TenantID] {
+			seen[*job.TenantID] = true
+			tenants = append(tenants, *job.TenantID)
+		}
+	}
+	running := make(map[uuid.UUID]int, len(tenants))
+	if len(tenants) == 0 {
+		return running, nil
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].String() < tenants[j].String() })
+	for _, tenantID := range tenants {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "jobs:"+tenantID.String()).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var counts []struct {
+		TenantID uuid.UUID
+		Count    int
+	}
+	if err := tx.Model(&domain.Job{}).
+		Select("tenant_id, COUNT(*) AS count").
+		Where("status = ? AND tenant_id IN ?", domain.JobStatusRunning, tenants).
+		Group("tenant_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		running[count.TenantID] = count.Count
+	}
+	return running, nil
+}
+
+// execute roda o handler com timeout igual ao lease e registra o resultado.
+func (q *Queue) execute(ctx context.Context, job *domain.Job) {
+	runCtx, cancel := context.WithTimeout(ctx, q.opts.Lease)
+	err := q.runHandler(runCtx, job)
+	cancel()
+
+	if err != nil {
+		q.logger.Warn("job failed",
+			zap.String("job_id", job.ID.String()),
+			zap.String("type", job.Type),
+			zap.Int("attempt", job.Attempts),
+			zap.Error(err))
+	}
+	if err := q.finish(context.WithoutCancel(ctx), job, err); err != nil {
+		q.logger.Error("failed to record job result", zap.String("job_id", job.ID.String()), zap.Error(err))
+	}
+}
+
+func (q *Queue) runHandler(ctx context.Context, job *domain.Job) (err error) {
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return Permanent(fmt.Errorf("jobs: tipo %q sem handler", job.Type))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jobs: panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// finish grava o resultado: done em sucesso, nova tentativa com backoff em falhas e dead
+// em erros permanentes ou ao esgotar as tentativas. Tarefas recorrentes nunca são
+// repetidas; em vez disso, a próxima ocorrência é agendada na mesma transação.
+func (q *Queue) finish(ctx context.Context, job *domain.Job, runErr error) error {
+	now := time.Now().UTC()
+	interval, recurring := q.recurring[job.Type]
+
+	updates := map[string]interface{}{
+		"locked_at": nil,
+		"locked_by": "",
+	}
+	switch {
+	case runErr == nil:
+		updates["status"] = domain.JobStatusDone
+		updates["completed_at"] = now
+		updates["last_error"] = ""
+	case recurring || isPermanent(runErr) || job.Attempts >= job.MaxAttempts:
+		updates["status"] = domain.JobStatusDead
+		updates["last_error"] = runErr.Error()
+	default:
+		updates["status"] = domain.JobStatusPending
+		updates["run_at"] = now.Add(backoff(job.Attempts))
+		updates["last_error"] = runErr.Error()
+	}
+
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A condição em locked_by evita sobrescrever uma tarefa retomada por outro worker
+		// depois de o lease expirar.
+		result := tx.Model(&domain.Job{}).
+			Where("id = ? AND status = ? AND locked_by = ?", job.ID, domain.JobStatusRunning, q.opts.WorkerID).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 || !recurring {
+			return result.Error
+		}
+		return q.enqueueRecurring(tx, job.Type, now.Add(interval))
+	})
+}
+
+// reap devolve à fila as tarefas cujo lease expirou (worker interrompido), ou as encerra
+// se já tiverem esgotado as tentativas. Recorrentes sempre voltam, para não interromper o agendamento.
+func (q *Queue) reap(ctx context.Context) error {
+	expired := time.Now().UTC().Add(-q.opts.Lease)
+	return q.db.WithContext(ctx).
+		Model(&domain.Job{}).
+		Where("status = ? AND locked_at < ?", domain.JobStatusRunning, expired).
+		Updates(map[string]interface{}{
+			"status": gorm.Expr("CASE WHEN attempts >= max_attempts AND (unique_key IS NULL OR unique_key NOT LIKE ?) THEN ? ELSE ? END",
+				recurringKeyPrefix+"%", domain.JobStatusDead, domain.JobStatusPending),
+			"locked_at":  nil,
+			"locked_by":  "",
+			"last_error": "lease expirado",
+		}).Error
+}
+
+func (q *Queue) scheduleRecurring(ctx context.Context) {
+	db := q.db.WithContext(ctx)
+	for jobType := range q.recurring {
+		if err := q.enqueueRecurring(db, jobType, time.Now().UTC()); err != nil {
+			q.logger.Warn("failed to schedule recurring job", zap.String("type", jobType), zap.Error(err))
+		}
+	}
+}
+
+func (q *Queue) enqueueRecurring(db *gorm.DB, jobType string, runAt time.Time) error {
+	_, err := Enqueue(db, jobType, struct{}{}, EnqueueOptions{
+		RunAt:       runAt,
+		UniqueKey:   recurringKeyPrefix + jobType,
+		MaxAttempts: 1,
+	})
+	if errors.Is(err, ErrDuplicateJob) {
+		return nil
+	}
+	return err
+}
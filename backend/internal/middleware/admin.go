@@ -4,27 +4,23 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kusmin/gestao_updev/backend/internal/auth"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
 	"github.com/kusmin/gestao_updev/backend/internal/http/response"
 )
 
+// Admin restringe o grupo a administradores. Deve vir depois de Auth, que grava o papel
+// do usuário no contexto.
 func Admin() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims, exists := c.Get("claims")
+		role, exists := c.Get(ContextUserRoleKey)
 		if !exists {
 			response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized", nil)
 			c.Abort()
 			return
 		}
 
-		jwtClaims, ok := claims.(*auth.Claims)
-		if !ok {
-			response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "unauthorized", nil)
-			c.Abort()
-			return
-		}
-
-		if jwtClaims.Role != "admin" {
+		if role != domain.UserRoleAdmin {
 			response.Error(c, http.StatusForbidden, "FORBIDDEN", "forbidden", nil)
 			c.Abort()
 			return
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmin(t *testing.T) {
+	serve := func(role string) int {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if role != "" {
+				c.Set(ContextUserRoleKey, role)
+			}
+		}, Admin())
+		router.GET("/v1/admin/ping", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/admin/ping", nil)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("admin"))
+	assert.Equal(t, http.StatusForbidden, serve("member"))
+	assert.Equal(t, http.StatusUnauthorized, serve(""))
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -14,10 +15,18 @@ const (
 	ContextUserIDKey   = "user_id"
 	ContextUserRoleKey = "user_role"
 	ContextTenantIDKey = "tenant_id"
+	// ContextSessionIDKey fica vazio em tokens emitidos fora de uma sessão.
+	ContextSessionIDKey = "session_id"
 )
 
-// Auth valida o JWT e sincroniza tenant/token.
-func Auth(jwtManager *auth.JWTManager, tenantHeader string) gin.HandlerFunc {
+// SessionChecker confirma que a sessão que emitiu o access token continua ativa.
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// Auth valida o JWT e sincroniza tenant/token. Tokens de sessões revogadas ou expiradas
+// são recusados mesmo antes de vencerem.
+func Auth(jwtManager *auth.JWTManager, tenantHeader string, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -40,6 +49,20 @@ func Auth(jwtManager *auth.JWTManager, tenantHeader string) gin.HandlerFunc {
 			return
 		}
 
+		if claims.SessionID != "" {
+			active, err := sessions.SessionActive(c.Request.Context(), claims.SessionID)
+			if err != nil {
+				response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Falha ao validar a sessão", nil)
+				c.Abort()
+				return
+			}
+			if !active {
+				response.Error(c, http.StatusUnauthorized, "UNAUTHORIZED", "Sessão encerrada", nil)
+				c.Abort()
+				return
+			}
+		}
+
 		headerTenant := c.GetHeader(tenantHeader)
 		if headerTenant != "" && !strings.EqualFold(headerTenant, claims.TenantID) {
 			response.Error(c, http.StatusForbidden, "TENANT_MISMATCH", "Tenant informado não pertence ao token", nil)
@@ -50,6 +73,7 @@ func Auth(jwtManager *auth.JWTManager, tenantHeader string) gin.HandlerFunc {
 		c.Set(ContextTenantIDKey, claims.TenantID)
 		c.Set(ContextUserIDKey, claims.UserID)
 		c.Set(ContextUserRoleKey, claims.Role)
+		c.Set(ContextSessionIDKey, claims.SessionID)
 		c.Next()
 	}
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
+)
+
+type fakeSessions struct {
+	active map[string]bool
+	err    error
+	calls  int
+}
+
+func (f *fakeSessions) SessionActive(_ context.Context, sessionID string) (bool, error) {
+	f.calls++
+	return f.active[sessionID], f.err
+}
+
+func TestAuthChecksSession(t *testing.T) {
+	manager := auth.NewJWTManager("access", "refresh", time.Minute, time.Hour)
+	sessions := &fakeSessions{active: map[string]bool{"ativa": true, "revogada": false}}
+
+	serve := func(token string) int {
+		router := gin.New()
+		router.Use(Auth(manager, "X-Tenant-ID", sessions))
+		router.GET("/v1/ping", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/ping", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	sessionToken := func(sessionID string) string {
+		pair, err := manager.GenerateTokens("user-1", "tenant-1", "admin", sessionID)
+		require.NoError(t, err)
+		return pair.AccessToken
+	}
+
+	assert.Equal(t, http.StatusOK, serve(sessionToken("ativa")))
+	assert.Equal(t, http.StatusUnauthorized, serve(sessionToken("revogada")))
+
+	sessions.calls = 0
+	plain, err := manager.GenerateAccessToken("user-1", "tenant-1", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(plain))
+	assert.Zero(t, sessions.calls, "token sem sessão não consulta o banco")
+
+	sessions.err = errors.New("banco indisponível")
+	assert.Equal(t, http.StatusInternalServerError, serve(sessionToken("ativa")))
+}
@@ -7,6 +7,9 @@ import (
 
 const requestIDHeader = "X-Request-ID"
 
+// ContextRequestIDKey chave do request ID no contexto do gin.
+const ContextRequestIDKey = requestIDHeader
+
 // RequestID injeta um identificador único em cada requisição.
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -14,7 +17,7 @@ func RequestID() gin.HandlerFunc {
 		if reqID == "" {
 			reqID = uuid.NewString()
 		}
-		c.Set(requestIDHeader, reqID)
+		c.Set(ContextRequestIDKey, reqID)
 		c.Writer.Header().Set(requestIDHeader, reqID)
 		c.Next()
 	}
@@ -14,6 +14,11 @@ var publicPrefixes = []string{
 	"/v1/auth/signup",
 	"/v1/auth/login",
 	"/v1/auth/refresh",
+	// Inclui /auth/logout-all, cujo tenant vem do access token.
+	"/v1/auth/logout",
+	"/v1/auth/password",
+	"/v1/auth/email",
+	"/v1/auth/mfa",
 	"/swagger",
 }
 
@@ -26,6 +26,20 @@ func TestTenantEnforcer_PublicRoute(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestTenantEnforcer_LogoutIsPublic(t *testing.T) {
+	router := gin.New()
+	router.Use(TenantEnforcer("X-Tenant-ID"))
+	router.POST("/v1/auth/logout", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/auth/logout", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
 func TestTenantEnforcer_PrivateRoute_NoHeader(t *testing.T) {
 	// setup
 	router := gin.New()
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GatewayNotifier envia SMS ou WhatsApp por um gateway HTTP. A mensagem é
+// postada como JSON {"channel", "to", "body"}, autenticada por token Bearer.
+type GatewayNotifier struct {
+	channel  string
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewSMS cria o adaptador de SMS.
+func NewSMS(endpoint, token string) *GatewayNotifier {
+	return newGateway(ChannelSMS, endpoint, token)
+}
+
+// NewWhatsApp cria o adaptador de WhatsApp.
+func NewWhatsApp(endpoint, token string) *GatewayNotifier {
+	return newGateway(ChannelWhatsApp, endpoint, token)
+}
+
+func newGateway(channel, endpoint, token string) *GatewayNotifier {
+	return &GatewayNotifier{
+		channel:  channel,
+		endpoint: endpoint,
+		token:    token,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *GatewayNotifier) Channel() string {
+	return n.channel
+}
+
+func (n *GatewayNotifier) Send(ctx context.Context, msg Message) error {
+	if strings.TrimSpace(msg.To) == "" {
+		return ErrNoRecipient
+	}
+	body, err := json.Marshal(map[string]string{
+		"channel": n.channel,
+		"to":      msg.To,
+		"body":    msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notify: gateway %s respondeu %d", n.channel, resp.StatusCode)
+	}
+	return nil
+}
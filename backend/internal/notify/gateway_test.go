@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatewayNotifierSend(t *testing.T) {
+	var payload map[string]string
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewWhatsApp(server.URL, "token-teste")
+	require.Equal(t, ChannelWhatsApp, notifier.Channel())
+	require.NoError(t, notifier.Send(context.Background(), Message{To: "+5511999999999", Body: "Olá"}))
+
+	assert.Equal(t, "Bearer token-teste", authorization)
+	assert.Equal(t, map[string]string{"channel": "whatsapp", "to": "+5511999999999", "body": "Olá"}, payload)
+}
+
+func TestGatewayNotifierErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	notifier := NewSMS(server.URL, "")
+	assert.ErrorIs(t, notifier.Send(context.Background(), Message{Body: "Olá"}), ErrNoRecipient)
+	assert.ErrorContains(t, notifier.Send(context.Background(), Message{To: "+5511999999999", Body: "Olá"}), "502")
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory guarda as mensagens em vez de entregá-las. Serve para testes e para
+// ambientes sem provedor configurado.
+type Memory struct {
+	channel string
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemory cria um notifier em memória para o canal informado.
+func NewMemory(channel string) *Memory {
+	return &Memory{channel: channel}
+}
+
+// Channel implementa Notifier.
+func (m *Memory) Channel() string { return m.channel }
+
+// Send registra a mensagem.
+func (m *Memory) Send(_ context.Context, msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages devolve uma cópia das mensagens registradas, na ordem de envio.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Reset descarta as mensagens registradas.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
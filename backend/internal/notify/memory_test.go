@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNotifierRecordsMessages(t *testing.T) {
+	notifier := NewMemory(ChannelEmail)
+	require.Equal(t, ChannelEmail, notifier.Channel())
+
+	require.NoError(t, notifier.Send(context.Background(), Message{To: "ana@example.com", Subject: "Oi", Body: "Olá"}))
+	assert.ErrorIs(t, notifier.Send(context.Background(), Message{Body: "sem destinatário"}), ErrNoRecipient)
+
+	messages := notifier.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "ana@example.com", messages[0].To)
+
+	messages[0].To = "alterado"
+	assert.Equal(t, "ana@example.com", notifier.Messages()[0].To, "Messages devolve uma cópia")
+
+	notifier.Reset()
+	assert.Empty(t, notifier.Messages())
+}
@@ -0,0 +1,32 @@
+// Package notify envia mensagens a clientes por canais plugáveis (e-mail, SMS, WhatsApp).
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Canais suportados.
+const (
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// ErrNoRecipient sinaliza mensagem sem destinatário.
+var ErrNoRecipient = errors.New("notify: destinatário vazio")
+
+// Message é o conteúdo entregue ao destinatário. Subject é ignorado por canais
+// sem assunto; HTMLBody, quando presente, acompanha Body em e-mails.
+type Message struct {
+	To       string
+	Subject  string
+	Body     string
+	HTMLBody string
+}
+
+// Notifier entrega mensagens por um canal.
+type Notifier interface {
+	Channel() string
+	Send(ctx context.Context, msg Message) error
+}
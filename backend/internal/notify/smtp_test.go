@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpStub é um servidor SMTP mínimo que aceita uma conversa por conexão e guarda
+// o remetente, os destinatários e os dados recebidos.
+type smtpStub struct {
+	listener net.Listener
+	mu       sync.Mutex
+	from     string
+	rcpt     []string
+	data     string
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	stub := &smtpStub{listener: listener}
+	go stub.serve()
+	t.Cleanup(func() { listener.Close() })
+	return stub
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStub) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 stub")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 envie os dados")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 tchau")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifierSend(t *testing.T) {
+	stub := newSMTPStub(t)
+	notifier := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: stub.port(), From: "agenda@example.com"})
+
+	err := notifier.Send(context.Background(), Message{
+		To:      "cliente@example.com",
+		Subject: "Lembrete do horário",
+		Body:    "Olá!\nAté amanhã.",
+	})
+	require.NoError(t, err)
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.Equal(t, "agenda@example.com", stub.from)
+	assert.Equal(t, []string{"cliente@example.com"}, stub.rcpt)
+	assert.Contains(t, stub.data, "To: cliente@example.com\r\n")
+	assert.Contains(t, stub.data, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, stub.data, "Olá!\r\nAté amanhã.")
+}
+
+func TestSMTPNotifierSendsMultipartWithHTML(t *testing.T) {
+	stub := newSMTPStub(t)
+	notifier := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: stub.port(), From: "agenda@example.com"})
+
+	err := notifier.Send(context.Background(), Message{
+		To:       "cliente@example.com",
+		Subject:  "Lembrete",
+		Body:     "texto",
+		HTMLBody: "<p>texto</p>",
+	})
+	require.NoError(t, err)
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+	assert.Contains(t, stub.data, "multipart/alternative")
+	assert.Contains(t, stub.data, "Content-Type: text/html; charset=utf-8\r\n\r\n<p>texto</p>")
+}
+
+func TestSMTPNotifierRequiresRecipient(t *testing.T) {
+	notifier := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: 1})
+	assert.ErrorIs(t, notifier.Send(context.Background(), Message{Body: "x"}), ErrNoRecipient)
+}
+
+func TestSMTPNotifierDialError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	notifier := NewSMTP(SMTPConfig{Host: "127.0.0.1", Port: port})
+	err = notifier.Send(context.Background(), Message{To: "cliente@example.com"})
+	assert.Error(t, err)
+}
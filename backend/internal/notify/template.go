@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template é o conteúdo de uma mensagem antes da renderização. Subject e Text usam
+// text/template; HTML usa html/template, que escapa os dados interpolados.
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Render aplica os dados aos três templates; partes vazias resultam em campos vazios.
+func (t Template) Render(data interface{}, funcs map[string]interface{}) (Message, error) {
+	var msg Message
+	var err error
+	if msg.Subject, err = renderText("subject", t.Subject, data, funcs); err != nil {
+		return Message{}, err
+	}
+	msg.Subject = strings.TrimSpace(msg.Subject)
+	if msg.Body, err = renderText("text", t.Text, data, funcs); err != nil {
+		return Message{}, err
+	}
+	if t.HTML == "" {
+		return msg, nil
+	}
+	tpl, err := htmltemplate.New("html").Funcs(funcs).Option("missingkey=error").Parse(t.HTML)
+	if err != nil {
+		return Message{}, err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return Message{}, err
+	}
+	msg.HTMLBody = buf.String()
+	return msg, nil
+}
+
+func renderText(name, source string, data interface{}, funcs map[string]interface{}) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	tpl, err := texttemplate.New(name).Funcs(funcs).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateRender(t *testing.T) {
+	tpl := Template{
+		Subject: "  Olá, {{.Name}}  ",
+		Text:    "Olá, {{.Name}}! Total: {{upper .Total}}",
+		HTML:    "<p>Olá, {{.Name}}!</p>",
+	}
+	data := struct {
+		Name  string
+		Total string
+	}{Name: "<Maria>", Total: "dez"}
+	funcs := map[string]interface{}{"upper": strings.ToUpper}
+
+	msg, err := tpl.Render(data, funcs)
+	require.NoError(t, err)
+	assert.Equal(t, "Olá, <Maria>", msg.Subject)
+	assert.Equal(t, "Olá, <Maria>! Total: DEZ", msg.Body)
+	assert.Equal(t, "<p>Olá, &lt;Maria&gt;!</p>", msg.HTMLBody, "HTML escapa os dados")
+}
+
+func TestTemplateRenderErrors(t *testing.T) {
+	_, err := Template{Subject: "{{.Name"}.Render(struct{ Name string }{}, nil)
+	assert.Error(t, err, "sintaxe inválida")
+
+	_, err = Template{Text: "{{.Missing}}"}.Render(struct{ Name string }{}, nil)
+	assert.Error(t, err, "campo inexistente")
+
+	_, err = Template{HTML: "{{.Missing}}"}.Render(map[string]string{}, nil)
+	assert.Error(t, err, "chave inexistente")
+}
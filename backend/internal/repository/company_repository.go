@@ -16,9 +16,26 @@ func NewCompanyRepository(db *gorm.DB) *CompanyRepository {
 	return &CompanyRepository{db: db}
 }
 
+type companyTxKey struct{}
+
+// WithinTransaction executa fn em uma transação; as chamadas do repositório feitas
+// com o contexto recebido por fn participam dela.
+func (r *CompanyRepository) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, companyTxKey{}, tx))
+	})
+}
+
+func (r *CompanyRepository) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(companyTxKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return r.db.WithContext(ctx)
+}
+
 func (r *CompanyRepository) ListAll(ctx context.Context) ([]domain.Company, error) {
 	var companies []domain.Company
-	if err := r.db.WithContext(ctx).Find(&companies).Error; err != nil {
+	if err := r.conn(ctx).Find(&companies).Error; err != nil {
 		return nil, err
 	}
 	return companies, nil
@@ -26,20 +43,24 @@ func (r *CompanyRepository) ListAll(ctx context.Context) ([]domain.Company, erro
 
 func (r *CompanyRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Company, error) {
 	var company domain.Company
-	if err := r.db.WithContext(ctx).First(&company, "id = ?", id).Error; err != nil {
+	if err := r.conn(ctx).First(&company, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &company, nil
 }
 
 func (r *CompanyRepository) Create(ctx context.Context, company *domain.Company) error {
-	return r.db.WithContext(ctx).Create(company).Error
+	return r.conn(ctx).Create(company).Error
 }
 
 func (r *CompanyRepository) Update(ctx context.Context, company *domain.Company) error {
-	return r.db.WithContext(ctx).Save(company).Error
+	return r.conn(ctx).Save(company).Error
 }
 
 func (r *CompanyRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Company{}, id).Error
+	return r.conn(ctx).Delete(&domain.Company{}, id).Error
+}
+
+func (r *CompanyRepository) CreateAuditLog(ctx context.Context, entry *domain.AuditLog) error {
+	return r.conn(ctx).Create(entry).Error
 }
@@ -30,6 +30,7 @@ type Server struct {
 	logger    *zap.Logger
 	engine    *gin.Engine
 	db        *gorm.DB
+	svc       *service.Service
 	telemetry *telemetry.Telemetry
 }
 
@@ -40,6 +41,12 @@ func New(cfg *config.Config, logger *zap.Logger, db *gorm.DB, telem *telemetry.T
 	}
 
 	engine := gin.New()
+	// O IP do cliente é chave do limitador de login: X-Forwarded-For só vale quando a
+	// conexão vem de um proxy configurado. Sem TRUSTED_PROXIES, vale o IP da conexão.
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies; ignoring forwarded headers", zap.Error(err))
+		_ = engine.SetTrustedProxies(nil)
+	}
 	engine.Use(gin.Recovery())
 	engine.Use(middleware.RequestID())
 	if telem != nil && telem.TracerProvider() != nil {
@@ -52,7 +59,8 @@ func New(cfg *config.Config, logger *zap.Logger, db *gorm.DB, telem *telemetry.T
 	companyRepo := repository.NewCompanyRepository(db)
 
 	// Services
-	jwtManager := auth.NewJWTManager(cfg.JWTAccessSecret, cfg.JWTRefreshSecret, cfg.JWTAccessTTL, cfg.JWTRefreshTTL)
+	jwtManager := auth.NewJWTManager(cfg.JWTAccessSecret, cfg.JWTRefreshSecret, cfg.JWTAccessTTL, cfg.JWTRefreshTTL).
+		WithRefreshTokenLength(cfg.RefreshTokenLength)
 	svc := service.New(cfg, repo, jwtManager, logger)
 	companySvc := service.NewCompanyService(companyRepo)
 
@@ -62,7 +70,7 @@ func New(cfg *config.Config, logger *zap.Logger, db *gorm.DB, telem *telemetry.T
 
 	api := engine.Group("/v1")
 	api.Use(middleware.TenantEnforcer(cfg.TenantHeader))
-	registerRoutes(api, cfg, apiHandler, companyHandler, jwtManager)
+	registerRoutes(api, cfg, apiHandler, companyHandler, jwtManager, svc)
 
 	engine.GET("/v1/healthz", func(c *gin.Context) {
 		response.Success(c, http.StatusOK, gin.H{
@@ -82,6 +90,7 @@ func New(cfg *config.Config, logger *zap.Logger, db *gorm.DB, telem *telemetry.T
 		logger:    logger,
 		engine:    engine,
 		db:        db,
+		svc:       svc,
 		telemetry: telem,
 	}
 }
@@ -96,6 +105,13 @@ func (s *Server) Run(ctx context.Context) error {
 
 	errCh := make(chan error, 1)
 
+	// Com o cmd/worker em execução, as rotinas periódicas rodam na fila de tarefas.
+	if s.cfg.APIBackgroundTasks {
+		for _, task := range s.svc.PeriodicTasks() {
+			go s.runPeriodic(ctx, task.Interval, task.Type, task.Run)
+		}
+	}
+
 	go func() {
 		s.logger.Info("HTTP server starting", zap.String("addr", s.cfg.Address()))
 		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -114,19 +130,57 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 }
 
+// runPeriodic executa fn a cada interval até o contexto ser cancelado.
+func (s *Server) runPeriodic(ctx context.Context, interval time.Duration, name string, fn func(context.Context, time.Time) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			count, err := fn(ctx, now.UTC())
+			if err != nil {
+				s.logger.Warn("periodic task failed", zap.String("task", name), zap.Error(err))
+				continue
+			}
+			if count > 0 {
+				s.logger.Info("periodic task processed", zap.String("task", name), zap.Int("count", count))
+			}
+		}
+	}
+}
+
 // Router expõe a instância do gin.Engine para middlewares externos.
 func (s *Server) Router() *gin.Engine {
 	return s.engine
 }
 
-func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, companyHandler *handler.CompanyHandler, jwtManager *auth.JWTManager) {
+func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, companyHandler *handler.CompanyHandler, jwtManager *auth.JWTManager, sessions middleware.SessionChecker) {
 	authGroup := api.Group("/auth")
+	authGroup.Use(handler.AuditActor())
 	authGroup.POST("/signup", h.Signup)
 	authGroup.POST("/login", h.Login)
 	authGroup.POST("/refresh", h.RefreshToken)
+	authGroup.POST("/logout", h.Logout)
+	authGroup.POST("/password/forgot", h.ForgotPassword)
+	authGroup.POST("/password/reset", h.ResetPassword)
+	authGroup.POST("/email/verify", h.VerifyEmail)
+	authGroup.POST("/mfa/verify", h.VerifyMFA)
+	authGroup.POST("/mfa/setup", h.SetupMFAWithChallenge)
+	authGroup.POST("/mfa/enable", h.EnableMFAWithChallenge)
 
 	protected := api.Group("/")
-	protected.Use(middleware.Auth(jwtManager, cfg.TenantHeader))
+	protected.Use(middleware.Auth(jwtManager, cfg.TenantHeader, sessions), handler.AuditActor())
+
+	protected.POST("/auth/logout-all", h.LogoutAll)
+	protected.GET("/me/sessions", h.ListMySessions)
+	protected.DELETE("/me/sessions/:id", h.RevokeMySession)
+	protected.POST("/me/email/verification", h.ResendEmailVerification)
+	protected.POST("/me/mfa/setup", h.StartMFASetup)
+	protected.POST("/me/mfa/enable", h.EnableMFA)
+	protected.POST("/me/mfa/disable", h.DisableMFA)
+	protected.POST("/me/mfa/recovery-codes", h.RegenerateRecoveryCodes)
 
 	protected.GET("/companies/me", h.GetCompany)
 	protected.PUT("/companies/me", h.UpdateCompany)
@@ -136,6 +190,9 @@ func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, co
 	protected.GET("/users/:id", h.GetUser)
 	protected.PATCH("/users/:id", h.UpdateUser)
 	protected.DELETE("/users/:id", h.DeleteUser)
+	protected.GET("/users/:id/sessions", h.ListUserSessions)
+	protected.DELETE("/users/:id/sessions/:session_id", h.RevokeUserSession)
+	protected.POST("/users/:id/unlock", h.UnlockUser)
 
 	protected.GET("/clients", h.ListClients)
 	protected.POST("/clients", h.CreateClient)
@@ -144,6 +201,12 @@ func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, co
 	protected.DELETE("/clients/:id", h.DeleteClient)
 
 	protected.GET("/professionals", h.ListProfessionals)
+	protected.POST("/professionals", h.CreateProfessional)
+	protected.GET("/professionals/:id", h.GetProfessional)
+	protected.PUT("/professionals/:id", h.UpdateProfessional)
+	protected.DELETE("/professionals/:id", h.DeactivateProfessional)
+	protected.GET("/professionals/:id/availability", h.GetProfessionalAvailability)
+	protected.PUT("/professionals/:id/availability", h.ReplaceProfessionalAvailability)
 
 	protected.GET("/services", h.ListServices)
 	protected.POST("/services", h.CreateService)
@@ -159,23 +222,53 @@ func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, co
 
 	protected.GET("/inventory/movements", h.ListInventoryMovements)
 	protected.POST("/inventory/movements", h.CreateInventoryMovement)
+	protected.POST("/inventory/products/:id/rebuild", h.RebuildProductStock)
 
 	protected.GET("/bookings", h.ListBookings)
 	protected.POST("/bookings", h.CreateBooking)
 	protected.PATCH("/bookings/:id", h.UpdateBooking)
 	protected.POST("/bookings/:id/cancel", h.CancelBooking)
+	protected.POST("/bookings/:id/checkout", h.CheckoutBooking)
+	protected.GET("/bookings/:id/reminders", h.ListBookingReminders)
+	protected.GET("/availability/slots", h.ListAvailableSlots)
+	protected.GET("/availability/exceptions", h.ListAvailabilityExceptions)
+	protected.POST("/availability/exceptions", h.CreateAvailabilityException)
+	protected.DELETE("/availability/exceptions/:id", h.DeleteAvailabilityException)
+	protected.GET("/waitlist", h.ListWaitlist)
+	protected.POST("/waitlist", h.CreateWaitlistEntry)
+	protected.DELETE("/waitlist/:id", h.CancelWaitlistEntry)
+	protected.POST("/waitlist/:id/accept", h.AcceptWaitlistOffer)
 
 	protected.GET("/sales/orders", h.ListSalesOrders)
 	protected.POST("/sales/orders", h.CreateSalesOrder)
 	protected.PATCH("/sales/orders/:id", h.UpdateSalesOrder)
 	protected.POST("/sales/orders/:id/payments", h.CreatePayment)
+	protected.POST("/sales/orders/:id/refunds", h.CreateRefund)
 	protected.GET("/payments", h.ListPayments)
 
+	protected.GET("/webhooks", h.ListWebhooks)
+	protected.POST("/webhooks", h.CreateWebhook)
+	protected.PUT("/webhooks/:id", h.UpdateWebhook)
+	protected.DELETE("/webhooks/:id", h.DeleteWebhook)
+	protected.GET("/webhook-deliveries", h.ListWebhookDeliveries)
+	protected.POST("/webhook-deliveries/:id/retry", h.RetryWebhookDelivery)
+
+	protected.GET("/email-templates", h.ListEmailTemplates)
+	protected.GET("/email-templates/:key", h.GetEmailTemplate)
+	protected.PUT("/email-templates/:key", h.UpdateEmailTemplate)
+	protected.DELETE("/email-templates/:key", h.ResetEmailTemplate)
+	protected.POST("/email-templates/:key/preview", h.PreviewEmailTemplate)
+	protected.GET("/email-templates/:key/versions", h.ListEmailTemplateVersions)
+	protected.POST("/email-templates/:key/versions/:version/restore", h.RestoreEmailTemplateVersion)
+
+	protected.GET("/audit-logs", h.ListAuditLogs)
+
 	protected.GET("/dashboard/daily", h.DashboardDaily)
+	protected.GET("/dashboard/attendance", h.DashboardAttendance)
 
 	// Admin routes
 	admin := api.Group("/admin")
-	admin.Use(middleware.Auth(jwtManager, cfg.TenantHeader), middleware.Admin())
+	admin.Use(middleware.Auth(jwtManager, cfg.TenantHeader, sessions), middleware.Admin(), handler.AuditActor())
 	companyHandler.RegisterRoutes(admin)
 	h.RegisterAdminUserRoutes(admin)
 	h.RegisterAdminProductRoutes(admin)
@@ -184,4 +277,5 @@ func registerRoutes(api *gin.RouterGroup, cfg *config.Config, h *handler.API, co
 	h.RegisterAdminBookingRoutes(admin)
 	h.RegisterAdminSalesRoutes(admin)
 	h.RegisterAdminDashboardRoutes(admin)
+	h.RegisterAdminAuditRoutes(admin)
 }
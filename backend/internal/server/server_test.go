@@ -1,22 +1,28 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
 	"github.com/kusmin/gestao_updev/backend/internal/config"
 )
 
-func setupTestServer(t *testing.T) *Server {
+func setupTestServer(t *testing.T, trustedProxies ...string) *Server {
 	cfg := &config.Config{
+		TrustedProxies:   trustedProxies,
 		AppEnv:           "test",
 		TenantHeader:     "X-Test-Tenant",
 		JWTAccessSecret:  "test-access",
@@ -49,3 +55,68 @@ func TestProtectedRouteRequiresAuth(t *testing.T) {
 	s.engine.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestClientIPIgnoresUntrustedForwardedFor(t *testing.T) {
+	clientIP := func(s *Server) string {
+		s.engine.GET("/test/client-ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test/client-ip", nil)
+		req.RemoteAddr = "192.0.2.10:4321"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		s.engine.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "192.0.2.10", clientIP(setupTestServer(t)))
+	assert.Equal(t, "203.0.113.7", clientIP(setupTestServer(t, "192.0.2.0/24")))
+}
+
+func TestAuditLogRoutesRequireAdmin(t *testing.T) {
+	s := setupTestServer(t)
+	jwtManager := auth.NewJWTManager("test-access", "test-refresh", 15*time.Minute, 24*time.Hour)
+	tenantID := uuid.NewString()
+	request := func(path, role string) int {
+		token, err := jwtManager.GenerateAccessToken(uuid.NewString(), tenantID, role)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Test-Tenant", tenantID)
+		s.engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for _, path := range []string{"/v1/audit-logs", "/v1/admin/audit-logs"} {
+		assert.Equal(t, http.StatusForbidden, request(path, "member"), path)
+		// O banco de teste não tem as tabelas; basta que a rota passe da autorização.
+		code := request(path, "admin")
+		assert.NotEqual(t, http.StatusUnauthorized, code, path)
+		assert.NotEqual(t, http.StatusForbidden, code, path)
+	}
+}
+
+func TestRunPeriodicKeepsRunningAfterErrors(t *testing.T) {
+	srv := &Server{logger: zap.NewNop()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	done := make(chan struct{})
+	go func() {
+		srv.runPeriodic(ctx, time.Millisecond, "test", func(context.Context, time.Time) (int, error) {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return 0, errors.New("falha transitória")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runPeriodic não parou com o contexto cancelado")
+	}
+	assert.GreaterOrEqual(t, calls, 3, "erros não interrompem o ticker")
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// Ações registradas na auditoria.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionLock   = "lock"
+	AuditActionUnlock = "unlock"
+)
+
+// Entidades registradas na auditoria.
+const (
+	AuditEntityCompany               = "company"
+	AuditEntityUser                  = "user"
+	AuditEntityClient                = "client"
+	AuditEntityService               = "service"
+	AuditEntityProduct               = "product"
+	AuditEntityInventoryMovement     = "inventory_movement"
+	AuditEntityProfessional          = "professional"
+	AuditEntityAvailabilityRules     = "availability_rules"
+	AuditEntityAvailabilityException = "availability_exception"
+	AuditEntityBooking               = "booking"
+	AuditEntityWaitlistEntry         = "waitlist_entry"
+	AuditEntitySalesOrder            = "sales_order"
+	AuditEntityPayment               = "payment"
+	AuditEntityRefund                = "refund"
+	AuditEntityWebhookSubscription   = "webhook_subscription"
+	AuditEntityWebhookDelivery       = "webhook_delivery"
+	AuditEntityEmailTemplate         = "email_template"
+	AuditEntitySession               = "session"
+)
+
+const auditRequestIDMaxLen = 64
+
+// auditIgnoredFields não entram no diff: são derivados ou sensíveis.
+var auditIgnoredFields = map[string]bool{
+	"created_at":  true,
+	"updated_at":  true,
+	"deleted_at":  true,
+	"secret":      true,
+	"balance_due": true,
+}
+
+// AuditActor identifica a origem das mutações feitas com o contexto.
+type AuditActor struct {
+	UserID    *uuid.UUID
+	RequestID string
+	// IP e UserAgent do cliente, registrados nas sessões de login.
+	IP        string
+	UserAgent string
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor anexa o ator ao contexto repassado ao service layer.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFrom(ctx context.Context) AuditActor {
+	if ctx == nil {
+		return AuditActor{}
+	}
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor
+}
+
+// AuditLogFilter filtros da consulta de auditoria.
+type AuditLogFilter struct {
+	TenantID *uuid.UUID
+	Entity   string
+	EntityID *uuid.UUID
+	ActorID  *uuid.UUID
+	From     *time.Time
+	To       *time.Time
+	Page     int
+	PerPage  int
+}
+
+// ListAuditLogs lista a auditoria do tenant, mais recente primeiro.
+func (s *Service) ListAuditLogs(ctx context.Context, tenantID uuid.UUID, filter AuditLogFilter) ([]domain.AuditLog, int64, error) {
+	filter.TenantID = &tenantID
+	return s.ListAllAuditLogs(ctx, filter)
+}
+
+// ListAllAuditLogs lista a auditoria de todos os tenants (admin).
+func (s *Service) ListAllAuditLogs(ctx context.Context, filter AuditLogFilter) ([]domain.AuditLog, int64, error) {
+	page, perPage := s.clampPagination(filter.Page, filter.PerPage)
+	query := s.dbWithContext(ctx).Model(&domain.AuditLog{})
+	if filter.TenantID != nil {
+		query = query.Where("tenant_id = ?", *filter.TenantID)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	logs := []domain.AuditLog{}
+	if total == 0 {
+		return logs, 0, nil
+	}
+	if err := query.
+		Order("created_at DESC").
+		Limit(perPage).
+		Offset((page - 1) * perPage).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// audit grava o registro de auditoria na transação tx, com ator e request ID do contexto
+// da própria transação. before é nil em criações e after é nil em exclusões.
+func (s *Service) audit(tx *gorm.DB, tenantID uuid.UUID, entity, action string, entityID uuid.UUID, before, after interface{}) error {
+	entry, err := newAuditLog(tx.Statement.Context, tenantID, entity, action, entityID, before, after)
+	if err != nil || entry == nil {
+		return err
+	}
+	return tx.Create(entry).Error
+}
+
+// deleteTenantRecord remove (soft delete) o registro do tenant e audita o estado anterior.
+// Registros inexistentes são ignorados, como no Delete direto.
+func (s *Service) deleteTenantRecord(ctx context.Context, tenantID, id uuid.UUID, record interface{}, entity string) error {
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		err := tx.Where("tenant_id = ? AND id = ?", tenantID, id).First(record).Error
+		if err != nil {
+			return ignoreNotFound(err)
+		}
+		if err := tx.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(record).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, entity, AuditActionDelete, id, record, nil)
+	})
+}
+
+// newAuditLog monta o registro de auditoria. Atualizações sem campos alterados não
+// geram registro (entry nil).
+func newAuditLog(ctx context.Context, tenantID uuid.UUID, entity, action string, entityID uuid.UUID, before, after interface{}) (*domain.AuditLog, error) {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if action == AuditActionUpdate && len(changes) == 0 {
+		return nil, nil
+	}
+	actor := auditActorFrom(ctx)
+	// O request ID pode vir do cliente (X-Request-ID); limita ao tamanho da coluna.
+	if len(actor.RequestID) > auditRequestIDMaxLen {
+		actor.RequestID = actor.RequestID[:auditRequestIDMaxLen]
+	}
+	entry := &domain.AuditLog{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		Entity:    entity,
+		Action:    action,
+		ActorID:   actor.UserID,
+		RequestID: actor.RequestID,
+		Metadata:  datatypes.JSONMap{"changes": changes},
+	}
+	if entityID != uuid.Nil {
+		entry.EntityID = &entityID
+	}
+	return entry, nil
+}
+
+// auditChanges compara as representações JSON de before e after e devolve, por campo
+// alterado, {"before": ..., "after": ...}.
+func auditChanges(before, after interface{}) (map[string]interface{}, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	for key, value := range afterFields {
+		if previous, ok := beforeFields[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[key] = map[string]interface{}{"before": beforeFields[key], "after": value}
+		}
+	}
+	for key, previous := range beforeFields {
+		if _, ok := afterFields[key]; !ok {
+			changes[key] = map[string]interface{}{"before": previous, "after": nil}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(record interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if record == nil || (reflect.ValueOf(record).Kind() == reflect.Ptr && reflect.ValueOf(record).IsNil()) {
+		return fields, nil
+	}
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for key := range fields {
+		if auditIgnoredFields[key] {
+			delete(fields, key)
+		}
+	}
+	return fields, nil
+}
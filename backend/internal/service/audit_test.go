@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestAuditChanges(t *testing.T) {
+	before := domain.Client{Name: "Ana", Email: "ana@example.com"}
+	after := before
+	after.Name = "Ana Paula"
+	after.UpdatedAt = time.Now()
+
+	changes, err := auditChanges(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": map[string]interface{}{"before": "Ana", "after": "Ana Paula"},
+	}, changes)
+
+	created, err := auditChanges(nil, &after)
+	require.NoError(t, err)
+	assert.Contains(t, created, "email")
+	assert.NotContains(t, created, "updated_at")
+
+	deleted, err := auditChanges(&before, (*domain.Client)(nil))
+	require.NoError(t, err)
+	assert.Nil(t, deleted["name"].(map[string]interface{})["after"])
+}
+
+func TestNewAuditLogSkipsEmptyUpdates(t *testing.T) {
+	client := domain.Client{Name: "Ana"}
+	entry, err := newAuditLog(context.Background(), uuid.New(), AuditEntityClient, AuditActionUpdate, uuid.New(), client, client)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	ctx := WithAuditActor(context.Background(), AuditActor{RequestID: strings.Repeat("x", 100)})
+	entry, err = newAuditLog(ctx, uuid.New(), AuditEntityClient, AuditActionDelete, uuid.New(), client, nil)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Nil(t, entry.ActorID)
+	assert.Len(t, entry.RequestID, auditRequestIDMaxLen)
+}
+
+func TestClientMutationsAreAudited(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	actorID := uuid.New()
+	ctx := WithAuditActor(context.Background(), AuditActor{UserID: &actorID, RequestID: "req-audit"})
+
+	client, err := testSvc.CreateClient(ctx, tenant.ID, ClientInput{Name: "Auditado", Email: "audit@example.com"})
+	require.NoError(t, err)
+	_, err = testSvc.UpdateClient(ctx, tenant.ID, client.ID, ClientInput{Name: "Renomeado", Email: "audit@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, testSvc.DeleteClient(ctx, tenant.ID, client.ID))
+
+	logs, total, err := testSvc.ListAuditLogs(context.Background(), tenant.ID, AuditLogFilter{
+		Entity:   AuditEntityClient,
+		EntityID: &client.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, logs, 3)
+
+	actions := map[string]domain.AuditLog{}
+	for _, entry := range logs {
+		actions[entry.Action] = entry
+		require.NotNil(t, entry.ActorID)
+		assert.Equal(t, actorID, *entry.ActorID)
+		assert.Equal(t, "req-audit", entry.RequestID)
+	}
+	changes := actions[AuditActionUpdate].Metadata["changes"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"before": "Auditado", "after": "Renomeado"}, changes["name"])
+	assert.NotContains(t, changes, "email")
+
+	// Outros tenants não enxergam os registros; a consulta administrativa sim.
+	other, _ := createTestTenant()
+	_, total, err = testSvc.ListAuditLogs(context.Background(), other.ID, AuditLogFilter{EntityID: &client.ID})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	_, total, err = testSvc.ListAllAuditLogs(context.Background(), AuditLogFilter{ActorID: &actorID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestSalesOrderTransitionsAreAudited(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Pedido Auditado", "order-audit@example.com", nil)
+	product := seedProductRecord(t, tenant.ID, "Pomada", "AUDIT-ORDER")
+	seedInventoryStock(t, tenant.ID, product.ID, 5)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: product.ID, Quantity: 1, UnitPrice: 30},
+	})
+
+	// A quitação muda o status fora de UpdateSalesOrder e também precisa ser auditada.
+	_, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 30, PaidAt: time.Now()})
+	require.NoError(t, err)
+
+	logs, total, err := testSvc.ListAuditLogs(ctx, tenant.ID, AuditLogFilter{
+		Entity:   AuditEntitySalesOrder,
+		EntityID: &order.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, AuditActionUpdate, logs[0].Action)
+	changes := logs[0].Metadata["changes"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"before": domain.SalesOrderStatusDraft,
+		"after":  domain.SalesOrderStatusPaid,
+	}, changes["status"])
+}
@@ -52,24 +52,35 @@ func (s *Service) Signup(ctx context.Context, input SignupInput) (*SignupResult,
 		Active:       true,
 	}
 
+	var (
+		tokenPair         *auth.TokenPair
+		verificationToken string
+	)
 	err = s.repo.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(company).Error; err != nil {
 			return err
 		}
+		if err := s.audit(tx, company.ID, AuditEntityCompany, AuditActionCreate, company.ID, nil, company); err != nil {
+			return err
+		}
 		user.TenantID = company.ID
 		if err := tx.Create(user).Error; err != nil {
 			return err
 		}
-		return nil
+		if err := s.audit(tx, company.ID, AuditEntityUser, AuditActionCreate, user.ID, nil, user); err != nil {
+			return err
+		}
+		verificationToken, err = s.issueUserToken(tx, user, domain.UserTokenEmailVerification, s.emailVerificationTTL())
+		if err != nil {
+			return err
+		}
+		tokenPair, err = s.startSession(ctx, tx, user)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-
-	tokenPair, err := s.jwt.GenerateTokens(user.ID.String(), company.ID.String(), user.Role)
-	if err != nil {
-		return nil, err
-	}
+	s.sendEmailVerification(ctx, user, verificationToken)
 
 	return &SignupResult{
 		TenantID: company.ID,
@@ -78,60 +89,72 @@ func (s *Service) Signup(ctx context.Context, input SignupInput) (*SignupResult,
 	}, nil
 }
 
-// Login realiza autenticação via email/senha e retorna os tokens.
+// Login realiza autenticação via email/senha e retorna os tokens. Quando o usuário usa
+// MFA (ou o tenant o exige), devolve um *MFAChallenge no lugar dos tokens. Falhas
+// repetidas do e-mail ou do IP são recusadas com *LoginThrottledError.
 func (s *Service) Login(ctx context.Context, email, password string) (*domain.User, *auth.TokenPair, error) {
+	attempt, err := s.reserveLoginAttempt(ctx, email, time.Now().UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var user domain.User
 	if err := s.dbWithContext(ctx).
 		Where("lower(email) = ?", s.sanitizeEmail(email)).
 		First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, nil, ErrInvalidCredentials
+			return nil, nil, s.loginFailed(ctx, attempt)
 		}
 		return nil, nil, err
 	}
 
 	if !user.Active {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, s.loginFailed(ctx, attempt)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, nil, ErrInvalidCredentials
+		return nil, nil, s.loginFailed(ctx, attempt)
+	}
+	if err := s.releaseLoginAttempt(ctx, attempt); err != nil {
+		return nil, nil, err
 	}
 
-	tokenPair, err := s.jwt.GenerateTokens(user.ID.String(), user.TenantID.String(), user.Role)
+	challenge, err := s.mfaChallenge(s.dbWithContext(ctx), &user)
 	if err != nil {
 		return nil, nil, err
 	}
+	if challenge != nil {
+		return nil, nil, challenge
+	}
 
-	now := time.Now()
-	_ = s.dbWithContext(ctx).Model(&user).Update("last_login_at", now).Error
-
+	tokenPair, err := s.completeLogin(ctx, &user)
+	if err != nil {
+		return nil, nil, err
+	}
 	return &user, tokenPair, nil
 }
 
-// RefreshTokens valida o refresh token e devolve novos tokens.
-func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
-	claims, err := s.jwt.ValidateRefreshToken(refreshToken)
-	if err != nil {
-		return nil, ErrInvalidCredentials
+// loginFailed confirma a falha da tentativa e devolve o erro de credenciais inválidas.
+func (s *Service) loginFailed(ctx context.Context, attempt *loginAttempt) error {
+	if err := s.failLoginAttempt(ctx, attempt); err != nil {
+		return err
 	}
+	return ErrInvalidCredentials
+}
 
-	var user domain.User
-	if err := s.dbWithContext(ctx).
-		Where("id = ?", claims.UserID).
-		First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, ErrInvalidCredentials
-		}
+// completeLogin abre a sessão depois de todos os fatores conferidos e zera as falhas
+// do e-mail. Só aqui, e não após a senha, para não liberar tentativas do segundo fator.
+func (s *Service) completeLogin(ctx context.Context, user *domain.User) (*auth.TokenPair, error) {
+	tokenPair, err := s.startSession(ctx, s.dbWithContext(ctx), user)
+	if err != nil {
 		return nil, err
 	}
-	if !user.Active {
-		return nil, ErrInvalidCredentials
-	}
-
-	tokenPair, err := s.jwt.GenerateTokens(user.ID.String(), user.TenantID.String(), user.Role)
-	if err != nil {
+	if err := s.resetLoginFailures(s.dbWithContext(ctx), user.Email); err != nil {
 		return nil, err
 	}
+
+	now := time.Now()
+	_ = s.dbWithContext(ctx).Model(user).Update("last_login_at", now).Error
+
 	return tokenPair, nil
 }
@@ -114,27 +114,158 @@ func TestLoginHandlesCredentialScenarios(t *testing.T) {
 	})
 }
 
-func TestRefreshTokensGeneratesNewPair(t *testing.T) {
-	clearAllData()
-	svc := newAuthTestService(t)
-	tenant, _ := createTestTenant()
-
+func seedAuthUser(t *testing.T, svc *Service, tenantID uuid.UUID, email string) (*domain.User, string) {
+	t.Helper()
+	password := testutil.RandomPassword()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), svc.cfg.BcryptCost)
+	require.NoError(t, err)
 	user := &domain.User{
-		TenantModel:  domain.TenantModel{TenantID: tenant.ID},
+		TenantModel:  domain.TenantModel{TenantID: tenantID},
 		Name:         "Refresh User",
-		Email:        "refresh@example.com",
-		PasswordHash: "hash",
+		Email:        email,
+		PasswordHash: string(hash),
 		Role:         "member",
 		Active:       true,
 	}
 	require.NoError(t, testDB.Create(user).Error)
+	return user, password
+}
 
-	refreshToken, err := svc.jwt.GenerateRefreshToken(user.ID.String(), tenant.ID.String(), user.Role)
+func TestRefreshTokensGeneratesNewPair(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "refresh@example.com")
+
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "10.0.0.1", UserAgent: "test-agent"})
+	_, login, err := svc.Login(ctx, user.Email, password)
 	require.NoError(t, err)
 
-	tokens, err := svc.RefreshTokens(context.Background(), refreshToken)
+	tokens, err := svc.RefreshTokens(context.Background(), login.RefreshToken)
 	require.NoError(t, err)
 	require.NotNil(t, tokens)
 	assert.NotEmpty(t, tokens.AccessToken)
 	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEqual(t, login.RefreshToken, tokens.RefreshToken)
+
+	var session domain.UserSession
+	require.NoError(t, testDB.First(&session, "user_id = ?", user.ID).Error)
+	assert.Equal(t, "10.0.0.1", session.IPAddress)
+	assert.Equal(t, "test-agent", session.UserAgent)
+	assert.Nil(t, session.RevokedAt)
+
+	var stored []domain.RefreshToken
+	require.NoError(t, testDB.Where("session_id = ?", session.ID).Order("created_at").Find(&stored).Error)
+	require.Len(t, stored, 2)
+	assert.NotNil(t, stored[0].UsedAt)
+	assert.Nil(t, stored[1].UsedAt)
+	for _, token := range stored {
+		assert.NotEqual(t, login.RefreshToken, token.TokenHash, "apenas o hash é persistido")
+	}
+
+	claims, err := svc.jwt.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID.String(), claims.SessionID)
+}
+
+func TestRefreshTokensRejectsUnknownToken(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+
+	_, err := svc.RefreshTokens(context.Background(), "nao-existe")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "reuse@example.com")
+
+	_, login, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	rotated, err := svc.RefreshTokens(context.Background(), login.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = svc.RefreshTokens(context.Background(), login.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = svc.RefreshTokens(context.Background(), rotated.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "o token legítimo mais recente também é invalidado")
+
+	var session domain.UserSession
+	require.NoError(t, testDB.First(&session, "user_id = ?", user.ID).Error)
+	require.NotNil(t, session.RevokedAt)
+	assert.Equal(t, domain.SessionRevokedReuse, session.RevokedReason)
+
+	var logs []domain.AuditLog
+	require.NoError(t, testDB.Where("entity = ? AND entity_id = ?", AuditEntitySession, session.ID).Find(&logs).Error)
+	assert.Len(t, logs, 1)
+}
+
+func TestRefreshTokensRejectsInactiveUser(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "inactive@example.com")
+
+	_, login, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	require.NoError(t, testDB.Model(user).Update("active", false).Error)
+
+	_, err = svc.RefreshTokens(context.Background(), login.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "logout@example.com")
+
+	_, first, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	_, second, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Logout(context.Background(), first.RefreshToken))
+	require.NoError(t, svc.Logout(context.Background(), first.RefreshToken), "logout é idempotente")
+	require.NoError(t, svc.Logout(context.Background(), "desconhecido"))
+
+	_, err = svc.RefreshTokens(context.Background(), first.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = svc.RefreshTokens(context.Background(), second.RefreshToken)
+	assert.NoError(t, err, "outras sessões continuam válidas")
+}
+
+func TestLogoutAllRevokesEverySession(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "all@example.com")
+	other, otherPassword := seedAuthUser(t, svc, tenant.ID, "other@example.com")
+
+	var refreshTokens []string
+	for i := 0; i < 2; i++ {
+		_, tokens, err := svc.Login(context.Background(), user.Email, password)
+		require.NoError(t, err)
+		refreshTokens = append(refreshTokens, tokens.RefreshToken)
+	}
+	_, otherTokens, err := svc.Login(context.Background(), other.Email, otherPassword)
+	require.NoError(t, err)
+
+	revoked, err := svc.LogoutAll(context.Background(), tenant.ID, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+
+	for _, token := range refreshTokens {
+		_, err := svc.RefreshTokens(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	_, err = svc.RefreshTokens(context.Background(), otherTokens.RefreshToken)
+	assert.NoError(t, err)
+
+	revoked, err = svc.LogoutAll(context.Background(), tenant.ID, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, revoked)
 }
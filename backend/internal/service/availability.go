@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+var ErrOutsideAvailability = errors.New("horário fora da disponibilidade do profissional")
+
+// checkAvailability garante que o intervalo caiba em uma das janelas semanais do
+// profissional, avaliadas no fuso horário da empresa, ou em uma abertura avulsa.
+// Bloqueios (folgas, feriados) sempre prevalecem. Profissionais sem regras
+// cadastradas só são restringidos pelos bloqueios.
+func (s *Service) checkAvailability(ctx context.Context, tenantID, professionalID uuid.UUID, start, end time.Time) error {
+	db := s.dbWithContext(ctx)
+	exceptions, err := availabilityExceptions(db, tenantID, professionalID, start, end)
+	if err != nil {
+		return err
+	}
+	if blockedBy(exceptions, start, end) {
+		return ErrOutsideAvailability
+	}
+
+	var rules []domain.AvailabilityRule
+	if err := db.
+		Where("tenant_id = ? AND professional_id = ?", tenantID, professionalID).
+		Find(&rules).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 || openedBy(exceptions, start, end) {
+		return nil
+	}
+
+	loc, err := s.companyLocation(db, tenantID)
+	if err != nil {
+		return err
+	}
+	if !withinAvailability(rules, start.In(loc), end.In(loc)) {
+		return ErrOutsideAvailability
+	}
+	return nil
+}
+
+// companyLocation carrega o fuso da empresa; valores vazios ou inválidos caem em UTC.
+func (s *Service) companyLocation(db *gorm.DB, tenantID uuid.UUID) (*time.Location, error) {
+	var company domain.Company
+	if err := db.Select("id", "timezone").First(&company, "id = ?", tenantID).Error; err != nil {
+		return nil, err
+	}
+	return companyTimezone(&company), nil
+}
+
+// withinAvailability verifica se [start, end) cabe inteiramente nas regras do dia. Regras
+// que se tocam ou se sobrepõem (08:00-12:00 e 12:00-18:00) contam como uma só janela,
+// como na busca de horários. Os horários já devem estar no fuso da empresa.
+func withinAvailability(rules []domain.AvailabilityRule, start, end time.Time) bool {
+	for _, window := range mergeWindows(availabilityWindows(rules, start, end)) {
+		if !start.Before(window.start) && !end.After(window.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock converte "HH:MM" ou "HH:MM:SS" em deslocamento desde a meia-noite.
+func parseClock(value string) (time.Duration, error) {
+	var h, m, sec int
+	if _, err := fmt.Sscanf(value, "%d:%d:%d", &h, &m, &sec); err != nil {
+		sec = 0
+		if _, err := fmt.Sscanf(value, "%d:%d", &h, &m); err != nil {
+			return 0, fmt.Errorf("horário inválido %q", value)
+		}
+	}
+	if h < 0 || h > 24 || m < 0 || m > 59 || sec < 0 || sec > 59 {
+		return 0, fmt.Errorf("horário inválido %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec)*time.Second, nil
+}
+
+func clockOffset(t time.Time) time.Duration {
+	return t.Sub(startOfDay(t))
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
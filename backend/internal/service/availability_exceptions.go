@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+var ErrInvalidAvailabilityException = errors.New("exceção de disponibilidade inválida")
+
+// AvailabilityExceptionInput payload de criação de folgas, feriados e aberturas avulsas.
+type AvailabilityExceptionInput struct {
+	// ProfessionalID vazio aplica a exceção a toda a empresa.
+	ProfessionalID *uuid.UUID
+	Kind           string
+	StartAt        time.Time
+	EndAt          time.Time
+	Reason         string
+}
+
+// AvailabilityExceptionFilter filtros da listagem de exceções.
+type AvailabilityExceptionFilter struct {
+	ProfessionalID *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+}
+
+// AvailabilityExceptionResult exceção criada e agendamentos ativos que passaram a conflitar com ela.
+type AvailabilityExceptionResult struct {
+	Exception domain.AvailabilityException `json:"exception"`
+	Conflicts []domain.Booking             `json:"conflicts"`
+}
+
+// ListAvailabilityExceptions lista exceções da empresa; filtrar por profissional inclui as exceções gerais.
+func (s *Service) ListAvailabilityExceptions(ctx context.Context, tenantID uuid.UUID, filter AvailabilityExceptionFilter) ([]domain.AvailabilityException, error) {
+	query := s.dbWithContext(ctx).Where("tenant_id = ?", tenantID)
+	if filter.ProfessionalID != nil {
+		query = query.Where("professional_id = ? OR professional_id IS NULL", *filter.ProfessionalID)
+	}
+	if filter.From != nil {
+		query = query.Where("end_at > ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_at < ?", *filter.To)
+	}
+
+	var exceptions []domain.AvailabilityException
+	if err := query.Order("start_at ASC").Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	return exceptions, nil
+}
+
+// CreateAvailabilityException grava a exceção. Bloqueios não cancelam agendamentos existentes;
+// os que caem no intervalo são devolvidos para que sejam remarcados.
+func (s *Service) CreateAvailabilityException(ctx context.Context, tenantID uuid.UUID, input AvailabilityExceptionInput) (*AvailabilityExceptionResult, error) {
+	if input.Kind != domain.AvailabilityExceptionBlocked && input.Kind != domain.AvailabilityExceptionOpen {
+		return nil, ErrInvalidAvailabilityException
+	}
+	if !input.EndAt.After(input.StartAt) {
+		return nil, ErrInvalidAvailabilityException
+	}
+	if input.ProfessionalID != nil {
+		if err := s.ensureTenantRecord(ctx, &domain.Professional{}, tenantID, *input.ProfessionalID); err != nil {
+			return nil, err
+		}
+	}
+
+	exception := domain.AvailabilityException{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		ProfessionalID: input.ProfessionalID,
+		Kind:           input.Kind,
+		StartAt:        input.StartAt.UTC(),
+		EndAt:          input.EndAt.UTC(),
+		Reason:         input.Reason,
+	}
+	result := &AvailabilityExceptionResult{Conflicts: []domain.Booking{}}
+
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(&exception).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityAvailabilityException, AuditActionCreate, exception.ID, nil, exception); err != nil {
+			return err
+		}
+		if exception.Kind != domain.AvailabilityExceptionBlocked {
+			return nil
+		}
+
+		query := tx.
+			Where("tenant_id = ? AND status NOT IN ?", tenantID, bookingInactiveStatuses).
+			Where("start_at < ? AND end_at > ?", exception.EndAt, exception.StartAt)
+		if exception.ProfessionalID != nil {
+			query = query.Where("professional_id = ?", *exception.ProfessionalID)
+		}
+		return query.Order("start_at ASC").Find(&result.Conflicts).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Exception = exception
+	return result, nil
+}
+
+func (s *Service) DeleteAvailabilityException(ctx context.Context, tenantID, exceptionID uuid.UUID) error {
+	if err := s.ensureTenantRecord(ctx, &domain.AvailabilityException{}, tenantID, exceptionID); err != nil {
+		return err
+	}
+	return s.deleteTenantRecord(ctx, tenantID, exceptionID, &domain.AvailabilityException{}, AuditEntityAvailabilityException)
+}
+
+// availabilityExceptions carrega as exceções do profissional e as gerais da empresa que tocam [from, to).
+func availabilityExceptions(db *gorm.DB, tenantID, professionalID uuid.UUID, from, to time.Time) ([]domain.AvailabilityException, error) {
+	var exceptions []domain.AvailabilityException
+	if err := db.
+		Where("tenant_id = ? AND (professional_id = ? OR professional_id IS NULL)", tenantID, professionalID).
+		Where("start_at < ? AND end_at > ?", to, from).
+		Order("start_at ASC").
+		Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	return exceptions, nil
+}
+
+// blockedBy indica se [start, end) toca algum bloqueio.
+func blockedBy(exceptions []domain.AvailabilityException, start, end time.Time) bool {
+	for _, exception := range exceptions {
+		if exception.Kind == domain.AvailabilityExceptionBlocked &&
+			exception.StartAt.Before(end) && exception.EndAt.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// openedBy indica se [start, end) cabe inteiramente em alguma abertura avulsa.
+func openedBy(exceptions []domain.AvailabilityException, start, end time.Time) bool {
+	for _, exception := range exceptions {
+		if exception.Kind == domain.AvailabilityExceptionOpen &&
+			!start.Before(exception.StartAt) && !end.After(exception.EndAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// openingWindows converte as aberturas avulsas em janelas concretas no fuso informado.
+func openingWindows(exceptions []domain.AvailabilityException, loc *time.Location) []timeWindow {
+	var windows []timeWindow
+	for _, exception := range exceptions {
+		if exception.Kind != domain.AvailabilityExceptionOpen {
+			continue
+		}
+		windows = append(windows, timeWindow{start: exception.StartAt.In(loc), end: exception.EndAt.In(loc)})
+	}
+	return windows
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestBlockedAndOpenedBy(t *testing.T) {
+	base := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	exceptions := []domain.AvailabilityException{
+		{Kind: domain.AvailabilityExceptionBlocked, StartAt: base.Add(12 * time.Hour), EndAt: base.Add(13 * time.Hour)},
+		{Kind: domain.AvailabilityExceptionOpen, StartAt: base.Add(9 * time.Hour), EndAt: base.Add(14 * time.Hour)},
+	}
+
+	assert.True(t, blockedBy(exceptions, base.Add(11*time.Hour+30*time.Minute), base.Add(12*time.Hour+30*time.Minute)))
+	assert.False(t, blockedBy(exceptions, base.Add(11*time.Hour), base.Add(12*time.Hour)))
+	assert.True(t, openedBy(exceptions, base.Add(9*time.Hour), base.Add(10*time.Hour)))
+	assert.False(t, openedBy(exceptions, base.Add(13*time.Hour+30*time.Minute), base.Add(14*time.Hour+30*time.Minute)))
+}
+
+func TestAvailabilityExceptionsAffectBookingsAndSlots(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Exception Client", "exception@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Exception")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+	seedAvailabilityRule(t, tenant.ID, pro.ID, int(time.Monday), "09:00", "18:00")
+
+	// 2026-03-02 é segunda-feira; 2026-03-07 é sábado, sem regra semanal.
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        monday.Add(10 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	holiday, err := testSvc.CreateAvailabilityException(ctx, tenant.ID, AvailabilityExceptionInput{
+		Kind:    domain.AvailabilityExceptionBlocked,
+		StartAt: monday,
+		EndAt:   monday.AddDate(0, 0, 1),
+		Reason:  "Feriado",
+	})
+	require.NoError(t, err)
+	require.Len(t, holiday.Conflicts, 1)
+	assert.Equal(t, booking.ID, holiday.Conflicts[0].ID)
+
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        monday.Add(14 * time.Hour),
+	})
+	require.ErrorIs(t, err, ErrOutsideAvailability)
+
+	saturday := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	_, err = testSvc.CreateAvailabilityException(ctx, tenant.ID, AvailabilityExceptionInput{
+		ProfessionalID: &pro.ID,
+		Kind:           domain.AvailabilityExceptionOpen,
+		StartAt:        saturday.Add(9 * time.Hour),
+		EndAt:          saturday.Add(12 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        saturday.Add(9 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	from := monday
+	to := saturday.AddDate(0, 0, 1)
+	slots, err := testSvc.ListAvailableSlots(ctx, tenant.ID, SlotQuery{
+		ServiceID:      service.ID,
+		ProfessionalID: &pro.ID,
+		From:           &from,
+		To:             &to,
+		Granularity:    60,
+	})
+	require.NoError(t, err)
+	starts := make([]time.Time, len(slots))
+	for i, slot := range slots {
+		starts[i] = slot.StartAt
+	}
+	assert.Equal(t, []time.Time{saturday.Add(10 * time.Hour), saturday.Add(11 * time.Hour)}, starts)
+
+	_, err = testSvc.CreateAvailabilityException(ctx, tenant.ID, AvailabilityExceptionInput{
+		Kind:    domain.AvailabilityExceptionBlocked,
+		StartAt: saturday,
+		EndAt:   saturday,
+	})
+	require.ErrorIs(t, err, ErrInvalidAvailabilityException)
+}
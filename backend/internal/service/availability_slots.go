@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const (
+	defaultSlotGranularity = 15 * time.Minute
+	defaultSlotRange       = 7 * 24 * time.Hour
+	maxSlotRange           = 31 * 24 * time.Hour
+)
+
+var ErrInvalidSlotQuery = errors.New("parâmetros de busca de horários inválidos")
+
+// SlotQuery parâmetros da busca de horários livres.
+type SlotQuery struct {
+	ServiceID      uuid.UUID
+	ProfessionalID *uuid.UUID
+	From           *time.Time
+	To             *time.Time
+	// Granularity em minutos; zero usa Company.Settings ou o padrão de 15 minutos.
+	Granularity int
+}
+
+// AvailableSlot horário livre para um profissional.
+type AvailableSlot struct {
+	ProfessionalID   uuid.UUID `json:"professional_id"`
+	ProfessionalName string    `json:"professional_name"`
+	StartAt          time.Time `json:"start_at"`
+	EndAt            time.Time `json:"end_at"`
+}
+
+// ListAvailableSlots combina regras de disponibilidade, exceções (bloqueios e aberturas
+// avulsas), duração do serviço, agendamentos ativos, reservas da lista de espera e
+// MaxParallel para sugerir horários livres. Sem profissional informado, busca entre
+// todos os profissionais ativos aptos ao serviço.
+func (s *Service) ListAvailableSlots(ctx context.Context, tenantID uuid.UUID, query SlotQuery) ([]AvailableSlot, error) {
+	db := s.dbWithContext(ctx)
+
+	var service domain.Service
+	if err := db.First(&service, "tenant_id = ? AND id = ?", tenantID, query.ServiceID).Error; err != nil {
+		return nil, err
+	}
+	if service.DurationMinutes <= 0 {
+		return nil, ErrInvalidSlotQuery
+	}
+	duration := time.Duration(service.DurationMinutes) * time.Minute
+
+	from := time.Now().UTC()
+	if query.From != nil {
+		from = *query.From
+	}
+	to := from.Add(defaultSlotRange)
+	if query.To != nil {
+		to = *query.To
+	}
+	if query.Granularity < 0 || !to.After(from) || to.Sub(from) > maxSlotRange {
+		return nil, ErrInvalidSlotQuery
+	}
+
+	settings, err := s.companySettings(db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	granularity := time.Duration(query.Granularity) * time.Minute
+	if granularity == 0 {
+		granularity = time.Duration(settingInt(settings, domain.SettingSlotGranularity)) * time.Minute
+	}
+	if granularity <= 0 {
+		granularity = defaultSlotGranularity
+	}
+
+	loc, err := s.companyLocation(db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	proQuery := db.Preload("Availability").Where("tenant_id = ? AND active = true", tenantID)
+	if query.ProfessionalID != nil {
+		proQuery = proQuery.Where("id = ?", *query.ProfessionalID)
+	}
+	var professionals []domain.Professional
+	if err := proQuery.Order("name ASC").Find(&professionals).Error; err != nil {
+		return nil, err
+	}
+
+	slots := []AvailableSlot{}
+	for _, pro := range professionals {
+		if query.ProfessionalID == nil && !professionalCanPerform(pro, service) {
+			continue
+		}
+
+		bookings, err := professionalOccupancy(db, tenantID, pro.ID, from, to.Add(duration), nil)
+		if err != nil {
+			return nil, err
+		}
+		holds, err := activeHolds(db, tenantID, pro.ID, from, to.Add(duration), time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, holds...)
+
+		exceptions, err := availabilityExceptions(db, tenantID, pro.ID, from, to.Add(duration))
+		if err != nil {
+			return nil, err
+		}
+		rules := pro.Availability
+		if len(rules) == 0 {
+			rules = fullDayAvailability
+		}
+		windows := mergeWindows(availabilityWindows(rules, from.In(loc), to.In(loc)))
+		windows = append(windows, openingWindows(exceptions, loc)...)
+
+		capacity := professionalCapacity(pro)
+		seen := map[time.Time]bool{}
+		for _, window := range windows {
+			for start := window.start; !start.Add(duration).After(window.end); start = start.Add(granularity) {
+				end := start.Add(duration)
+				if start.Before(from) || !start.Before(to) || seen[start.UTC()] {
+					continue
+				}
+				if blockedBy(exceptions, start, end) {
+					continue
+				}
+				if maxConcurrentBookings(bookings, start, end) >= capacity {
+					continue
+				}
+				seen[start.UTC()] = true
+				slots = append(slots, AvailableSlot{
+					ProfessionalID:   pro.ID,
+					ProfessionalName: pro.Name,
+					StartAt:          start.UTC(),
+					EndAt:            end.UTC(),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].StartAt.Before(slots[j].StartAt)
+	})
+	return slots, nil
+}
+
+// fullDayAvailability substitui as regras de profissionais sem nenhuma cadastrada, que
+// checkAvailability trata como disponíveis a qualquer hora.
+var fullDayAvailability = func() []domain.AvailabilityRule {
+	rules := make([]domain.AvailabilityRule, 7)
+	for weekday := range rules {
+		rules[weekday] = domain.AvailabilityRule{Weekday: weekday, StartTime: "00:00", EndTime: "24:00"}
+	}
+	return rules
+}()
+
+type timeWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// availabilityWindows expande as regras semanais em janelas concretas entre from e to,
+// já no fuso dos parâmetros.
+func availabilityWindows(rules []domain.AvailabilityRule, from, to time.Time) []timeWindow {
+	var windows []timeWindow
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		for _, rule := range rules {
+			if rule.Weekday != int(day.Weekday()) {
+				continue
+			}
+			ruleStart, err := parseClock(rule.StartTime)
+			if err != nil {
+				continue
+			}
+			ruleEnd, err := parseClock(rule.EndTime)
+			if err != nil || ruleEnd <= ruleStart {
+				continue
+			}
+			windows = append(windows, timeWindow{start: atClock(day, ruleStart), end: atClock(day, ruleEnd)})
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+	return windows
+}
+
+// mergeWindows junta janelas ordenadas que se tocam ou se sobrepõem.
+func mergeWindows(windows []timeWindow) []timeWindow {
+	merged := make([]timeWindow, 0, len(windows))
+	for _, window := range windows {
+		if n := len(merged); n > 0 && !window.start.After(merged[n-1].end) {
+			if window.end.After(merged[n-1].end) {
+				merged[n-1].end = window.end
+			}
+			continue
+		}
+		merged = append(merged, window)
+	}
+	return merged
+}
+
+// atClock posiciona o deslocamento no relógio local do dia, respeitando horário de verão.
+func atClock(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, int(offset/time.Second), 0, day.Location())
+}
+
+// professionalCanPerform considera generalistas os profissionais sem especialidades;
+// caso contrário exige que alguma especialidade cite o ID, o nome ou a categoria do serviço.
+func professionalCanPerform(pro domain.Professional, service domain.Service) bool {
+	var specialties []string
+	if len(pro.Specialties) > 0 {
+		if err := json.Unmarshal(pro.Specialties, &specialties); err != nil {
+			return false
+		}
+	}
+	if len(specialties) == 0 {
+		return true
+	}
+	for _, specialty := range specialties {
+		specialty = strings.TrimSpace(specialty)
+		if specialty == service.ID.String() ||
+			strings.EqualFold(specialty, service.Name) ||
+			(service.Category != "" && strings.EqualFold(specialty, service.Category)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestProfessionalCanPerform(t *testing.T) {
+	service := domain.Service{Name: "Corte", Category: "Cabelo"}
+
+	tests := []struct {
+		name        string
+		specialties string
+		want        bool
+	}{
+		{"generalist", ``, true},
+		{"empty list", `[]`, true},
+		{"by name", `["corte"]`, true},
+		{"by category", `["Cabelo", "Unhas"]`, true},
+		{"other specialty", `["Unhas"]`, false},
+	}
+
+	for _, tt := range tests {
+		pro := domain.Professional{Specialties: datatypes.JSON(tt.specialties)}
+		if got := professionalCanPerform(pro, service); got != tt.want {
+			t.Fatalf("%s: professionalCanPerform = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestListAvailableSlotsHonorsBookingsAndCapacity(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	require.NoError(t, testDB.Model(&domain.Company{}).Where("id = ?", tenant.ID).Update("timezone", "UTC").Error)
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Slots Client", "slots@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+	ana := seedProfessionalRecord(t, tenant.ID, "Ana")
+	bia := seedProfessionalRecord(t, tenant.ID, "Bia")
+	require.NoError(t, testDB.Model(bia).Update("max_parallel", 2).Error)
+
+	// 2026-03-02 é segunda-feira.
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	seedAvailabilityRule(t, tenant.ID, ana.ID, int(time.Monday), "09:00", "12:00")
+	seedAvailabilityRule(t, tenant.ID, bia.ID, int(time.Monday), "09:00", "11:00")
+
+	for _, pro := range []*domain.Professional{ana, bia} {
+		_, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+			ClientID:       client.ID,
+			ProfessionalID: pro.ID,
+			ServiceID:      service.ID,
+			StartAt:        monday.Add(9 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	from := monday
+	to := monday.Add(24 * time.Hour)
+	anaID := ana.ID
+	slots, err := testSvc.ListAvailableSlots(ctx, tenant.ID, SlotQuery{
+		ServiceID:      service.ID,
+		ProfessionalID: &anaID,
+		From:           &from,
+		To:             &to,
+		Granularity:    60,
+	})
+	require.NoError(t, err)
+	require.Len(t, slots, 2)
+	assert.Equal(t, monday.Add(10*time.Hour), slots[0].StartAt)
+	assert.Equal(t, monday.Add(11*time.Hour), slots[1].StartAt)
+
+	all, err := testSvc.ListAvailableSlots(ctx, tenant.ID, SlotQuery{
+		ServiceID:   service.ID,
+		From:        &from,
+		To:          &to,
+		Granularity: 60,
+	})
+	require.NoError(t, err)
+	// Ana: 10h e 11h. Bia (capacidade 2): 9h e 10h.
+	require.Len(t, all, 4)
+	assert.Equal(t, bia.ID, all[0].ProfessionalID)
+	assert.Equal(t, monday.Add(9*time.Hour), all[0].StartAt)
+}
+
+func TestProfessionalWithoutRulesMatchesBookingCheck(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	require.NoError(t, testDB.Model(&domain.Company{}).Where("id = ?", tenant.ID).Update("timezone", "UTC").Error)
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Sem Regras", "no-rules@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+	pro := seedProfessionalRecord(t, tenant.ID, "Livre")
+
+	// Sem regras, a busca oferece o dia inteiro, assim como a criação aceita qualquer horário.
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	from, to := monday, monday.Add(24*time.Hour)
+	proID := pro.ID
+	slots, err := testSvc.ListAvailableSlots(ctx, tenant.ID, SlotQuery{
+		ServiceID:      service.ID,
+		ProfessionalID: &proID,
+		From:           &from,
+		To:             &to,
+		Granularity:    60,
+	})
+	require.NoError(t, err)
+	require.Len(t, slots, 24)
+	assert.Equal(t, monday, slots[0].StartAt)
+
+	for _, slot := range []AvailableSlot{slots[0], slots[len(slots)-1]} {
+		require.NoError(t, testSvc.checkAvailability(ctx, tenant.ID, pro.ID, slot.StartAt, slot.EndAt))
+		_, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+			ClientID:       client.ID,
+			ProfessionalID: pro.ID,
+			ServiceID:      service.ID,
+			StartAt:        slot.StartAt,
+		})
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestWithinAvailability(t *testing.T) {
+	rules := []domain.AvailabilityRule{
+		{Weekday: int(time.Monday), StartTime: "09:00", EndTime: "12:00"},
+		{Weekday: int(time.Monday), StartTime: "14:00:00", EndTime: "18:00:00"},
+		{Weekday: int(time.Tuesday), StartTime: "08:00", EndTime: "12:00"},
+		{Weekday: int(time.Tuesday), StartTime: "12:00", EndTime: "18:00"},
+	}
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"inside morning", monday.Add(9 * time.Hour), monday.Add(10 * time.Hour), true},
+		{"ends at close", monday.Add(11 * time.Hour), monday.Add(12 * time.Hour), true},
+		{"crosses lunch", monday.Add(11*time.Hour + 30*time.Minute), monday.Add(14*time.Hour + 30*time.Minute), false},
+		{"before open", monday.Add(8*time.Hour + 30*time.Minute), monday.Add(9*time.Hour + 30*time.Minute), false},
+		{"other weekday", monday.Add(57 * time.Hour), monday.Add(58 * time.Hour), false},
+		{"spans back-to-back rules", monday.Add(35 * time.Hour), monday.Add(37 * time.Hour), true},
+		{"after merged close", monday.Add(41 * time.Hour), monday.Add(43 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		if got := withinAvailability(rules, tt.start, tt.end); got != tt.want {
+			t.Fatalf("%s: withinAvailability = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCreateBookingRespectsAvailabilityInCompanyTimezone(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	require.NoError(t, testDB.Model(&domain.Company{}).Where("id = ?", tenant.ID).Update("timezone", "America/Sao_Paulo").Error)
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Availability Client", "availability@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Availability")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+	seedAvailabilityRule(t, tenant.ID, pro.ID, int(time.Monday), "09:00", "18:00")
+
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	// 2026-03-02 é segunda-feira; 10h em São Paulo equivale a 13h UTC.
+	inside := time.Date(2026, 3, 2, 10, 0, 0, 0, loc).UTC()
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        inside,
+	})
+	require.NoError(t, err)
+
+	// 7h em São Paulo (10h UTC) estaria dentro da regra se avaliada em UTC.
+	outside := time.Date(2026, 3, 2, 7, 0, 0, 0, loc).UTC()
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        outside,
+	})
+	require.ErrorIs(t, err, ErrOutsideAvailability)
+
+	overridden, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:             client.ID,
+		ProfessionalID:       pro.ID,
+		ServiceID:            service.ID,
+		StartAt:              outside,
+		OverrideAvailability: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, outside, overridden.StartAt.UTC())
+
+	moved := time.Date(2026, 3, 2, 16, 0, 0, 0, loc).UTC()
+	movedEnd := moved.Add(time.Hour)
+	_, err = testSvc.UpdateBooking(ctx, tenant.ID, booking.ID, BookingUpdateInput{StartAt: &moved, EndAt: &movedEnd})
+	require.NoError(t, err)
+
+	late := time.Date(2026, 3, 2, 17, 30, 0, 0, loc).UTC()
+	lateEnd := late.Add(time.Hour)
+	_, err = testSvc.UpdateBooking(ctx, tenant.ID, booking.ID, BookingUpdateInput{StartAt: &late, EndAt: &lateEnd})
+	require.ErrorIs(t, err, ErrOutsideAvailability)
+}
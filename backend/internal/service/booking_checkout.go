@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+var ErrBookingAlreadyCheckedOut = errors.New("agendamento já possui pedido de venda")
+
+// CheckoutProductInput produto adicional do checkout. Sem UnitPrice, usa o preço atual do produto.
+type CheckoutProductInput struct {
+	ProductID uuid.UUID
+	Quantity  int
+	UnitPrice *float64
+}
+
+// CheckoutInput dados opcionais do checkout de um agendamento.
+type CheckoutInput struct {
+	Products []CheckoutProductInput
+	Discount float64
+	Notes    string
+}
+
+// CheckoutBooking gera o pedido em rascunho para o cliente do agendamento, com os serviços
+// agendados ao preço atual e os produtos adicionais, e conclui o agendamento na mesma
+// transação. Um agendamento com pedido não cancelado não pode passar por novo checkout.
+func (s *Service) CheckoutBooking(ctx context.Context, tenantID, bookingID uuid.UUID, input CheckoutInput) (*domain.SalesOrder, error) {
+	items, err := s.bookingSalesItems(ctx, tenantID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range input.Products {
+		item, err := s.checkoutProductItem(ctx, tenantID, product)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	order := &domain.SalesOrder{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		BookingID: &bookingID,
+		Status:    domain.SalesOrderStatusDraft,
+		Discount:  input.Discount,
+		Notes:     input.Notes,
+	}
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var booking domain.Booking
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, bookingID).
+			First(&booking).Error; err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&domain.SalesOrder{}).
+			Where("tenant_id = ? AND booking_id = ? AND status <> ?", tenantID, bookingID, domain.SalesOrderStatusCanceled).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrBookingAlreadyCheckedOut
+		}
+
+		before := booking
+		if err := s.transitionBooking(tx, &booking, domain.BookingStatusDone, nil); err != nil {
+			return err
+		}
+		if err := s.auditBookingUpdate(tx, before); err != nil {
+			return err
+		}
+		order.ClientID = booking.ClientID
+		return s.createSalesOrderTx(tx, order, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dbWithContext(ctx).
+		Preload("Items").
+		First(order, "id = ?", order.ID).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (s *Service) checkoutProductItem(ctx context.Context, tenantID uuid.UUID, input CheckoutProductInput) (SalesItemInput, error) {
+	var product domain.Product
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, input.ProductID).
+		First(&product).Error; err != nil {
+		return SalesItemInput{}, err
+	}
+	price := product.Price
+	if input.UnitPrice != nil {
+		price = *input.UnitPrice
+	}
+	return SalesItemInput{
+		Type:      salesItemTypeProduct,
+		RefID:     product.ID,
+		Quantity:  input.Quantity,
+		UnitPrice: price,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestCheckoutBooking(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Checkout Client", "checkout@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Checkout")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 30)
+	product, err := testSvc.CreateProduct(ctx, tenant.ID, ProductInput{
+		Name:     "Pomada",
+		SKU:      "POM-001",
+		Price:    40,
+		StockQty: 10,
+	})
+	require.NoError(t, err)
+
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		Status:         domain.BookingStatusConfirmed,
+		StartAt:        time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// O preço atual do serviço prevalece sobre o vigente na criação do agendamento.
+	require.NoError(t, testDB.Model(&domain.Service{}).Where("id = ?", service.ID).Update("price", 120).Error)
+
+	order, err := testSvc.CheckoutBooking(ctx, tenant.ID, booking.ID, CheckoutInput{
+		Products: []CheckoutProductInput{{ProductID: product.ID, Quantity: 2}},
+		Discount: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.ID, order.ClientID)
+	assert.Equal(t, domain.SalesOrderStatusDraft, order.Status)
+	require.Len(t, order.Items, 2)
+	assert.InDelta(t, 190, order.Total, 0.001)
+
+	var done domain.Booking
+	require.NoError(t, testDB.First(&done, "id = ?", booking.ID).Error)
+	assert.Equal(t, domain.BookingStatusDone, done.Status)
+	assert.NotNil(t, done.CompletedAt)
+
+	_, err = testSvc.CheckoutBooking(ctx, tenant.ID, booking.ID, CheckoutInput{})
+	assert.ErrorIs(t, err, ErrBookingAlreadyCheckedOut)
+}
+
+func TestCheckoutBookingRequiresValidTransition(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Canceled Client", "canceled@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Canceled")
+	service := seedServiceRecord(t, tenant.ID, "Barba", 30)
+
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	_, err = testSvc.CancelBooking(ctx, tenant.ID, booking.ID, "")
+	require.NoError(t, err)
+
+	_, err = testSvc.CheckoutBooking(ctx, tenant.ID, booking.ID, CheckoutInput{})
+	var transitionErr *InvalidTransitionError
+	require.ErrorAs(t, err, &transitionErr)
+
+	var count int64
+	require.NoError(t, testDB.Model(&domain.SalesOrder{}).Where("booking_id = ?", booking.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
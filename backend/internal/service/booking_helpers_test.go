@@ -38,3 +38,16 @@ func ensureTable(t *testing.T, model interface{}) {
 	t.Helper()
 	require.NoError(t, testDB.AutoMigrate(model))
 }
+
+func seedAvailabilityRule(t *testing.T, tenantID, professionalID uuid.UUID, weekday int, start, end string) *domain.AvailabilityRule {
+	t.Helper()
+	rule := &domain.AvailabilityRule{
+		TenantModel:    domain.TenantModel{TenantID: tenantID},
+		ProfessionalID: professionalID,
+		Weekday:        weekday,
+		StartTime:      start,
+		EndTime:        end,
+	}
+	require.NoError(t, testDB.Create(rule).Error)
+	return rule
+}
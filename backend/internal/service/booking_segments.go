@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+var ErrInvalidBookingServices = errors.New("serviços do agendamento inválidos")
+
+// BookingServiceInput etapa de um agendamento com vários serviços. Sem profissional,
+// a etapa usa o profissional principal do agendamento.
+type BookingServiceInput struct {
+	ServiceID      uuid.UUID
+	ProfessionalID *uuid.UUID
+}
+
+// buildSegments encadeia as etapas a partir de start, na ordem informada, usando a
+// duração atual de cada serviço.
+func (s *Service) buildSegments(ctx context.Context, tenantID, defaultProfessionalID uuid.UUID, start time.Time, inputs []BookingServiceInput) ([]domain.BookingSegment, error) {
+	segments := make([]domain.BookingSegment, 0, len(inputs))
+	cursor := start
+	for i, input := range inputs {
+		professionalID := defaultProfessionalID
+		if input.ProfessionalID != nil {
+			professionalID = *input.ProfessionalID
+			if err := s.ensureTenantRecord(ctx, &domain.Professional{}, tenantID, professionalID); err != nil {
+				return nil, err
+			}
+		}
+
+		var service domain.Service
+		if err := s.dbWithContext(ctx).First(&service, "tenant_id = ? AND id = ?", tenantID, input.ServiceID).Error; err != nil {
+			return nil, err
+		}
+		if service.DurationMinutes <= 0 {
+			return nil, ErrInvalidBookingServices
+		}
+
+		end := cursor.Add(time.Duration(service.DurationMinutes) * time.Minute)
+		segments = append(segments, domain.BookingSegment{
+			TenantModel: domain.TenantModel{
+				TenantID: tenantID,
+			},
+			Position:       i,
+			ServiceID:      service.ID,
+			ProfessionalID: professionalID,
+			StartAt:        cursor,
+			EndAt:          end,
+		})
+		cursor = end
+	}
+	return segments, nil
+}
+
+// checkBookingSchedule valida cada etapa do agendamento (ou o agendamento inteiro, quando
+// simples) contra a agenda do respectivo profissional. Os locks são obtidos em ordem
+// fixa para evitar deadlocks entre agendamentos que envolvem os mesmos profissionais.
+func (s *Service) checkBookingSchedule(ctx context.Context, tx *gorm.DB, booking *domain.Booking, ignoreID *uuid.UUID, override bool) error {
+	if len(booking.Segments) == 0 {
+		return s.checkSchedule(ctx, tx, booking.TenantID, booking.ProfessionalID, booking.StartAt, booking.EndAt, ignoreID, override)
+	}
+
+	professionals := make([]uuid.UUID, 0, len(booking.Segments))
+	for _, segment := range booking.Segments {
+		professionals = append(professionals, segment.ProfessionalID)
+	}
+	sort.Slice(professionals, func(i, j int) bool { return professionals[i].String() < professionals[j].String() })
+	for _, professionalID := range professionals {
+		if err := lockProfessionalSchedule(tx, booking.TenantID, professionalID); err != nil {
+			return err
+		}
+	}
+
+	for _, segment := range booking.Segments {
+		if err := s.checkSchedule(ctx, tx, booking.TenantID, segment.ProfessionalID, segment.StartAt, segment.EndAt, ignoreID, override); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// professionalOccupancy retorna os intervalos ocupados do profissional: agendamentos simples
+// e etapas de agendamentos com vários serviços, ignorando os inativos.
+func professionalOccupancy(db *gorm.DB, tenantID, professionalID uuid.UUID, start, end time.Time, ignoreID *uuid.UUID) ([]domain.Booking, error) {
+	query := db.
+		Model(&domain.Booking{}).
+		Where("tenant_id = ? AND professional_id = ? AND status NOT IN ?", tenantID, professionalID, bookingInactiveStatuses).
+		Where("start_at < ? AND end_at > ?", end, start).
+		Where("NOT EXISTS (SELECT 1 FROM booking_segments bs WHERE bs.booking_id = bookings.id AND bs.deleted_at IS NULL)")
+	if ignoreID != nil {
+		query = query.Where("id <> ?", *ignoreID)
+	}
+	var occupied []domain.Booking
+	if err := query.Find(&occupied).Error; err != nil {
+		return nil, err
+	}
+
+	segmentQuery := db.
+		Model(&domain.BookingSegment{}).
+		Select("booking_segments.*").
+		Joins("JOIN bookings ON bookings.id = booking_segments.booking_id AND bookings.deleted_at IS NULL").
+		Where("booking_segments.tenant_id = ? AND booking_segments.professional_id = ?", tenantID, professionalID).
+		Where("bookings.status NOT IN ?", bookingInactiveStatuses).
+		Where("booking_segments.start_at < ? AND booking_segments.end_at > ?", end, start)
+	if ignoreID != nil {
+		segmentQuery = segmentQuery.Where("booking_segments.booking_id <> ?", *ignoreID)
+	}
+	var segments []domain.BookingSegment
+	if err := segmentQuery.Find(&segments).Error; err != nil {
+		return nil, err
+	}
+	for _, segment := range segments {
+		occupied = append(occupied, domain.Booking{StartAt: segment.StartAt, EndAt: segment.EndAt})
+	}
+	return occupied, nil
+}
+
+// rescheduledBooking aplica os novos horários a uma cópia do agendamento. Em agendamentos
+// com vários serviços o fim é derivado das etapas, que são deslocadas junto do início.
+func rescheduledBooking(booking domain.Booking, startAt, endAt *time.Time) domain.Booking {
+	moved := booking
+	if startAt != nil {
+		moved.StartAt = *startAt
+	}
+	if endAt != nil {
+		moved.EndAt = *endAt
+	}
+	if len(booking.Segments) > 0 {
+		shift := moved.StartAt.Sub(booking.StartAt)
+		moved.EndAt = booking.EndAt.Add(shift)
+		moved.Segments = shiftSegments(booking.Segments, shift)
+	}
+	return moved
+}
+
+func shiftSegments(segments []domain.BookingSegment, shift time.Duration) []domain.BookingSegment {
+	if len(segments) == 0 {
+		return nil
+	}
+	shifted := make([]domain.BookingSegment, len(segments))
+	for i, segment := range segments {
+		segment.StartAt = segment.StartAt.Add(shift)
+		segment.EndAt = segment.EndAt.Add(shift)
+		shifted[i] = segment
+	}
+	return shifted
+}
+
+// loadSegments carrega as etapas do agendamento em ordem.
+func loadSegments(db *gorm.DB, booking *domain.Booking) error {
+	return db.
+		Where("tenant_id = ? AND booking_id = ?", booking.TenantID, booking.ID).
+		Order("position ASC").
+		Find(&booking.Segments).Error
+}
+
+func preloadSegments(db *gorm.DB) *gorm.DB {
+	return db.Preload("Segments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	})
+}
+
+// bookingSalesItems gera um item de venda por serviço do agendamento, ao preço atual do serviço.
+func (s *Service) bookingSalesItems(ctx context.Context, tenantID, bookingID uuid.UUID) ([]SalesItemInput, error) {
+	var booking domain.Booking
+	if err := preloadSegments(s.dbWithContext(ctx)).
+		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
+		First(&booking).Error; err != nil {
+		return nil, err
+	}
+
+	serviceIDs := []uuid.UUID{booking.ServiceID}
+	if len(booking.Segments) > 0 {
+		serviceIDs = serviceIDs[:0]
+		for _, segment := range booking.Segments {
+			serviceIDs = append(serviceIDs, segment.ServiceID)
+		}
+	}
+
+	var services []domain.Service
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id IN ?", tenantID, serviceIDs).
+		Find(&services).Error; err != nil {
+		return nil, err
+	}
+	prices := make(map[uuid.UUID]float64, len(services))
+	for _, service := range services {
+		prices[service.ID] = service.Price
+	}
+
+	items := make([]SalesItemInput, 0, len(serviceIDs))
+	for _, serviceID := range serviceIDs {
+		price, ok := prices[serviceID]
+		if !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+		items = append(items, SalesItemInput{
+			Type:      salesItemTypeService,
+			RefID:     serviceID,
+			Quantity:  1,
+			UnitPrice: price,
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestRescheduledBookingShiftsSegments(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	booking := domain.Booking{
+		StartAt: start,
+		EndAt:   start.Add(90 * time.Minute),
+		Segments: []domain.BookingSegment{
+			{Position: 0, StartAt: start, EndAt: start.Add(45 * time.Minute)},
+			{Position: 1, StartAt: start.Add(45 * time.Minute), EndAt: start.Add(90 * time.Minute)},
+		},
+	}
+
+	newStart := start.Add(2 * time.Hour)
+	ignoredEnd := start.Add(10 * time.Hour)
+	moved := rescheduledBooking(booking, &newStart, &ignoredEnd)
+
+	assert.Equal(t, newStart, moved.StartAt)
+	assert.Equal(t, newStart.Add(90*time.Minute), moved.EndAt)
+	assert.Equal(t, newStart.Add(45*time.Minute), moved.Segments[1].StartAt)
+	assert.Equal(t, start, booking.Segments[0].StartAt, "original segments must not change")
+}
+
+func TestMultiServiceBooking(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Combo Client", "combo@example.com", nil)
+	barber := seedProfessionalRecord(t, tenant.ID, "Barbeiro")
+	washer := seedProfessionalRecord(t, tenant.ID, "Lavagem")
+	cut := seedServiceRecord(t, tenant.ID, "Corte", 45)
+	beard := seedServiceRecord(t, tenant.ID, "Barba", 30)
+	wash := seedServiceRecord(t, tenant.ID, "Lavagem", 15)
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: barber.ID,
+		StartAt:        start,
+		Services: []BookingServiceInput{
+			{ServiceID: cut.ID},
+			{ServiceID: beard.ID},
+			{ServiceID: wash.ID, ProfessionalID: &washer.ID},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, cut.ID, booking.ServiceID)
+	assert.Equal(t, start.Add(90*time.Minute), booking.EndAt)
+	require.Len(t, booking.Segments, 3)
+	assert.Equal(t, washer.ID, booking.Segments[2].ProfessionalID)
+	assert.Equal(t, start.Add(75*time.Minute), booking.Segments[2].StartAt)
+
+	// O lavador está livre durante o corte e a barba, mas ocupado na etapa de lavagem.
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: washer.ID,
+		ServiceID:      wash.ID,
+		StartAt:        start,
+	})
+	require.NoError(t, err)
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: washer.ID,
+		ServiceID:      wash.ID,
+		StartAt:        start.Add(75 * time.Minute),
+	})
+	require.ErrorIs(t, err, ErrBookingConflict)
+
+	order, err := testSvc.CreateSalesOrder(ctx, tenant.ID, SalesOrderInput{
+		ClientID:  client.ID,
+		BookingID: &booking.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, order.Items, 3)
+	assert.InDelta(t, 300, order.Total, 0.001)
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// Abrangência de edições e cancelamentos em séries recorrentes.
+const (
+	BookingScopeThis      = "this"
+	BookingScopeFollowing = "following"
+	BookingScopeAll       = "all"
+)
+
+const maxSeriesOccurrences = 52
+
+var (
+	ErrInvalidRecurrence  = errors.New("regra de recorrência inválida")
+	ErrInvalidSeriesScope = errors.New("abrangência de série inválida")
+)
+
+// RecurrenceRule repete o agendamento a cada Interval semanas (1 = semanal, 2 = quinzenal)
+// até atingir Count ocorrências ou a data Until, o que vier primeiro.
+type RecurrenceRule struct {
+	Interval int
+	Count    int
+	Until    *time.Time
+	// SkipConflicts cria as ocorrências livres e ignora as conflitantes; caso contrário
+	// qualquer conflito desfaz a série inteira.
+	SkipConflicts bool
+}
+
+// OccurrenceConflict ocorrência que não pôde ser agendada ou alterada.
+type OccurrenceConflict struct {
+	BookingID *uuid.UUID `json:"booking_id,omitempty"`
+	StartAt   time.Time  `json:"start_at"`
+	EndAt     time.Time  `json:"end_at"`
+	Reason    string     `json:"reason"`
+}
+
+// SeriesConflictError lista as ocorrências em conflito quando a operação na série é recusada.
+type SeriesConflictError struct {
+	Conflicts []OccurrenceConflict
+}
+
+func (e *SeriesConflictError) Error() string {
+	return fmt.Sprintf("%d ocorrência(s) da série em conflito", len(e.Conflicts))
+}
+
+// BookingSeriesResult série criada e ocorrências ignoradas por conflito.
+type BookingSeriesResult struct {
+	SeriesID uuid.UUID            `json:"series_id"`
+	Bookings []domain.Booking     `json:"bookings"`
+	Skipped  []OccurrenceConflict `json:"skipped"`
+}
+
+// CreateBookingSeries gera as ocorrências da regra com o mesmo horário local (fuso da empresa)
+// e as vincula por SeriesID. Cada ocorrência passa pelas mesmas validações de CreateBooking.
+func (s *Service) CreateBookingSeries(ctx context.Context, tenantID uuid.UUID, input BookingInput, rule RecurrenceRule) (*BookingSeriesResult, error) {
+	template, err := s.newBooking(ctx, tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.companyLocation(s.dbWithContext(ctx), tenantID)
+	if err != nil {
+		return nil, err
+	}
+	starts, err := recurrenceStarts(template.StartAt, loc, rule)
+	if err != nil {
+		return nil, err
+	}
+	duration := template.EndAt.Sub(template.StartAt)
+
+	result := &BookingSeriesResult{
+		SeriesID: uuid.New(),
+		Bookings: []domain.Booking{},
+		Skipped:  []OccurrenceConflict{},
+	}
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		for _, start := range starts {
+			booking := *template
+			booking.SeriesID = &result.SeriesID
+			booking.StartAt = start
+			booking.EndAt = start.Add(duration)
+			booking.Segments = shiftSegments(template.Segments, start.Sub(template.StartAt))
+			booking.Metadata = datatypes.JSONMap{}
+			if err := s.checkBookingSchedule(ctx, tx, &booking, nil, input.OverrideAvailability); err != nil {
+				if !isScheduleConflict(err) {
+					return err
+				}
+				result.Skipped = append(result.Skipped, OccurrenceConflict{StartAt: booking.StartAt, EndAt: booking.EndAt, Reason: err.Error()})
+				continue
+			}
+			if err := tx.Create(&booking).Error; err != nil {
+				return err
+			}
+			if err := s.audit(tx, tenantID, AuditEntityBooking, AuditActionCreate, booking.ID, nil, booking); err != nil {
+				return err
+			}
+			if err := s.publish(tx, tenantID, EventBookingCreated, booking); err != nil {
+				return err
+			}
+			result.Bookings = append(result.Bookings, booking)
+		}
+		if len(result.Skipped) > 0 && (!rule.SkipConflicts || len(result.Bookings) == 0) {
+			return &SeriesConflictError{Conflicts: result.Skipped}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateBookingSeries aplica a edição à ocorrência, às seguintes ou à série inteira.
+// Novos horários são aplicados como deslocamento relativo à ocorrência informada;
+// qualquer conflito desfaz a operação. Ocorrências finalizadas não são alteradas.
+func (s *Service) UpdateBookingSeries(ctx context.Context, tenantID, bookingID uuid.UUID, scope string, input BookingUpdateInput) ([]domain.Booking, error) {
+	anchor, targets, err := s.seriesTargets(ctx, tenantID, bookingID, scope)
+	if err != nil {
+		return nil, err
+	}
+	var startShift, endShift time.Duration
+	if input.StartAt != nil {
+		startShift = input.StartAt.Sub(anchor.StartAt)
+	}
+	if input.EndAt != nil {
+		endShift = input.EndAt.Sub(anchor.EndAt)
+	}
+
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var conflicts []OccurrenceConflict
+		for _, target := range targets {
+			occurrence := BookingUpdateInput{
+				Status:               input.Status,
+				Notes:                input.Notes,
+				OverrideAvailability: input.OverrideAvailability,
+			}
+			start, end := target.StartAt, target.EndAt
+			if input.StartAt != nil {
+				start = start.Add(startShift)
+				occurrence.StartAt = &start
+			}
+			if input.EndAt != nil {
+				end = end.Add(endShift)
+				occurrence.EndAt = &end
+			}
+			if err := s.updateBookingTx(ctx, tx, tenantID, target.ID, occurrence); err != nil {
+				if !isScheduleConflict(err) {
+					return err
+				}
+				id := target.ID
+				conflicts = append(conflicts, OccurrenceConflict{BookingID: &id, StartAt: start, EndAt: end, Reason: err.Error()})
+			}
+		}
+		if len(conflicts) > 0 {
+			return &SeriesConflictError{Conflicts: conflicts}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reloadBookings(ctx, tenantID, targets)
+}
+
+// CancelBookingSeries cancela a ocorrência, as seguintes ou a série inteira.
+func (s *Service) CancelBookingSeries(ctx context.Context, tenantID, bookingID uuid.UUID, scope, reason string) ([]domain.Booking, error) {
+	_, targets, err := s.seriesTargets(ctx, tenantID, bookingID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		for _, target := range targets {
+			if err := s.cancelBookingTx(tx, tenantID, target.ID, reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reloadBookings(ctx, tenantID, targets)
+}
+
+// seriesTargets resolve as ocorrências afetadas pela abrangência. Agendamentos avulsos
+// são tratados como série de uma única ocorrência.
+func (s *Service) seriesTargets(ctx context.Context, tenantID, bookingID uuid.UUID, scope string) (*domain.Booking, []domain.Booking, error) {
+	switch scope {
+	case BookingScopeThis, BookingScopeFollowing, BookingScopeAll:
+	default:
+		return nil, nil, ErrInvalidSeriesScope
+	}
+
+	var anchor domain.Booking
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
+		First(&anchor).Error; err != nil {
+		return nil, nil, err
+	}
+	if scope == BookingScopeThis || anchor.SeriesID == nil {
+		return &anchor, []domain.Booking{anchor}, nil
+	}
+
+	query := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND series_id = ? AND status NOT IN ?", tenantID, *anchor.SeriesID, bookingFinalStatuses)
+	if scope == BookingScopeFollowing {
+		query = query.Where("start_at >= ?", anchor.StartAt)
+	}
+	var targets []domain.Booking
+	if err := query.Order("start_at ASC").Find(&targets).Error; err != nil {
+		return nil, nil, err
+	}
+	return &anchor, targets, nil
+}
+
+func (s *Service) reloadBookings(ctx context.Context, tenantID uuid.UUID, bookings []domain.Booking) ([]domain.Booking, error) {
+	ids := make([]uuid.UUID, len(bookings))
+	for i, booking := range bookings {
+		ids[i] = booking.ID
+	}
+	reloaded := []domain.Booking{}
+	if len(ids) == 0 {
+		return reloaded, nil
+	}
+	if err := preloadSegments(s.dbWithContext(ctx)).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Order("start_at ASC").
+		Find(&reloaded).Error; err != nil {
+		return nil, err
+	}
+	return reloaded, nil
+}
+
+// recurrenceStarts calcula os inícios das ocorrências preservando o horário local,
+// inclusive em mudanças de horário de verão.
+func recurrenceStarts(start time.Time, loc *time.Location, rule RecurrenceRule) ([]time.Time, error) {
+	interval := rule.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if interval < 0 || rule.Count < 0 || rule.Count > maxSeriesOccurrences {
+		return nil, ErrInvalidRecurrence
+	}
+	if rule.Count == 0 && rule.Until == nil {
+		return nil, ErrInvalidRecurrence
+	}
+
+	local := start.In(loc)
+	var starts []time.Time
+	for i := 0; i < maxSeriesOccurrences; i++ {
+		if rule.Count > 0 && i >= rule.Count {
+			break
+		}
+		occurrence := local.AddDate(0, 0, 7*interval*i)
+		if rule.Until != nil && occurrence.After(*rule.Until) {
+			break
+		}
+		starts = append(starts, occurrence.UTC())
+	}
+	if len(starts) == 0 {
+		return nil, ErrInvalidRecurrence
+	}
+	return starts, nil
+}
+
+func isScheduleConflict(err error) bool {
+	return errors.Is(err, ErrBookingConflict) || errors.Is(err, ErrOutsideAvailability)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestRecurrenceStarts(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	// O horário de verão começa em 2026-03-08; a série deve manter 10h locais.
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, loc)
+
+	starts, err := recurrenceStarts(start, loc, RecurrenceRule{Count: 3})
+	require.NoError(t, err)
+	require.Len(t, starts, 3)
+	for i, occurrence := range starts {
+		local := occurrence.In(loc)
+		assert.Equal(t, 10, local.Hour())
+		assert.Equal(t, start.AddDate(0, 0, 7*i).Day(), local.Day())
+	}
+
+	until := time.Date(2026, 4, 1, 0, 0, 0, 0, loc)
+	biweekly, err := recurrenceStarts(start, loc, RecurrenceRule{Interval: 2, Until: &until})
+	require.NoError(t, err)
+	assert.Len(t, biweekly, 3)
+
+	_, err = recurrenceStarts(start, loc, RecurrenceRule{})
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = recurrenceStarts(start, loc, RecurrenceRule{Count: maxSeriesOccurrences + 1})
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+}
+
+func TestBookingSeriesLifecycle(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Series Client", "series@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Series")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	_, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        start.AddDate(0, 0, 14),
+	})
+	require.NoError(t, err)
+
+	input := BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        start,
+	}
+	_, err = testSvc.CreateBookingSeries(ctx, tenant.ID, input, RecurrenceRule{Count: 4})
+	var seriesErr *SeriesConflictError
+	require.True(t, errors.As(err, &seriesErr))
+	require.Len(t, seriesErr.Conflicts, 1)
+	assert.Equal(t, start.AddDate(0, 0, 14), seriesErr.Conflicts[0].StartAt)
+
+	var count int64
+	require.NoError(t, testDB.Model(&domain.Booking{}).Where("tenant_id = ? AND series_id IS NOT NULL", tenant.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	series, err := testSvc.CreateBookingSeries(ctx, tenant.ID, input, RecurrenceRule{Count: 4, SkipConflicts: true})
+	require.NoError(t, err)
+	require.Len(t, series.Bookings, 3)
+	require.Len(t, series.Skipped, 1)
+
+	second := series.Bookings[1]
+	moved := second.StartAt.Add(time.Hour)
+	movedEnd := second.EndAt.Add(time.Hour)
+	updated, err := testSvc.UpdateBookingSeries(ctx, tenant.ID, second.ID, BookingScopeFollowing, BookingUpdateInput{
+		StartAt: &moved,
+		EndAt:   &movedEnd,
+	})
+	require.NoError(t, err)
+	require.Len(t, updated, 2)
+	for _, booking := range updated {
+		assert.Equal(t, 11, booking.StartAt.UTC().Hour())
+	}
+
+	var first domain.Booking
+	require.NoError(t, testDB.First(&first, "id = ?", series.Bookings[0].ID).Error)
+	assert.Equal(t, 10, first.StartAt.UTC().Hour())
+
+	canceled, err := testSvc.CancelBookingSeries(ctx, tenant.ID, first.ID, BookingScopeAll, "Cliente mudou de cidade")
+	require.NoError(t, err)
+	require.Len(t, canceled, 3)
+	for _, booking := range canceled {
+		assert.Equal(t, domain.BookingStatusCanceled, booking.Status)
+	}
+
+	_, err = testSvc.CancelBookingSeries(ctx, tenant.ID, first.ID, "everything", "")
+	assert.ErrorIs(t, err, ErrInvalidSeriesScope)
+}
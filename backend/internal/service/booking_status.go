@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// BookingTransitionHook é executado dentro da transação sempre que um agendamento muda de status.
+// Retornar erro desfaz a transição.
+type BookingTransitionHook func(tx *gorm.DB, booking *domain.Booking, from, to string) error
+
+var (
+	ErrInvalidBookingStatus = errors.New("status de agendamento inválido")
+	ErrBookingFinalized     = errors.New("agendamento finalizado não pode ser remarcado")
+)
+
+// bookingInitialStatuses são os status aceitos na criação; os demais só são atingidos
+// por transição.
+var bookingInitialStatuses = []string{
+	domain.BookingStatusPending,
+	domain.BookingStatusConfirmed,
+}
+
+var bookingTransitions = map[string][]string{
+	domain.BookingStatusPending: {
+		domain.BookingStatusConfirmed,
+		domain.BookingStatusCheckedIn,
+		domain.BookingStatusCanceled,
+		domain.BookingStatusNoShow,
+	},
+	domain.BookingStatusConfirmed: {
+		domain.BookingStatusCheckedIn,
+		domain.BookingStatusDone,
+		domain.BookingStatusCanceled,
+		domain.BookingStatusNoShow,
+	},
+	domain.BookingStatusCheckedIn: {
+		domain.BookingStatusDone,
+		domain.BookingStatusCanceled,
+	},
+	domain.BookingStatusDone:     {},
+	domain.BookingStatusCanceled: {},
+	domain.BookingStatusNoShow:   {},
+}
+
+// bookingStatusTimestamps indica a coluna que registra quando o status foi atingido.
+var bookingStatusTimestamps = map[string]string{
+	domain.BookingStatusConfirmed: "confirmed_at",
+	domain.BookingStatusCheckedIn: "checked_in_at",
+	domain.BookingStatusDone:      "completed_at",
+	domain.BookingStatusCanceled:  "canceled_at",
+	domain.BookingStatusNoShow:    "no_show_at",
+}
+
+// bookingFinalStatuses encerram o agendamento: não saem do status, não são remarcados
+// nem alterados por edições em lote da série.
+var bookingFinalStatuses = []string{
+	domain.BookingStatusDone,
+	domain.BookingStatusCanceled,
+	domain.BookingStatusNoShow,
+}
+
+// bookingInactiveStatuses não ocupam a agenda do profissional.
+var bookingInactiveStatuses = []string{
+	domain.BookingStatusCanceled,
+	domain.BookingStatusNoShow,
+}
+
+// OnBookingTransition registra um hook adicional para transições de agendamentos.
+func (s *Service) OnBookingTransition(hook BookingTransitionHook) {
+	s.bookingHooks = append(s.bookingHooks, hook)
+}
+
+func canTransitionBooking(from, to string) bool {
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBookingStatus rejeita, na criação do agendamento, status desconhecidos ou que
+// pulariam a máquina de estados.
+func validateBookingStatus(status string) error {
+	for _, initial := range bookingInitialStatuses {
+		if status == initial {
+			return nil
+		}
+	}
+	return ErrInvalidBookingStatus
+}
+
+// stampBookingStatus preenche o timestamp do status inicial do agendamento.
+func stampBookingStatus(booking *domain.Booking, at time.Time) {
+	if booking.Status == domain.BookingStatusConfirmed {
+		booking.ConfirmedAt = &at
+	}
+}
+
+func isFinalBookingStatus(status string) bool {
+	for _, final := range bookingFinalStatuses {
+		if status == final {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionBooking valida a transição, executa os hooks e persiste o novo status
+// junto do timestamp correspondente. Deve ser chamada com o agendamento bloqueado.
+func (s *Service) transitionBooking(tx *gorm.DB, booking *domain.Booking, to string, extra map[string]interface{}) error {
+	from := booking.Status
+	if from == to {
+		return nil
+	}
+	if !canTransitionBooking(from, to) {
+		return &InvalidTransitionError{Entity: "agendamento", From: from, To: to}
+	}
+
+	for _, hook := range s.bookingHooks {
+		if err := hook(tx, booking, from, to); err != nil {
+			return err
+		}
+	}
+
+	updates := map[string]interface{}{"status": to}
+	for key, value := range extra {
+		updates[key] = value
+	}
+	if column, ok := bookingStatusTimestamps[to]; ok {
+		updates[column] = time.Now().UTC()
+	}
+	if err := tx.Model(&domain.Booking{}).
+		Where("tenant_id = ? AND id = ?", booking.TenantID, booking.ID).
+		Updates(updates).Error; err != nil {
+		return err
+	}
+	booking.Status = to
+	return nil
+}
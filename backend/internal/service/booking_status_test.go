@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestCanTransitionBooking(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{domain.BookingStatusPending, domain.BookingStatusConfirmed, true},
+		{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn, true},
+		{domain.BookingStatusCheckedIn, domain.BookingStatusDone, true},
+		{domain.BookingStatusConfirmed, domain.BookingStatusNoShow, true},
+		{domain.BookingStatusCanceled, domain.BookingStatusPending, false},
+		{domain.BookingStatusNoShow, domain.BookingStatusCheckedIn, false},
+		{domain.BookingStatusDone, domain.BookingStatusCanceled, false},
+		{domain.BookingStatusCheckedIn, domain.BookingStatusNoShow, false},
+		{domain.BookingStatusPending, "archived", false},
+	}
+
+	for _, tt := range tests {
+		if got := canTransitionBooking(tt.from, tt.to); got != tt.want {
+			t.Fatalf("canTransitionBooking(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBookingStatus(t *testing.T) {
+	assert.NoError(t, validateBookingStatus(domain.BookingStatusPending))
+	assert.NoError(t, validateBookingStatus(domain.BookingStatusConfirmed))
+	for _, status := range []string{
+		domain.BookingStatusCheckedIn,
+		domain.BookingStatusDone,
+		domain.BookingStatusCanceled,
+		domain.BookingStatusNoShow,
+		"whatever",
+	} {
+		assert.ErrorIs(t, validateBookingStatus(status), ErrInvalidBookingStatus, status)
+	}
+}
+
+func TestUpdateBookingStatusRecordsTimestamps(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Status Client", "status@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Status")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 30)
+
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        time.Now().UTC().Truncate(time.Minute).Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, booking.ConfirmedAt)
+
+	for _, status := range []string{domain.BookingStatusConfirmed, domain.BookingStatusCheckedIn, domain.BookingStatusDone} {
+		next := status
+		booking, err = testSvc.UpdateBooking(ctx, tenant.ID, booking.ID, BookingUpdateInput{Status: &next})
+		require.NoError(t, err)
+		assert.Equal(t, status, booking.Status)
+	}
+	assert.NotNil(t, booking.ConfirmedAt)
+	assert.NotNil(t, booking.CheckedInAt)
+	assert.NotNil(t, booking.CompletedAt)
+
+	_, err = testSvc.CancelBooking(ctx, tenant.ID, booking.ID, "tarde demais")
+	var transitionErr *InvalidTransitionError
+	require.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, domain.BookingStatusDone, transitionErr.From)
+
+	later := booking.StartAt.Add(24 * time.Hour)
+	_, err = testSvc.UpdateBooking(ctx, tenant.ID, booking.ID, BookingUpdateInput{StartAt: &later})
+	require.ErrorIs(t, err, ErrBookingFinalized)
+
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        time.Now().UTC().Truncate(time.Minute).Add(-5 * time.Hour),
+		Status:         domain.BookingStatusNoShow,
+	})
+	require.ErrorIs(t, err, ErrInvalidBookingStatus, "a criação não pula a máquina de estados")
+
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        time.Now().UTC().Truncate(time.Minute).Add(5 * time.Hour),
+		Status:         "whatever",
+	})
+	require.ErrorIs(t, err, ErrInvalidBookingStatus)
+}
+
+func TestDashboardAttendanceByProfessional(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Attendance Client", "attendance@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Attendance")
+	service := seedServiceRecord(t, tenant.ID, "Manicure", 30)
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-6 * time.Hour)
+	var ids []*domain.Booking
+	for i := 0; i < 3; i++ {
+		booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+			ClientID:       client.ID,
+			ProfessionalID: pro.ID,
+			ServiceID:      service.ID,
+			StartAt:        base.Add(time.Duration(i) * time.Hour),
+			Status:         domain.BookingStatusConfirmed,
+		})
+		require.NoError(t, err)
+		ids = append(ids, booking)
+	}
+
+	noShow := domain.BookingStatusNoShow
+	_, err := testSvc.UpdateBooking(ctx, tenant.ID, ids[0].ID, BookingUpdateInput{Status: &noShow})
+	require.NoError(t, err)
+	checkedIn := domain.BookingStatusCheckedIn
+	_, err = testSvc.UpdateBooking(ctx, tenant.ID, ids[1].ID, BookingUpdateInput{Status: &checkedIn})
+	require.NoError(t, err)
+	_, err = testSvc.CancelBooking(ctx, tenant.ID, ids[2].ID, "")
+	require.NoError(t, err)
+	// Agendamentos futuros ainda pendentes não puxam as taxas para baixo.
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       client.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        base.Add(48 * time.Hour),
+		Status:         domain.BookingStatusConfirmed,
+	})
+	require.NoError(t, err)
+
+	stats, err := testSvc.DashboardAttendance(ctx, tenant.ID, AttendanceFilter{GroupBy: "professional"})
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, pro.ID, stats[0].GroupID)
+	assert.Equal(t, int64(2), stats[0].Bookings)
+	assert.Equal(t, int64(1), stats[0].NoShows)
+	assert.Equal(t, int64(1), stats[0].CheckedIn)
+	assert.InDelta(t, 0.5, stats[0].NoShowRate, 0.001)
+}
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -16,6 +18,7 @@ import (
 type BookingFilter struct {
 	Date           *time.Time
 	ProfessionalID *uuid.UUID
+	SeriesID       *uuid.UUID
 	Status         string
 }
 
@@ -28,6 +31,11 @@ type BookingInput struct {
 	StartAt        time.Time
 	EndAt          *time.Time
 	Notes          string
+	// Services cria um agendamento com vários serviços em sequência; o fim é
+	// derivado da soma das durações e EndAt é ignorado.
+	Services []BookingServiceInput
+	// OverrideAvailability permite a administradores agendar fora da disponibilidade.
+	OverrideAvailability bool
 }
 
 // BookingUpdateInput campos permitidos na edição.
@@ -36,6 +44,8 @@ type BookingUpdateInput struct {
 	StartAt *time.Time
 	EndAt   *time.Time
 	Notes   *string
+	// OverrideAvailability permite a administradores agendar fora da disponibilidade.
+	OverrideAvailability bool
 }
 
 var ErrBookingConflict = errors.New("já existe agendamento no horário selecionado")
@@ -50,6 +60,9 @@ func (s *Service) ListBookings(ctx context.Context, tenantID uuid.UUID, filter B
 	if filter.ProfessionalID != nil {
 		query = query.Where("professional_id = ?", *filter.ProfessionalID)
 	}
+	if filter.SeriesID != nil {
+		query = query.Where("series_id = ?", *filter.SeriesID)
+	}
 	if filter.Date != nil {
 		start := filter.Date.Truncate(24 * time.Hour)
 		end := start.Add(24 * time.Hour)
@@ -57,13 +70,40 @@ func (s *Service) ListBookings(ctx context.Context, tenantID uuid.UUID, filter B
 	}
 
 	var bookings []domain.Booking
-	if err := query.Order("start_at ASC").Find(&bookings).Error; err != nil {
+	if err := preloadSegments(query).Order("start_at ASC").Find(&bookings).Error; err != nil {
 		return nil, err
 	}
 	return bookings, nil
 }
 
 func (s *Service) CreateBooking(ctx context.Context, tenantID uuid.UUID, input BookingInput) (*domain.Booking, error) {
+	booking, err := s.newBooking(ctx, tenantID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := s.checkBookingSchedule(ctx, tx, booking, nil, input.OverrideAvailability); err != nil {
+			return err
+		}
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityBooking, AuditActionCreate, booking.ID, nil, booking); err != nil {
+			return err
+		}
+		return s.publish(tx, tenantID, EventBookingCreated, booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// newBooking valida as referências e monta o agendamento, calculando o fim pela
+// duração do serviço quando não informado. Com vários serviços, monta as etapas
+// em sequência e usa a primeira como serviço e profissional principais.
+func (s *Service) newBooking(ctx context.Context, tenantID uuid.UUID, input BookingInput) (*domain.Booking, error) {
 	if err := s.ensureTenantRecord(ctx, &domain.Client{}, tenantID, input.ClientID); err != nil {
 		return nil, err
 	}
@@ -73,10 +113,24 @@ func (s *Service) CreateBooking(ctx context.Context, tenantID uuid.UUID, input B
 	if input.Status == "" {
 		input.Status = domain.BookingStatusPending
 	}
+	if err := validateBookingStatus(input.Status); err != nil {
+		return nil, err
+	}
 
 	start := input.StartAt
 	end := input.EndAt
-	if end == nil || end.Before(start) {
+	var segments []domain.BookingSegment
+	if len(input.Services) > 0 {
+		var err error
+		segments, err = s.buildSegments(ctx, tenantID, input.ProfessionalID, start, input.Services)
+		if err != nil {
+			return nil, err
+		}
+		input.ServiceID = segments[0].ServiceID
+		input.ProfessionalID = segments[0].ProfessionalID
+		last := segments[len(segments)-1].EndAt
+		end = &last
+	} else if end == nil || end.Before(start) {
 		var service domain.Service
 		if err := s.dbWithContext(ctx).First(&service, "tenant_id = ? AND id = ?", tenantID, input.ServiceID).Error; err != nil {
 			return nil, err
@@ -85,10 +139,6 @@ func (s *Service) CreateBooking(ctx context.Context, tenantID uuid.UUID, input B
 		end = &calculated
 	}
 
-	if err := s.checkBookingConflict(ctx, tenantID, input.ProfessionalID, start, *end, nil); err != nil {
-		return nil, err
-	}
-
 	booking := &domain.Booking{
 		TenantModel: domain.TenantModel{
 			TenantID: tenantID,
@@ -101,62 +151,116 @@ func (s *Service) CreateBooking(ctx context.Context, tenantID uuid.UUID, input B
 		EndAt:          *end,
 		Notes:          input.Notes,
 		Metadata:       datatypes.JSONMap{},
+		Segments:       segments,
 	}
-
-	if err := s.dbWithContext(ctx).Create(booking).Error; err != nil {
-		return nil, err
-	}
+	stampBookingStatus(booking, time.Now().UTC())
 	return booking, nil
 }
 
 func (s *Service) UpdateBooking(ctx context.Context, tenantID, bookingID uuid.UUID, input BookingUpdateInput) (*domain.Booking, error) {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		return s.updateBookingTx(ctx, tx, tenantID, bookingID, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	var booking domain.Booking
-	if err := s.dbWithContext(ctx).
+	if err := preloadSegments(s.dbWithContext(ctx)).
 		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
 		First(&booking).Error; err != nil {
 		return nil, err
 	}
+	return &booking, nil
+}
 
-	if input.StartAt != nil || input.EndAt != nil {
-		start := booking.StartAt
-		end := booking.EndAt
-		if input.StartAt != nil {
-			start = *input.StartAt
-		}
-		if input.EndAt != nil {
-			end = *input.EndAt
+// updateBookingTx bloqueia o agendamento, valida a nova agenda e aplica status e
+// campos editáveis dentro da transação recebida.
+func (s *Service) updateBookingTx(ctx context.Context, tx *gorm.DB, tenantID, bookingID uuid.UUID, input BookingUpdateInput) error {
+	var booking domain.Booking
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
+		First(&booking).Error; err != nil {
+		return err
+	}
+	if err := loadSegments(tx, &booking); err != nil {
+		return err
+	}
+	before := booking
+
+	rescheduled := input.StartAt != nil || input.EndAt != nil
+	if rescheduled && isFinalBookingStatus(booking.Status) {
+		return ErrBookingFinalized
+	}
+	moved := rescheduledBooking(booking, input.StartAt, input.EndAt)
+	if rescheduled {
+		if err := s.checkBookingSchedule(ctx, tx, &moved, &booking.ID, input.OverrideAvailability); err != nil {
+			return err
 		}
-		if err := s.checkBookingConflict(ctx, tenantID, booking.ProfessionalID, start, end, &booking.ID); err != nil {
-			return nil, err
+	}
+	if input.Status != nil {
+		if err := s.transitionBooking(tx, &booking, *input.Status, nil); err != nil {
+			return err
 		}
 	}
 
 	updates := map[string]interface{}{}
-	if input.Status != nil {
-		updates["status"] = *input.Status
-	}
-	if input.StartAt != nil {
-		updates["start_at"] = *input.StartAt
-	}
-	if input.EndAt != nil {
-		updates["end_at"] = *input.EndAt
+	if rescheduled {
+		updates["start_at"] = moved.StartAt
+		updates["end_at"] = moved.EndAt
 	}
 	if input.Notes != nil {
 		updates["notes"] = *input.Notes
 	}
-
 	if len(updates) == 0 {
-		return &booking, nil
+		return s.auditBookingUpdate(tx, before)
 	}
-
-	if err := s.dbWithContext(ctx).
+	if err := tx.
 		Model(&domain.Booking{}).
 		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
 		Updates(updates).Error; err != nil {
+		return err
+	}
+	if !rescheduled {
+		return s.auditBookingUpdate(tx, before)
+	}
+	for _, segment := range moved.Segments {
+		if err := tx.Model(&domain.BookingSegment{}).
+			Where("id = ?", segment.ID).
+			Updates(map[string]interface{}{"start_at": segment.StartAt, "end_at": segment.EndAt}).Error; err != nil {
+			return err
+		}
+	}
+	moved.Status = booking.Status
+	if input.Notes != nil {
+		moved.Notes = *input.Notes
+	}
+	if err := s.publish(tx, tenantID, EventBookingRescheduled, moved); err != nil {
+		return err
+	}
+	if err := s.auditBookingUpdate(tx, before); err != nil {
+		return err
+	}
+
+	// Remarcações liberam o horário antigo para a lista de espera; cancelamentos já
+	// foram tratados pelo hook de transição.
+	if !isInactiveBookingStatus(booking.Status) {
+		return s.releaseBookingSlots(tx, &booking)
+	}
+	return nil
+}
+
+func (s *Service) CancelBooking(ctx context.Context, tenantID, bookingID uuid.UUID, reason string) (*domain.Booking, error) {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		return s.cancelBookingTx(tx, tenantID, bookingID, reason)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.dbWithContext(ctx).
+	var booking domain.Booking
+	if err := preloadSegments(s.dbWithContext(ctx)).
 		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
 		First(&booking).Error; err != nil {
 		return nil, err
@@ -164,12 +268,13 @@ func (s *Service) UpdateBooking(ctx context.Context, tenantID, bookingID uuid.UU
 	return &booking, nil
 }
 
-func (s *Service) CancelBooking(ctx context.Context, tenantID, bookingID uuid.UUID, reason string) (*domain.Booking, error) {
+func (s *Service) cancelBookingTx(tx *gorm.DB, tenantID, bookingID uuid.UUID, reason string) error {
 	var booking domain.Booking
-	if err := s.dbWithContext(ctx).
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
 		First(&booking).Error; err != nil {
-		return nil, err
+		return err
 	}
 
 	metadata := booking.Metadata
@@ -179,43 +284,129 @@ func (s *Service) CancelBooking(ctx context.Context, tenantID, bookingID uuid.UU
 	if reason != "" {
 		metadata["cancel_reason"] = reason
 	}
+	if booking.Status == domain.BookingStatusCanceled {
+		return nil
+	}
+	before := booking
+	before.Metadata = datatypes.JSONMap{}
+	for key, value := range booking.Metadata {
+		before.Metadata[key] = value
+	}
+	if err := s.transitionBooking(tx, &booking, domain.BookingStatusCanceled, map[string]interface{}{
+		"metadata": metadata,
+	}); err != nil {
+		return err
+	}
+	return s.auditBookingUpdate(tx, before)
+}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Booking{}).
-		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
-		Updates(map[string]interface{}{
-			"status":   domain.BookingStatusCanceled,
-			"metadata": metadata,
-		}).Error; err != nil {
-		return nil, err
+// auditBookingUpdate recarrega o agendamento na transação e audita a diferença em
+// relação a before. As etapas só entram no diff quando before as carregou.
+func (s *Service) auditBookingUpdate(tx *gorm.DB, before domain.Booking) error {
+	var after domain.Booking
+	if err := tx.
+		Where("tenant_id = ? AND id = ?", before.TenantID, before.ID).
+		First(&after).Error; err != nil {
+		return err
 	}
+	if before.Segments != nil {
+		if err := loadSegments(tx, &after); err != nil {
+			return err
+		}
+	}
+	return s.audit(tx, before.TenantID, AuditEntityBooking, AuditActionUpdate, before.ID, before, after)
+}
 
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, bookingID).
-		First(&booking).Error; err != nil {
-		return nil, err
+// checkSchedule valida disponibilidade (salvo override) e capacidade do profissional no intervalo.
+func (s *Service) checkSchedule(ctx context.Context, tx *gorm.DB, tenantID, professionalID uuid.UUID, start, end time.Time, ignoreID *uuid.UUID, override bool) error {
+	if !override {
+		if err := s.checkAvailability(ctx, tenantID, professionalID, start, end); err != nil {
+			return err
+		}
 	}
-	return &booking, nil
+	return s.checkBookingConflict(tx, tenantID, professionalID, start, end, ignoreID)
 }
 
-func (s *Service) checkBookingConflict(ctx context.Context, tenantID, professionalID uuid.UUID, start, end time.Time, ignoreID *uuid.UUID) error {
-	query := s.dbWithContext(ctx).
-		Model(&domain.Booking{}).
-		Where("tenant_id = ? AND professional_id = ? AND status != ?", tenantID, professionalID, domain.BookingStatusCanceled).
-		Where("start_at < ? AND end_at > ?", end, start)
+// checkBookingConflict conta os agendamentos simultâneos do profissional no intervalo,
+// incluindo vagas reservadas pela lista de espera, e rejeita quando a capacidade
+// (MaxParallel) seria excedida. Um advisory lock por profissional serializa as
+// verificações concorrentes até o fim da transação.
+func (s *Service) checkBookingConflict(tx *gorm.DB, tenantID, professionalID uuid.UUID, start, end time.Time, ignoreID *uuid.UUID) error {
+	if err := lockProfessionalSchedule(tx, tenantID, professionalID); err != nil {
+		return err
+	}
 
-	if ignoreID != nil {
-		query = query.Where("id <> ?", *ignoreID)
+	var professional domain.Professional
+	if err := tx.
+		Select("id", "max_parallel").
+		Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+		First(&professional).Error; err != nil {
+		return err
 	}
 
-	var conflict domain.Booking
-	if err := query.First(&conflict).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil
-		}
+	overlapping, err := professionalOccupancy(tx, tenantID, professionalID, start, end, ignoreID)
+	if err != nil {
 		return err
 	}
-	return ErrBookingConflict
+	holds, err := activeHolds(tx, tenantID, professionalID, start, end, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	overlapping = append(overlapping, holds...)
+	if maxConcurrentBookings(overlapping, start, end) >= professionalCapacity(professional) {
+		return ErrBookingConflict
+	}
+	return nil
+}
+
+func lockProfessionalSchedule(tx *gorm.DB, tenantID, professionalID uuid.UUID) error {
+	key := "bookings:" + tenantID.String() + ":" + professionalID.String()
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+func professionalCapacity(professional domain.Professional) int {
+	if professional.MaxParallel < 1 {
+		return 1
+	}
+	return professional.MaxParallel
+}
+
+// maxConcurrentBookings retorna o pico de agendamentos simultâneos dentro de [start, end).
+func maxConcurrentBookings(bookings []domain.Booking, start, end time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, len(bookings)*2)
+	for _, booking := range bookings {
+		if !booking.StartAt.Before(end) || !booking.EndAt.After(start) {
+			continue
+		}
+		from, to := booking.StartAt, booking.EndAt
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		events = append(events, event{at: from, delta: 1}, event{at: to, delta: -1})
+	}
+	// Saídas antes de entradas no mesmo instante: intervalos encostados não se sobrepõem.
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at.Equal(events[j].at) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].at.Before(events[j].at)
+	})
+
+	current, peak := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
 }
 
 func (s *Service) ListAllBookings(ctx context.Context, filter BookingFilter) ([]domain.Booking, error) {
@@ -250,13 +441,17 @@ func (s *Service) AdminCreateBooking(ctx context.Context, input AdminBookingInpu
 }
 
 func (s *Service) AdminUpdateBooking(ctx context.Context, bookingID uuid.UUID, input BookingUpdateInput) (*domain.Booking, error) {
-	var booking domain.Booking
-	if err := s.dbWithContext(ctx).First(&booking, "id = ?", bookingID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.Booking{}, bookingID)
+	if err != nil {
 		return nil, err
 	}
-	return s.UpdateBooking(ctx, booking.TenantID, bookingID, input)
+	return s.UpdateBooking(ctx, tenantID, bookingID, input)
 }
 
 func (s *Service) AdminDeleteBooking(ctx context.Context, bookingID uuid.UUID) error {
-	return s.dbWithContext(ctx).Delete(&domain.Booking{}, "id = ?", bookingID).Error
+	tenantID, err := s.recordTenant(ctx, &domain.Booking{}, bookingID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	return s.deleteTenantRecord(ctx, tenantID, bookingID, &domain.Booking{}, AuditEntityBooking)
 }
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -114,13 +116,17 @@ func TestListBookingsFilters(t *testing.T) {
 	pro2 := seedProfessionalRecord(t, tenant.ID, "Pro Filter 2")
 
 	date := time.Now().UTC().Truncate(24 * time.Hour)
-	_, _ = testSvc.CreateBooking(context.Background(), tenant.ID, BookingInput{
+	done, err := testSvc.CreateBooking(context.Background(), tenant.ID, BookingInput{
 		ClientID:       client.ID,
 		ProfessionalID: pro1.ID,
 		ServiceID:      service.ID,
 		StartAt:        date.Add(10 * time.Hour),
-		Status:         domain.BookingStatusDone,
+		Status:         domain.BookingStatusConfirmed,
 	})
+	require.NoError(t, err)
+	doneStatus := domain.BookingStatusDone
+	_, err = testSvc.UpdateBooking(context.Background(), tenant.ID, done.ID, BookingUpdateInput{Status: &doneStatus})
+	require.NoError(t, err)
 	_, _ = testSvc.CreateBooking(context.Background(), tenant.ID, BookingInput{
 		ClientID:       client.ID,
 		ProfessionalID: pro2.ID,
@@ -236,3 +242,57 @@ func TestListAllBookings(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, bookings, 2)
 }
+
+func TestMaxConcurrentBookings(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	bookings := []domain.Booking{
+		{StartAt: base, EndAt: base.Add(time.Hour)},
+		{StartAt: base.Add(time.Hour), EndAt: base.Add(2 * time.Hour)},
+		{StartAt: base.Add(30 * time.Minute), EndAt: base.Add(90 * time.Minute)},
+	}
+
+	assert.Equal(t, 2, maxConcurrentBookings(bookings, base, base.Add(2*time.Hour)))
+	assert.Equal(t, 1, maxConcurrentBookings(bookings[:2], base, base.Add(2*time.Hour)))
+	assert.Equal(t, 0, maxConcurrentBookings(bookings, base.Add(3*time.Hour), base.Add(4*time.Hour)))
+}
+
+func TestCreateBookingHonorsMaxParallelUnderConcurrency(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	client := seedClientRecord(t, tenant.ID, "Parallel Client", "parallel@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Escova", 60)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Parallel")
+	require.NoError(t, testDB.Model(pro).Update("max_parallel", 2).Error)
+
+	start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	const attempts = 6
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		created   int
+		conflicts int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := testSvc.CreateBooking(context.Background(), tenant.ID, BookingInput{
+				ClientID:       client.ID,
+				ProfessionalID: pro.ID,
+				ServiceID:      service.ID,
+				StartAt:        start,
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				created++
+			} else if errors.Is(err, ErrBookingConflict) {
+				conflicts++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, created)
+	assert.Equal(t, attempts-2, conflicts)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -66,17 +67,23 @@ func (s *Service) CreateService(ctx context.Context, tenantID uuid.UUID, input I
 		Color:           input.Color,
 		Metadata:        datatypes.JSONMap(input.Metadata),
 	}
-	if err := s.dbWithContext(ctx).Create(service).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(service).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityService, AuditActionCreate, service.ID, nil, service)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return service, nil
 }
 
 func (s *Service) UpdateService(ctx context.Context, tenantID, serviceID uuid.UUID, input Input) (*domain.Service, error) {
-	var service domain.Service
+	var before, service domain.Service
 	if err := s.dbWithContext(ctx).
 		Where("tenant_id = ? AND id = ?", tenantID, serviceID).
-		First(&service).Error; err != nil {
+		First(&before).Error; err != nil {
 		return nil, err
 	}
 
@@ -90,25 +97,28 @@ func (s *Service) UpdateService(ctx context.Context, tenantID, serviceID uuid.UU
 		"metadata":         datatypes.JSONMap(input.Metadata),
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Service{}).
-		Where("tenant_id = ? AND id = ?", tenantID, serviceID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, serviceID).
-		First(&service).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.Service{}).
+			Where("tenant_id = ? AND id = ?", tenantID, serviceID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, serviceID).
+			First(&service).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityService, AuditActionUpdate, serviceID, before, service)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return &service, nil
 }
 
 func (s *Service) DeleteService(ctx context.Context, tenantID, serviceID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, serviceID).
-		Delete(&domain.Service{}).Error
+	return s.deleteTenantRecord(ctx, tenantID, serviceID, &domain.Service{}, AuditEntityService)
 }
 
 func (s *Service) ListProducts(ctx context.Context, tenantID uuid.UUID) ([]domain.Product, error) {
@@ -141,12 +151,11 @@ func (s *Service) CreateProduct(ctx context.Context, tenantID uuid.UUID, input P
 		SKU:         input.SKU,
 		Price:       input.Price,
 		Cost:        input.Cost,
-		StockQty:    input.StockQty,
 		MinStock:    input.MinStock,
 		Description: input.Description,
 		Metadata:    datatypes.JSONMap(input.Metadata),
 	}
-	if err := s.dbWithContext(ctx).Create(product).Error; err != nil {
+	if err := s.createProductWithStock(ctx, product, input.StockQty); err != nil {
 		return nil, err
 	}
 	return product, nil
@@ -165,31 +174,19 @@ func (s *Service) UpdateProduct(ctx context.Context, tenantID, productID uuid.UU
 		"sku":         input.SKU,
 		"price":       input.Price,
 		"cost":        input.Cost,
-		"stock_qty":   input.StockQty,
 		"min_stock":   input.MinStock,
 		"description": input.Description,
 		"metadata":    datatypes.JSONMap(input.Metadata),
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Product{}).
-		Where("tenant_id = ? AND id = ?", tenantID, productID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, productID).
-		First(&product).Error; err != nil {
+	if err := s.updateProductWithStock(ctx, &product, updates, input.StockQty); err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
 func (s *Service) DeleteProduct(ctx context.Context, tenantID, productID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, productID).
-		Delete(&domain.Product{}).Error
+	return s.deleteTenantRecord(ctx, tenantID, productID, &domain.Product{}, AuditEntityProduct)
 }
 
 func (s *Service) ListAllProducts(ctx context.Context) ([]domain.Product, error) {
@@ -208,60 +205,75 @@ type AdminProductInput struct {
 }
 
 func (s *Service) AdminCreateProduct(ctx context.Context, input AdminProductInput) (*domain.Product, error) {
-	product := &domain.Product{
-		TenantModel: domain.TenantModel{
-			TenantID: input.TenantID,
-		},
-		Name:        input.Name,
-		SKU:         input.SKU,
-		Price:       input.Price,
-		Cost:        input.Cost,
-		StockQty:    input.StockQty,
-		MinStock:    input.MinStock,
-		Description: input.Description,
-		Metadata:    datatypes.JSONMap(input.Metadata),
-	}
-	if err := s.dbWithContext(ctx).Create(product).Error; err != nil {
-		return nil, err
-	}
-	return product, nil
+	return s.CreateProduct(ctx, input.TenantID, input.ProductInput)
 }
 
 func (s *Service) AdminUpdateProduct(ctx context.Context, productID uuid.UUID, input ProductInput) (*domain.Product, error) {
-	var product domain.Product
-	if err := s.dbWithContext(ctx).
-		First(&product, "id = ?", productID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.Product{}, productID)
+	if err != nil {
 		return nil, err
 	}
+	return s.UpdateProduct(ctx, tenantID, productID, input)
+}
 
-	updates := map[string]interface{}{
-		"name":        input.Name,
-		"sku":         input.SKU,
-		"price":       input.Price,
-		"cost":        input.Cost,
-		"stock_qty":   input.StockQty,
-		"min_stock":   input.MinStock,
-		"description": input.Description,
-		"metadata":    datatypes.JSONMap(input.Metadata),
-	}
-
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Product{}).
-		Where("id = ?", productID).
-		Updates(updates).Error; err != nil {
-		return nil, err
+func (s *Service) AdminDeleteProduct(ctx context.Context, productID uuid.UUID) error {
+	tenantID, err := s.recordTenant(ctx, &domain.Product{}, productID)
+	if err != nil {
+		return ignoreNotFound(err)
 	}
+	return s.DeleteProduct(ctx, tenantID, productID)
+}
 
-	if err := s.dbWithContext(ctx).
-		First(&product, "id = ?", productID).Error; err != nil {
-		return nil, err
-	}
-	return &product, nil
+// createProductWithStock cria o produto e registra o estoque inicial como uma
+// entrada no ledger, para que o saldo possa ser reconstruído depois.
+func (s *Service) createProductWithStock(ctx context.Context, product *domain.Product, initialStock int) error {
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return err
+		}
+		if initialStock > 0 {
+			movement, err := s.applyInventoryMovement(tx, product.TenantID, InventoryInput{
+				ProductID: product.ID,
+				Type:      domain.InventoryMovementIn,
+				Quantity:  initialStock,
+				Reason:    "estoque inicial",
+			})
+			if err != nil {
+				return err
+			}
+			product.StockQty = movement.BalanceAfter
+		}
+		return s.audit(tx, product.TenantID, AuditEntityProduct, AuditActionCreate, product.ID, nil, product)
+	})
 }
 
-func (s *Service) AdminDeleteProduct(ctx context.Context, productID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Delete(&domain.Product{}, "id = ?", productID).Error
+// updateProductWithStock aplica as alterações cadastrais e converte mudanças de
+// stock_qty em um ajuste no ledger. Ao final, product reflete o registro atualizado.
+func (s *Service) updateProductWithStock(ctx context.Context, product *domain.Product, updates map[string]interface{}, stockQty int) error {
+	before := *product
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Product{}).
+			Where("tenant_id = ? AND id = ?", product.TenantID, product.ID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if stockQty != before.StockQty {
+			if _, err := s.applyInventoryMovement(tx, product.TenantID, InventoryInput{
+				ProductID: product.ID,
+				Type:      domain.InventoryMovementAdjustment,
+				Quantity:  stockQty,
+				Reason:    "ajuste via cadastro do produto",
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", before.TenantID, before.ID).
+			First(product).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, product.TenantID, AuditEntityProduct, AuditActionUpdate, product.ID, before, product)
+	})
 }
 
 func (s *Service) ListAllServices(ctx context.Context) ([]domain.Service, error) {
@@ -280,56 +292,21 @@ type AdminServiceInput struct {
 }
 
 func (s *Service) AdminCreateService(ctx context.Context, input AdminServiceInput) (*domain.Service, error) {
-	service := &domain.Service{
-		TenantModel: domain.TenantModel{
-			TenantID: input.TenantID,
-		},
-		Name:            input.Name,
-		Category:        input.Category,
-		Description:     input.Description,
-		DurationMinutes: input.DurationMinutes,
-		Price:           input.Price,
-		Color:           input.Color,
-		Metadata:        datatypes.JSONMap(input.Metadata),
-	}
-	if err := s.dbWithContext(ctx).Create(service).Error; err != nil {
-		return nil, err
-	}
-	return service, nil
+	return s.CreateService(ctx, input.TenantID, input.Input)
 }
 
 func (s *Service) AdminUpdateService(ctx context.Context, serviceID uuid.UUID, input Input) (*domain.Service, error) {
-	var service domain.Service
-	if err := s.dbWithContext(ctx).
-		First(&service, "id = ?", serviceID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.Service{}, serviceID)
+	if err != nil {
 		return nil, err
 	}
-
-	updates := map[string]interface{}{
-		"name":             input.Name,
-		"category":         input.Category,
-		"description":      input.Description,
-		"duration_minutes": input.DurationMinutes,
-		"price":            input.Price,
-		"color":            input.Color,
-		"metadata":         datatypes.JSONMap(input.Metadata),
-	}
-
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Service{}).
-		Where("id = ?", serviceID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		First(&service, "id = ?", serviceID).Error; err != nil {
-		return nil, err
-	}
-	return &service, nil
+	return s.UpdateService(ctx, tenantID, serviceID, input)
 }
 
 func (s *Service) AdminDeleteService(ctx context.Context, serviceID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Delete(&domain.Service{}, "id = ?", serviceID).Error
+	tenantID, err := s.recordTenant(ctx, &domain.Service{}, serviceID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	return s.DeleteService(ctx, tenantID, serviceID)
 }
@@ -90,7 +90,16 @@ func (s *Service) CreateClient(ctx context.Context, tenantID uuid.UUID, input Cl
 		Tags:    marshalTags(input.Tags),
 		Contact: marshalContact(input.Contact),
 	}
-	if err := s.dbWithContext(ctx).Create(client).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(client).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityClient, AuditActionCreate, client.ID, nil, client); err != nil {
+			return err
+		}
+		return s.publish(tx, tenantID, EventClientCreated, client)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return client, nil
@@ -137,17 +146,26 @@ func (s *Service) GetClient(ctx context.Context, tenantID, clientID uuid.UUID) (
 		Scan(&ts).Error; err != nil {
 		return &client, nil, err
 	}
-	stats.TotalSpent = ts.Sum
+	var refunded totalSpent
+	if err := s.dbWithContext(ctx).
+		Model(&domain.Refund{}).
+		Select("COALESCE(SUM(refunds.amount),0) AS sum").
+		Joins("JOIN sales_orders so ON so.id = refunds.order_id").
+		Where("refunds.tenant_id = ? AND so.client_id = ?", tenantID, clientID).
+		Scan(&refunded).Error; err != nil {
+		return &client, nil, err
+	}
+	stats.TotalSpent = ts.Sum - refunded.Sum
 
 	return &client, &stats, nil
 }
 
 // UpdateClient realiza alterações completas.
 func (s *Service) UpdateClient(ctx context.Context, tenantID, clientID uuid.UUID, input ClientInput) (*domain.Client, error) {
-	var client domain.Client
+	var before, client domain.Client
 	if err := s.dbWithContext(ctx).
 		Where("tenant_id = ? AND id = ?", tenantID, clientID).
-		First(&client).Error; err != nil {
+		First(&before).Error; err != nil {
 		return nil, err
 	}
 
@@ -160,16 +178,24 @@ func (s *Service) UpdateClient(ctx context.Context, tenantID, clientID uuid.UUID
 		"tags":    marshalTags(input.Tags),
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Client{}).
-		Where("tenant_id = ? AND id = ?", tenantID, clientID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, clientID).
-		First(&client).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.Client{}).
+			Where("tenant_id = ? AND id = ?", tenantID, clientID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, clientID).
+			First(&client).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityClient, AuditActionUpdate, clientID, before, client); err != nil {
+			return err
+		}
+		return s.publish(tx, tenantID, EventClientUpdated, client)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -178,9 +204,21 @@ func (s *Service) UpdateClient(ctx context.Context, tenantID, clientID uuid.UUID
 
 // DeleteClient faz soft delete.
 func (s *Service) DeleteClient(ctx context.Context, tenantID, clientID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, clientID).
-		Delete(&domain.Client{}).Error
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var client domain.Client
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, clientID).
+			First(&client).Error; err != nil {
+			return ignoreNotFound(err)
+		}
+		if err := tx.Delete(&client).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityClient, AuditActionDelete, clientID, client, nil); err != nil {
+			return err
+		}
+		return s.publish(tx, tenantID, EventClientDeleted, map[string]interface{}{"id": clientID})
+	})
 }
 
 func (s *Service) ListAllClients(ctx context.Context, filter ClientsFilter) ([]domain.Client, int64, error) {
@@ -226,57 +264,23 @@ type AdminClientInput struct {
 }
 
 func (s *Service) AdminCreateClient(ctx context.Context, input AdminClientInput) (*domain.Client, error) {
-	client := &domain.Client{
-		TenantModel: domain.TenantModel{
-			TenantID: input.TenantID,
-		},
-		Name:    input.Name,
-		Email:   input.Email,
-		Phone:   input.Phone,
-		Notes:   input.Notes,
-		Tags:    marshalTags(input.Tags),
-		Contact: marshalContact(input.Contact),
-	}
-	if err := s.dbWithContext(ctx).Create(client).Error; err != nil {
-		return nil, err
-	}
-	return client, nil
+	return s.CreateClient(ctx, input.TenantID, input.ClientInput)
 }
 
 func (s *Service) AdminUpdateClient(ctx context.Context, clientID uuid.UUID, input ClientInput) (*domain.Client, error) {
-	var client domain.Client
-	if err := s.dbWithContext(ctx).
-		First(&client, "id = ?", clientID).Error; err != nil {
-		return nil, err
-	}
-
-	updates := map[string]interface{}{
-		"name":    input.Name,
-		"email":   input.Email,
-		"phone":   input.Phone,
-		"notes":   input.Notes,
-		"contact": marshalContact(input.Contact),
-		"tags":    marshalTags(input.Tags),
-	}
-
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Client{}).
-		Where("id = ?", clientID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		First(&client, "id = ?", clientID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.Client{}, clientID)
+	if err != nil {
 		return nil, err
 	}
-
-	return &client, nil
+	return s.UpdateClient(ctx, tenantID, clientID, input)
 }
 
 func (s *Service) AdminDeleteClient(ctx context.Context, clientID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Delete(&domain.Client{}, "id = ?", clientID).Error
+	tenantID, err := s.recordTenant(ctx, &domain.Client{}, clientID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	return s.DeleteClient(ctx, tenantID, clientID)
 }
 
 func marshalTags(tags []string) datatypes.JSON {
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -28,8 +30,8 @@ func (s *Service) GetCompany(ctx context.Context, tenantID uuid.UUID) (*domain.C
 
 // UpdateCompany aplica mudanças parciais nos dados da empresa.
 func (s *Service) UpdateCompany(ctx context.Context, tenantID uuid.UUID, input CompanyUpdateInput) (*domain.Company, error) {
-	var company domain.Company
-	if err := s.dbWithContext(ctx).First(&company, "id = ?", tenantID).Error; err != nil {
+	var before, company domain.Company
+	if err := s.dbWithContext(ctx).First(&before, "id = ?", tenantID).Error; err != nil {
 		return nil, err
 	}
 
@@ -51,19 +53,90 @@ func (s *Service) UpdateCompany(ctx context.Context, tenantID uuid.UUID, input C
 	}
 
 	if len(updates) == 0 {
-		return &company, nil
+		return &before, nil
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.Company{}).
-		Where("id = ?", tenantID).
-		Updates(updates).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.Company{}).
+			Where("id = ?", tenantID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&company, "id = ?", tenantID).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityCompany, AuditActionUpdate, tenantID, before, company)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := s.dbWithContext(ctx).First(&company, "id = ?", tenantID).Error; err != nil {
+	return &company, nil
+}
+
+// companySettings carrega as configurações do tenant usando a conexão informada,
+// permitindo a leitura dentro de transações em andamento.
+func (s *Service) companySettings(db *gorm.DB, tenantID uuid.UUID) (datatypes.JSONMap, error) {
+	var company domain.Company
+	if err := db.Select("id", "settings").First(&company, "id = ?", tenantID).Error; err != nil {
 		return nil, err
 	}
+	if company.Settings == nil {
+		return datatypes.JSONMap{}, nil
+	}
+	return company.Settings, nil
+}
 
-	return &company, nil
+func settingBool(settings datatypes.JSONMap, key string) bool {
+	value, ok := settings[key]
+	if !ok {
+		return false
+	}
+	enabled, ok := value.(bool)
+	return ok && enabled
+}
+
+func settingInt(settings datatypes.JSONMap, key string) int {
+	switch value := settings[key].(type) {
+	case float64:
+		return int(value)
+	case int:
+		return value
+	default:
+		return 0
+	}
+}
+
+// settingInts lê uma lista de inteiros; nil quando a chave não existe ou não é uma lista.
+func settingInts(settings datatypes.JSONMap, key string) []int {
+	items, ok := settings[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]int, 0, len(items))
+	for _, item := range items {
+		switch value := item.(type) {
+		case float64:
+			values = append(values, int(value))
+		case int:
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// settingStrings lê uma lista de strings; nil quando a chave não existe ou não é uma lista.
+func settingStrings(settings datatypes.JSONMap, key string) []string {
+	items, ok := settings[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if value, ok := item.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
 }
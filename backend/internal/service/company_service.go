@@ -14,6 +14,8 @@ type CompanyRepository interface {
 	Create(ctx context.Context, company *domain.Company) error
 	Update(ctx context.Context, company *domain.Company) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	CreateAuditLog(ctx context.Context, entry *domain.AuditLog) error
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type CompanyService struct {
@@ -47,7 +49,13 @@ func (s *CompanyService) CreateCompany(ctx context.Context, input CreateCompanyI
 		Email:    input.Email,
 	}
 
-	if err := s.repo.Create(ctx, company); err != nil {
+	err := s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, company); err != nil {
+			return err
+		}
+		return s.audit(ctx, company.ID, AuditActionCreate, nil, company)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -66,6 +74,7 @@ func (s *CompanyService) UpdateCompany(ctx context.Context, id uuid.UUID, input
 	if err != nil {
 		return nil, err
 	}
+	before := *company
 
 	if input.Name != "" {
 		company.Name = input.Name
@@ -80,7 +89,13 @@ func (s *CompanyService) UpdateCompany(ctx context.Context, id uuid.UUID, input
 		company.Email = input.Email
 	}
 
-	if err := s.repo.Update(ctx, company); err != nil {
+	err = s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, company); err != nil {
+			return err
+		}
+		return s.audit(ctx, company.ID, AuditActionUpdate, before, company)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -88,5 +103,23 @@ func (s *CompanyService) UpdateCompany(ctx context.Context, id uuid.UUID, input
 }
 
 func (s *CompanyService) DeleteCompany(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	return s.repo.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repo.FindByID(ctx, id)
+		if err != nil {
+			return ignoreNotFound(err)
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.audit(ctx, id, AuditActionDelete, before, nil)
+	})
+}
+
+// audit registra a mutação da empresa; o tenant do registro é a própria empresa.
+func (s *CompanyService) audit(ctx context.Context, companyID uuid.UUID, action string, before, after interface{}) error {
+	entry, err := newAuditLog(ctx, companyID, AuditEntityCompany, action, companyID, before, after)
+	if err != nil || entry == nil {
+		return err
+	}
+	return s.repo.CreateAuditLog(ctx, entry)
 }
@@ -23,6 +23,7 @@ type fakeCompanyRepo struct {
 	createdRecord *domain.Company
 	updatedRecord *domain.Company
 	deletedID     uuid.UUID
+	auditLogs     []*domain.AuditLog
 }
 
 func (f *fakeCompanyRepo) ListAll(_ context.Context) ([]domain.Company, error) {
@@ -51,6 +52,15 @@ func (f *fakeCompanyRepo) Delete(_ context.Context, id uuid.UUID) error {
 	return f.deleteErr
 }
 
+func (f *fakeCompanyRepo) CreateAuditLog(_ context.Context, entry *domain.AuditLog) error {
+	f.auditLogs = append(f.auditLogs, entry)
+	return nil
+}
+
+func (f *fakeCompanyRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestCompanyServiceCreateCompany(t *testing.T) {
 	repo := &fakeCompanyRepo{}
 	svc := NewCompanyService(repo)
@@ -66,6 +76,8 @@ func TestCompanyServiceCreateCompany(t *testing.T) {
 	require.NotNil(t, result)
 	assert.Equal(t, "Acme", repo.createdRecord.Name)
 	assert.Equal(t, "contact@acme.dev", repo.createdRecord.Email)
+	require.Len(t, repo.auditLogs, 1)
+	assert.Equal(t, AuditActionCreate, repo.auditLogs[0].Action)
 
 	repo.createErr = errors.New("boom")
 	_, err = svc.CreateCompany(context.Background(), CreateCompanyInput{Name: "Fail"})
@@ -98,6 +110,10 @@ func TestCompanyServiceUpdateCompany(t *testing.T) {
 	assert.Equal(t, "DOC", repo.updatedRecord.Document, "empty fields should not override existing value")
 	assert.Equal(t, "2222", repo.updatedRecord.Phone)
 	assert.Equal(t, "new@example.com", repo.updatedRecord.Email)
+	require.Len(t, repo.auditLogs, 1)
+	changes := repo.auditLogs[0].Metadata["changes"].(map[string]interface{})
+	assert.Contains(t, changes, "name")
+	assert.NotContains(t, changes, "document")
 
 	repo.findErr = errors.New("not found")
 	_, err = svc.UpdateCompany(context.Background(), uuid.New(), UpdateCompanyInput{Name: "Should fail"})
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -51,7 +52,16 @@ func (s *Service) DashboardDaily(ctx context.Context, tenantID uuid.UUID, date t
 	if err := paymentQuery.Select("COALESCE(SUM(amount),0) as total").Scan(&rev).Error; err != nil {
 		return nil, err
 	}
-	revenue := rev.Total
+	var refunded struct {
+		Total float64
+	}
+	if err := s.dbWithContext(ctx).Model(&domain.Refund{}).
+		Where("tenant_id = ? AND refunded_at >= ? AND refunded_at < ?", tenantID, start, end).
+		Select("COALESCE(SUM(amount),0) as total").
+		Scan(&refunded).Error; err != nil {
+		return nil, err
+	}
+	revenue := rev.Total - refunded.Total
 
 	type result struct {
 		ServiceID uuid.UUID
@@ -87,6 +97,62 @@ func (s *Service) DashboardDaily(ctx context.Context, tenantID uuid.UUID, date t
 	}, nil
 }
 
+// AttendanceFilter filtros das métricas de comparecimento.
+type AttendanceFilter struct {
+	GroupBy   string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// AttendanceStats resume pontualidade e no-show por profissional ou cliente.
+type AttendanceStats struct {
+	GroupID                uuid.UUID `json:"group_id"`
+	Bookings               int64     `json:"bookings"`
+	CheckedIn              int64     `json:"checked_in"`
+	NoShows                int64     `json:"no_shows"`
+	NoShowRate             float64   `json:"no_show_rate"`
+	AvgCheckInDelayMinutes float64   `json:"avg_check_in_delay_minutes"`
+}
+
+var ErrInvalidAttendanceGroup = errors.New("group_by deve ser professional ou client")
+
+// DashboardAttendance calcula taxas de no-show e atraso médio de check-in.
+// Agendamentos cancelados ou que ainda não começaram não entram na base de cálculo.
+func (s *Service) DashboardAttendance(ctx context.Context, tenantID uuid.UUID, filter AttendanceFilter) ([]AttendanceStats, error) {
+	var groupColumn string
+	switch filter.GroupBy {
+	case "", "professional":
+		groupColumn = "professional_id"
+	case "client":
+		groupColumn = "client_id"
+	default:
+		return nil, ErrInvalidAttendanceGroup
+	}
+
+	query := s.dbWithContext(ctx).
+		Model(&domain.Booking{}).
+		Select(groupColumn+" AS group_id, COUNT(*) AS bookings, COUNT(checked_in_at) AS checked_in, COUNT(no_show_at) AS no_shows, "+
+			"COALESCE(AVG(EXTRACT(EPOCH FROM (checked_in_at - start_at)) / 60), 0) AS avg_check_in_delay_minutes").
+		Where("tenant_id = ? AND status <> ? AND start_at <= ?", tenantID, domain.BookingStatusCanceled, time.Now().UTC())
+	if filter.StartDate != nil {
+		query = query.Where("start_at >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("start_at < ?", *filter.EndDate)
+	}
+
+	var stats []AttendanceStats
+	if err := query.Group(groupColumn).Order("no_shows DESC").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		if stats[i].Bookings > 0 {
+			stats[i].NoShowRate = float64(stats[i].NoShows) / float64(stats[i].Bookings)
+		}
+	}
+	return stats, nil
+}
+
 // OverallMetricsDTO estrutura retorno do endpoint.
 type OverallMetricsDTO struct {
 	TotalTenants  int64   `json:"total_tenants"`
@@ -0,0 +1,578 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+var (
+	ErrUnknownEmailTemplate = errors.New("template de e-mail desconhecido")
+	ErrInvalidEmailTemplate = errors.New("template de e-mail inválido")
+)
+
+// defaultEmailTemplates são usados enquanto o tenant não personaliza o template.
+var defaultEmailTemplates = map[string]notify.Template{
+	domain.EmailTemplateSignup: {
+		Subject: `Bem-vindo(a) à {{.Company.Name}}`,
+		Text: `Olá, {{.User.Name}}!
+
+Sua conta em {{.Company.Name}} foi criada. Acesse com o e-mail {{.User.Email}}.
+`,
+		HTML: `<p>Olá, {{.User.Name}}!</p>
+<p>Sua conta em <strong>{{.Company.Name}}</strong> foi criada. Acesse com o e-mail {{.User.Email}}.</p>
+`,
+	},
+	domain.EmailTemplatePasswordReset: {
+		Subject: `Redefinição de senha – {{.Company.Name}}`,
+		Text: `Olá, {{.User.Name}}!
+
+Para criar uma nova senha, acesse: {{.Link}}
+
+Se você não pediu a redefinição, ignore este e-mail.
+`,
+		HTML: `<p>Olá, {{.User.Name}}!</p>
+<p>Para criar uma nova senha, <a href="{{.Link}}">clique aqui</a>.</p>
+<p>Se você não pediu a redefinição, ignore este e-mail.</p>
+`,
+	},
+	domain.EmailTemplateEmailVerification: {
+		Subject: `Confirme seu e-mail – {{.Company.Name}}`,
+		Text: `Olá, {{.User.Name}}!
+
+Para confirmar o e-mail {{.User.Email}} na sua conta em {{.Company.Name}}, acesse: {{.Link}}
+`,
+		HTML: `<p>Olá, {{.User.Name}}!</p>
+<p>Para confirmar o e-mail {{.User.Email}} na sua conta em <strong>{{.Company.Name}}</strong>, <a href="{{.Link}}">clique aqui</a>.</p>
+`,
+	},
+	domain.EmailTemplateBookingConfirmation: {
+		Subject: `Agendamento confirmado – {{.Company.Name}}`,
+		Text: `Olá, {{.Client.Name}}!
+
+Seu horário{{with .Service}} de {{.Name}}{{end}} em {{.Company.Name}} está marcado para {{date .Booking.StartAt}} às {{time .Booking.StartAt}}.
+`,
+		HTML: `<p>Olá, {{.Client.Name}}!</p>
+<p>Seu horário{{with .Service}} de {{.Name}}{{end}} em <strong>{{.Company.Name}}</strong> está marcado para {{date .Booking.StartAt}} às {{time .Booking.StartAt}}.</p>
+`,
+	},
+	domain.EmailTemplateBookingReminder: {
+		Subject: `Lembrete: seu horário em {{.Company.Name}}`,
+		Text:    `Olá, {{.Client.Name}}! Lembramos do seu horário{{with .Service}} de {{.Name}}{{end}} em {{.Company.Name}} no dia {{date .Booking.StartAt}} às {{time .Booking.StartAt}}.`,
+		HTML: `<p>Olá, {{.Client.Name}}!</p>
+<p>Lembramos do seu horário{{with .Service}} de {{.Name}}{{end}} em <strong>{{.Company.Name}}</strong> no dia {{date .Booking.StartAt}} às {{time .Booking.StartAt}}.</p>
+`,
+	},
+	domain.EmailTemplateSalesReceipt: {
+		Subject: `Recibo – {{.Company.Name}}`,
+		Text: `Olá, {{.Client.Name}}!
+
+Obrigado pela preferência. Resumo do pedido:
+{{range .Items}}- {{.Quantity}} x {{.Name}}: {{money .Total}}
+{{end}}{{if gt .SalesOrder.Discount 0.0}}Desconto: {{money .SalesOrder.Discount}}
+{{end}}Total: {{money .SalesOrder.Total}}
+Pago: {{money .SalesOrder.AmountPaid}}
+`,
+		HTML: `<p>Olá, {{.Client.Name}}!</p>
+<p>Obrigado pela preferência. Resumo do pedido:</p>
+<ul>{{range .Items}}<li>{{.Quantity}} x {{.Name}}: {{money .Total}}</li>{{end}}</ul>
+{{if gt .SalesOrder.Discount 0.0}}<p>Desconto: {{money .SalesOrder.Discount}}</p>{{end}}
+<p><strong>Total: {{money .SalesOrder.Total}}</strong><br>Pago: {{money .SalesOrder.AmountPaid}}</p>
+`,
+	},
+}
+
+// EmailTemplateData é o modelo disponível aos templates. Campos não relacionados ao
+// template ficam nulos; datas são formatadas no fuso da empresa por date, time e datetime.
+type EmailTemplateData struct {
+	Company      domain.Company
+	User         *EmailTemplateUser
+	Client       *domain.Client
+	Booking      *domain.Booking
+	Service      *domain.Service
+	Professional *domain.Professional
+	SalesOrder   *domain.SalesOrder
+	Items        []EmailTemplateItem
+	Link         string
+}
+
+// EmailTemplateUser dados do usuário expostos aos templates. Os templates são editáveis
+// pelo tenant, então segredos (hash de senha, segredo MFA) nunca chegam até eles.
+type EmailTemplateUser struct {
+	Name  string
+	Email string
+	Role  string
+}
+
+func emailTemplateUser(user *domain.User) *EmailTemplateUser {
+	return &EmailTemplateUser{Name: user.Name, Email: user.Email, Role: user.Role}
+}
+
+// EmailTemplateItem item do pedido com o nome do serviço ou produto resolvido.
+type EmailTemplateItem struct {
+	Name      string
+	Quantity  int
+	UnitPrice float64
+	Total     float64
+}
+
+// EmailTemplateView template efetivo: o personalizado pelo tenant ou o padrão (Version 0).
+type EmailTemplateView struct {
+	Key        string `json:"key"`
+	Subject    string `json:"subject"`
+	TextBody   string `json:"text_body"`
+	HTMLBody   string `json:"html_body"`
+	Version    int    `json:"version"`
+	Customized bool   `json:"customized"`
+}
+
+// EmailTemplateInput conteúdo de uma nova versão.
+type EmailTemplateInput struct {
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// EmailTemplatePreview resultado renderizado de um template.
+type EmailTemplatePreview struct {
+	Subject  string `json:"subject"`
+	TextBody string `json:"text_body"`
+	HTMLBody string `json:"html_body"`
+}
+
+// EmailTemplatePreviewInput rascunho opcional (sem ele, usa o template efetivo) e
+// registros usados como dados; sem eles, a prévia usa dados de exemplo.
+type EmailTemplatePreviewInput struct {
+	Draft        *EmailTemplateInput
+	BookingID    *uuid.UUID
+	SalesOrderID *uuid.UUID
+	ClientID     *uuid.UUID
+}
+
+// ListEmailTemplates lista todos os templates com o conteúdo efetivo do tenant.
+func (s *Service) ListEmailTemplates(ctx context.Context, tenantID uuid.UUID) ([]EmailTemplateView, error) {
+	var custom []domain.EmailTemplate
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND active = ?", tenantID, true).
+		Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*domain.EmailTemplate, len(custom))
+	for i := range custom {
+		byKey[custom[i].Key] = &custom[i]
+	}
+
+	keys := emailTemplateKeys()
+	views := make([]EmailTemplateView, 0, len(keys))
+	for _, key := range keys {
+		views = append(views, emailTemplateView(key, byKey[key]))
+	}
+	return views, nil
+}
+
+// GetEmailTemplate devolve o template efetivo da chave.
+func (s *Service) GetEmailTemplate(ctx context.Context, tenantID uuid.UUID, key string) (*EmailTemplateView, error) {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return nil, ErrUnknownEmailTemplate
+	}
+	custom, err := s.activeEmailTemplate(s.dbWithContext(ctx), tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+	view := emailTemplateView(key, custom)
+	return &view, nil
+}
+
+// UpdateEmailTemplate grava uma nova versão personalizada. O conteúdo é validado
+// renderizando-o com dados de exemplo.
+func (s *Service) UpdateEmailTemplate(ctx context.Context, tenantID uuid.UUID, key string, input EmailTemplateInput) (*EmailTemplateView, error) {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return nil, ErrUnknownEmailTemplate
+	}
+	if strings.TrimSpace(input.Subject) == "" || strings.TrimSpace(input.TextBody) == "" {
+		return nil, ErrInvalidEmailTemplate
+	}
+	var saved domain.EmailTemplate
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		data, loc, err := s.sampleEmailTemplateData(tx, tenantID)
+		if err != nil {
+			return err
+		}
+		if _, err := renderEmailTemplate(emailTemplateFromInput(input), data, loc); err != nil {
+			return err
+		}
+		return s.saveEmailTemplateVersion(tx, tenantID, key, input, &saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	view := emailTemplateView(key, &saved)
+	return &view, nil
+}
+
+// RestoreEmailTemplateVersion grava o conteúdo de uma versão anterior como nova versão.
+func (s *Service) RestoreEmailTemplateVersion(ctx context.Context, tenantID uuid.UUID, key string, version int) (*EmailTemplateView, error) {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return nil, ErrUnknownEmailTemplate
+	}
+	var saved domain.EmailTemplate
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var previous domain.EmailTemplateVersion
+		if err := tx.
+			Where("tenant_id = ? AND template_key = ? AND version = ?", tenantID, key, version).
+			First(&previous).Error; err != nil {
+			return err
+		}
+		input := EmailTemplateInput{Subject: previous.Subject, TextBody: previous.TextBody, HTMLBody: previous.HTMLBody}
+		return s.saveEmailTemplateVersion(tx, tenantID, key, input, &saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	view := emailTemplateView(key, &saved)
+	return &view, nil
+}
+
+// ResetEmailTemplate volta ao template padrão, preservando o histórico de versões.
+func (s *Service) ResetEmailTemplate(ctx context.Context, tenantID uuid.UUID, key string) error {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return ErrUnknownEmailTemplate
+	}
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		custom, err := s.activeEmailTemplate(tx.Clauses(clause.Locking{Strength: "UPDATE"}), tenantID, key)
+		if err != nil || custom == nil {
+			return err
+		}
+		before := *custom
+		custom.Active = false
+		if err := tx.Model(custom).Update("active", false).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityEmailTemplate, AuditActionUpdate, custom.ID, before, custom)
+	})
+}
+
+// ListEmailTemplateVersions lista o histórico da chave, da versão mais recente para a mais antiga.
+func (s *Service) ListEmailTemplateVersions(ctx context.Context, tenantID uuid.UUID, key string) ([]domain.EmailTemplateVersion, error) {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return nil, ErrUnknownEmailTemplate
+	}
+	var versions []domain.EmailTemplateVersion
+	err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND template_key = ?", tenantID, key).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// PreviewEmailTemplate renderiza o template (ou o rascunho) sem enviar nada.
+func (s *Service) PreviewEmailTemplate(ctx context.Context, tenantID uuid.UUID, key string, input EmailTemplatePreviewInput) (*EmailTemplatePreview, error) {
+	if _, ok := defaultEmailTemplates[key]; !ok {
+		return nil, ErrUnknownEmailTemplate
+	}
+	db := s.dbWithContext(ctx)
+	data, loc, err := s.sampleEmailTemplateData(db, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadPreviewRecords(db, tenantID, input, data); err != nil {
+		return nil, err
+	}
+
+	var tpl notify.Template
+	if input.Draft != nil {
+		tpl = emailTemplateFromInput(*input.Draft)
+	} else {
+		custom, err := s.activeEmailTemplate(db, tenantID, key)
+		if err != nil {
+			return nil, err
+		}
+		tpl = emailTemplateView(key, custom).template()
+	}
+	msg, err := renderEmailTemplate(tpl, data, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailTemplatePreview{Subject: msg.Subject, TextBody: msg.Body, HTMLBody: msg.HTMLBody}, nil
+}
+
+// renderEmail renderiza o template efetivo do tenant para envio. data.Company é
+// preenchida a partir do tenant.
+func (s *Service) renderEmail(db *gorm.DB, tenantID uuid.UUID, key string, data *EmailTemplateData) (notify.Message, error) {
+	if err := db.First(&data.Company, "id = ?", tenantID).Error; err != nil {
+		return notify.Message{}, err
+	}
+	custom, err := s.activeEmailTemplate(db, tenantID, key)
+	if err != nil {
+		return notify.Message{}, err
+	}
+	return renderEmailTemplate(emailTemplateView(key, custom).template(), data, companyTimezone(&data.Company))
+}
+
+func (s *Service) activeEmailTemplate(db *gorm.DB, tenantID uuid.UUID, key string) (*domain.EmailTemplate, error) {
+	var custom domain.EmailTemplate
+	err := db.Where("tenant_id = ? AND key = ? AND active = ?", tenantID, key, true).First(&custom).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &custom, nil
+}
+
+// saveEmailTemplateVersion cria ou atualiza a personalização com a próxima versão e
+// registra o conteúdo no histórico.
+func (s *Service) saveEmailTemplateVersion(tx *gorm.DB, tenantID uuid.UUID, key string, input EmailTemplateInput, saved *domain.EmailTemplate) error {
+	var current domain.EmailTemplate
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND key = ?", tenantID, key).
+		First(&current).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	exists := err == nil
+	before := current
+
+	current.TenantID = tenantID
+	current.Key = key
+	current.Subject = input.Subject
+	current.TextBody = input.TextBody
+	current.HTMLBody = input.HTMLBody
+	current.Version++
+	current.Active = true
+	if exists {
+		err = tx.Model(&current).Select("subject", "text_body", "html_body", "version", "active").Updates(&current).Error
+	} else {
+		err = tx.Create(&current).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	version := domain.EmailTemplateVersion{
+		TenantModel: domain.TenantModel{TenantID: tenantID},
+		TemplateKey: key,
+		Version:     current.Version,
+		Subject:     current.Subject,
+		TextBody:    current.TextBody,
+		HTMLBody:    current.HTMLBody,
+		CreatedBy:   auditActorFrom(tx.Statement.Context).UserID,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return err
+	}
+
+	*saved = current
+	if exists {
+		return s.audit(tx, tenantID, AuditEntityEmailTemplate, AuditActionUpdate, current.ID, before, current)
+	}
+	return s.audit(tx, tenantID, AuditEntityEmailTemplate, AuditActionCreate, current.ID, nil, current)
+}
+
+// sampleEmailTemplateData monta dados fictícios completos para validação e prévia.
+func (s *Service) sampleEmailTemplateData(db *gorm.DB, tenantID uuid.UUID) (*EmailTemplateData, *time.Location, error) {
+	var company domain.Company
+	if err := db.First(&company, "id = ?", tenantID).Error; err != nil {
+		return nil, nil, err
+	}
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	sampleService := &domain.Service{Name: "Corte de cabelo", DurationMinutes: 30, Price: 50}
+	order := &domain.SalesOrder{Status: domain.SalesOrderStatusPaid, Total: 80, AmountPaid: 80}
+	return &EmailTemplateData{
+		Company:      company,
+		User:         &EmailTemplateUser{Name: "Ana Souza", Email: "ana@exemplo.com", Role: domain.UserRoleAdmin},
+		Client:       &domain.Client{Name: "Maria Silva", Email: "maria@exemplo.com", Phone: "+5511999999999"},
+		Booking:      &domain.Booking{Status: domain.BookingStatusConfirmed, StartAt: start, EndAt: start.Add(30 * time.Minute)},
+		Service:      sampleService,
+		Professional: &domain.Professional{Name: "João Pereira"},
+		SalesOrder:   order,
+		Items: []EmailTemplateItem{
+			{Name: sampleService.Name, Quantity: 1, UnitPrice: 50, Total: 50},
+			{Name: "Pomada modeladora", Quantity: 1, UnitPrice: 30, Total: 30},
+		},
+		Link: "https://exemplo.com/link",
+	}, companyTimezone(&company), nil
+}
+
+// loadPreviewRecords substitui os dados de exemplo pelos registros informados.
+func (s *Service) loadPreviewRecords(db *gorm.DB, tenantID uuid.UUID, input EmailTemplatePreviewInput, data *EmailTemplateData) error {
+	if input.BookingID != nil {
+		var booking domain.Booking
+		if err := db.First(&booking, "tenant_id = ? AND id = ?", tenantID, *input.BookingID).Error; err != nil {
+			return err
+		}
+		if err := s.loadBookingEmailData(db, &booking, data); err != nil {
+			return err
+		}
+	}
+	if input.SalesOrderID != nil {
+		var order domain.SalesOrder
+		if err := db.Preload("Items").First(&order, "tenant_id = ? AND id = ?", tenantID, *input.SalesOrderID).Error; err != nil {
+			return err
+		}
+		if err := s.loadSalesOrderEmailData(db, &order, data); err != nil {
+			return err
+		}
+	}
+	if input.ClientID != nil {
+		var client domain.Client
+		if err := db.First(&client, "tenant_id = ? AND id = ?", tenantID, *input.ClientID).Error; err != nil {
+			return err
+		}
+		data.Client = &client
+	}
+	return nil
+}
+
+// loadBookingEmailData preenche agendamento, cliente, serviço e profissional.
+func (s *Service) loadBookingEmailData(db *gorm.DB, booking *domain.Booking, data *EmailTemplateData) error {
+	data.Booking = booking
+	var client domain.Client
+	if err := db.First(&client, "tenant_id = ? AND id = ?", booking.TenantID, booking.ClientID).Error; err != nil {
+		return err
+	}
+	data.Client = &client
+	data.Service = nil
+	var svc domain.Service
+	err := db.First(&svc, "tenant_id = ? AND id = ?", booking.TenantID, booking.ServiceID).Error
+	if err == nil {
+		data.Service = &svc
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	data.Professional = nil
+	var professional domain.Professional
+	err = db.First(&professional, "tenant_id = ? AND id = ?", booking.TenantID, booking.ProfessionalID).Error
+	if err == nil {
+		data.Professional = &professional
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// loadSalesOrderEmailData preenche pedido, cliente e itens com os nomes resolvidos.
+func (s *Service) loadSalesOrderEmailData(db *gorm.DB, order *domain.SalesOrder, data *EmailTemplateData) error {
+	data.SalesOrder = order
+	var client domain.Client
+	if err := db.First(&client, "tenant_id = ? AND id = ?", order.TenantID, order.ClientID).Error; err != nil {
+		return err
+	}
+	data.Client = &client
+
+	data.Items = make([]EmailTemplateItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		var model interface{} = &domain.Product{}
+		if item.ItemType == salesItemTypeService {
+			model = &domain.Service{}
+		}
+		var names []string
+		if err := db.Model(model).
+			Where("tenant_id = ? AND id = ?", order.TenantID, item.ItemRefID).
+			Pluck("name", &names).Error; err != nil {
+			return err
+		}
+		name := strings.Join(names, "")
+		data.Items = append(data.Items, EmailTemplateItem{
+			Name:      name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+			Total:     float64(item.Quantity) * item.UnitPrice,
+		})
+	}
+	return nil
+}
+
+func renderEmailTemplate(tpl notify.Template, data *EmailTemplateData, loc *time.Location) (notify.Message, error) {
+	msg, err := tpl.Render(data, emailTemplateFuncs(loc))
+	if err != nil {
+		return notify.Message{}, fmt.Errorf("%w: %v", ErrInvalidEmailTemplate, err)
+	}
+	return msg, nil
+}
+
+func emailTemplateFuncs(loc *time.Location) map[string]interface{} {
+	return map[string]interface{}{
+		"date":     func(t time.Time) string { return t.In(loc).Format("02/01/2006") },
+		"time":     func(t time.Time) string { return t.In(loc).Format("15:04") },
+		"datetime": func(t time.Time) string { return t.In(loc).Format("02/01/2006 15:04") },
+		"money":    formatMoney,
+	}
+}
+
+// formatMoney formata valores em reais: 1234.5 → "R$ 1.234,50".
+func formatMoney(value float64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	cents := int64(math.Round(value * 100))
+	integer := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%sR$ %s,%02d", sign, grouped.String(), cents%100)
+}
+
+func emailTemplateFromInput(input EmailTemplateInput) notify.Template {
+	return notify.Template{Subject: input.Subject, Text: input.TextBody, HTML: input.HTMLBody}
+}
+
+func emailTemplateView(key string, custom *domain.EmailTemplate) EmailTemplateView {
+	if custom == nil {
+		tpl := defaultEmailTemplates[key]
+		return EmailTemplateView{Key: key, Subject: tpl.Subject, TextBody: tpl.Text, HTMLBody: tpl.HTML}
+	}
+	return EmailTemplateView{
+		Key:        key,
+		Subject:    custom.Subject,
+		TextBody:   custom.TextBody,
+		HTMLBody:   custom.HTMLBody,
+		Version:    custom.Version,
+		Customized: true,
+	}
+}
+
+func (v EmailTemplateView) template() notify.Template {
+	return notify.Template{Subject: v.Subject, Text: v.TextBody, HTML: v.HTMLBody}
+}
+
+func emailTemplateKeys() []string {
+	return []string{
+		domain.EmailTemplateSignup,
+		domain.EmailTemplatePasswordReset,
+		domain.EmailTemplateEmailVerification,
+		domain.EmailTemplateBookingConfirmation,
+		domain.EmailTemplateBookingReminder,
+		domain.EmailTemplateSalesReceipt,
+	}
+}
+
+// companyTimezone resolve o fuso da empresa, com UTC como alternativa.
+func companyTimezone(company *domain.Company) *time.Location {
+	if company.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(company.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
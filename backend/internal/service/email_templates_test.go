@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "R$ 0,00", formatMoney(0))
+	assert.Equal(t, "R$ 12,50", formatMoney(12.5))
+	assert.Equal(t, "R$ 1.234,57", formatMoney(1234.567))
+	assert.Equal(t, "R$ 1.000.000,00", formatMoney(1e6))
+	assert.Equal(t, "-R$ 3,10", formatMoney(-3.1))
+}
+
+func TestDefaultEmailTemplatesRender(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	start := time.Date(2026, 5, 4, 13, 30, 0, 0, time.UTC)
+	data := &EmailTemplateData{
+		Company:    domain.Company{Name: "Barbearia <Central>"},
+		User:       &EmailTemplateUser{Name: "Ana", Email: "ana@example.com"},
+		Client:     &domain.Client{Name: "Maria"},
+		Booking:    &domain.Booking{StartAt: start},
+		Service:    &domain.Service{Name: "Corte"},
+		SalesOrder: &domain.SalesOrder{Total: 45, Discount: 5, AmountPaid: 45},
+		Items:      []EmailTemplateItem{{Name: "Corte", Quantity: 1, UnitPrice: 50, Total: 50}},
+		Link:       "https://example.com/reset?token=abc",
+	}
+
+	for _, key := range emailTemplateKeys() {
+		msg, err := renderEmailTemplate(defaultEmailTemplates[key], data, loc)
+		require.NoError(t, err, key)
+		assert.NotEmpty(t, msg.Subject, key)
+		assert.NotEmpty(t, msg.Body, key)
+		assert.NotContains(t, msg.HTMLBody, "<Central>", "%s: HTML deve escapar os dados", key)
+	}
+
+	msg, err := renderEmailTemplate(defaultEmailTemplates[domain.EmailTemplateBookingReminder], data, loc)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Body, "04/05/2026 às 10:30", "horário no fuso da empresa")
+
+	msg, err = renderEmailTemplate(defaultEmailTemplates[domain.EmailTemplateSalesReceipt], data, loc)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Body, "Desconto: R$ 5,00")
+	assert.Contains(t, msg.Body, "Total: R$ 45,00")
+}
+
+func TestEmailTemplateDataHidesUserSecrets(t *testing.T) {
+	data := &EmailTemplateData{User: emailTemplateUser(&domain.User{
+		Name:         "Ana",
+		Email:        "ana@example.com",
+		PasswordHash: "hash",
+		MFASecret:    "SEGREDO",
+	})}
+
+	for _, field := range []string{"PasswordHash", "MFASecret"} {
+		_, err := renderEmailTemplate(notify.Template{Subject: "x", Text: "{{.User." + field + "}}"}, data, time.UTC)
+		assert.ErrorIs(t, err, ErrInvalidEmailTemplate, field)
+	}
+}
+
+func TestEmailTemplateVersioning(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	key := domain.EmailTemplateBookingConfirmation
+
+	view, err := testSvc.GetEmailTemplate(ctx, tenant.ID, key)
+	require.NoError(t, err)
+	assert.False(t, view.Customized)
+	assert.Zero(t, view.Version)
+
+	_, err = testSvc.UpdateEmailTemplate(ctx, tenant.ID, key, EmailTemplateInput{Subject: "{{.Nada}}", TextBody: "x"})
+	assert.ErrorIs(t, err, ErrInvalidEmailTemplate)
+	_, err = testSvc.GetEmailTemplate(ctx, tenant.ID, "desconhecido")
+	assert.ErrorIs(t, err, ErrUnknownEmailTemplate)
+
+	view, err = testSvc.UpdateEmailTemplate(ctx, tenant.ID, key, EmailTemplateInput{
+		Subject:  "Confirmado: {{.Client.Name}}",
+		TextBody: "Até {{date .Booking.StartAt}}!",
+	})
+	require.NoError(t, err)
+	assert.True(t, view.Customized)
+	assert.Equal(t, 1, view.Version)
+
+	view, err = testSvc.UpdateEmailTemplate(ctx, tenant.ID, key, EmailTemplateInput{
+		Subject:  "Horário confirmado",
+		TextBody: "Até breve, {{.Client.Name}}.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, view.Version)
+
+	client := seedClientRecord(t, tenant.ID, "Carla", "carla@example.com", nil)
+	preview, err := testSvc.PreviewEmailTemplate(ctx, tenant.ID, key, EmailTemplatePreviewInput{ClientID: &client.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "Horário confirmado", preview.Subject)
+	assert.Equal(t, "Até breve, Carla.", preview.TextBody)
+
+	preview, err = testSvc.PreviewEmailTemplate(ctx, tenant.ID, key, EmailTemplatePreviewInput{
+		Draft: &EmailTemplateInput{Subject: "Rascunho {{.Company.Name}}", TextBody: "-"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Rascunho Test Tenant", preview.Subject)
+
+	require.NoError(t, testSvc.ResetEmailTemplate(ctx, tenant.ID, key))
+	view, err = testSvc.GetEmailTemplate(ctx, tenant.ID, key)
+	require.NoError(t, err)
+	assert.False(t, view.Customized)
+
+	view, err = testSvc.RestoreEmailTemplateVersion(ctx, tenant.ID, key, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, view.Version)
+	assert.Equal(t, "Confirmado: {{.Client.Name}}", view.Subject)
+
+	versions, err := testSvc.ListEmailTemplateVersions(ctx, tenant.ID, key)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, 3, versions[0].Version)
+
+	other, _ := createTestTenant()
+	view, err = testSvc.GetEmailTemplate(ctx, other.ID, key)
+	require.NoError(t, err)
+	assert.False(t, view.Customized, "personalização não vaza entre tenants")
+}
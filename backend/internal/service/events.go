@@ -0,0 +1,108 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// Tipos de eventos de domínio publicados pelo service layer.
+const (
+	EventBookingCreated          = "booking.created"
+	EventBookingRescheduled      = "booking.rescheduled"
+	EventBookingStatusChanged    = "booking.status_changed"
+	EventSalesOrderCreated       = "sales_order.created"
+	EventSalesOrderStatusChanged = "sales_order.status_changed"
+	EventPaymentCreated          = "payment.created"
+	EventClientCreated           = "client.created"
+	EventClientUpdated           = "client.updated"
+	EventClientDeleted           = "client.deleted"
+	// EventAll assina todos os tipos de evento.
+	EventAll = "*"
+)
+
+// EventTypes lista os eventos que podem ser assinados.
+var EventTypes = []string{
+	EventBookingCreated,
+	EventBookingRescheduled,
+	EventBookingStatusChanged,
+	EventSalesOrderCreated,
+	EventSalesOrderStatusChanged,
+	EventPaymentCreated,
+	EventClientCreated,
+	EventClientUpdated,
+	EventClientDeleted,
+}
+
+// Event evento de domínio publicado dentro da transação que o originou.
+type Event struct {
+	ID         uuid.UUID   `json:"id"`
+	Type       string      `json:"type"`
+	TenantID   uuid.UUID   `json:"tenant_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// EventHandler é executado na transação do evento. Retornar erro desfaz a operação
+// que o publicou, por isso efeitos externos devem passar pela outbox.
+type EventHandler func(tx *gorm.DB, event Event) error
+
+// StatusChange payload dos eventos de mudança de status.
+type StatusChange struct {
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Record interface{} `json:"record"`
+}
+
+// Subscribe registra um handler para o tipo de evento (ou EventAll).
+func (s *Service) Subscribe(eventType string, handler EventHandler) {
+	if s.eventHandlers == nil {
+		s.eventHandlers = map[string][]EventHandler{}
+	}
+	s.eventHandlers[eventType] = append(s.eventHandlers[eventType], handler)
+}
+
+func (s *Service) publish(tx *gorm.DB, tenantID uuid.UUID, eventType string, data interface{}) error {
+	event := Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	for _, key := range []string{eventType, EventAll} {
+		for _, handler := range s.eventHandlers[key] {
+			if err := handler(tx, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) publishBookingTransition(tx *gorm.DB, booking *domain.Booking, from, to string) error {
+	record := *booking
+	record.Status = to
+	return s.publish(tx, booking.TenantID, EventBookingStatusChanged, StatusChange{From: from, To: to, Record: record})
+}
+
+func (s *Service) publishOrderTransition(tx *gorm.DB, order *domain.SalesOrder, from, to string) error {
+	record := *order
+	record.Status = to
+	return s.publish(tx, order.TenantID, EventSalesOrderStatusChanged, StatusChange{From: from, To: to, Record: record})
+}
+
+func isEventType(eventType string) bool {
+	if eventType == EventAll {
+		return true
+	}
+	for _, known := range EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
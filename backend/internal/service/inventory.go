@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -27,7 +29,18 @@ type InventoryInput struct {
 	Reason    string
 }
 
-var ErrInvalidInventoryType = errors.New("tipo de movimentação inválido")
+// StockRebuildResult resume a reconstrução do saldo de um produto.
+type StockRebuildResult struct {
+	Product     *domain.Product `json:"product"`
+	PreviousQty int             `json:"previous_qty"`
+	RebuiltQty  int             `json:"rebuilt_qty"`
+	Movements   int             `json:"movements"`
+}
+
+var (
+	ErrInvalidInventoryType = errors.New("tipo de movimentação inválido")
+	ErrInsufficientStock    = errors.New("estoque insuficiente para a movimentação")
+)
 
 func (s *Service) ListInventoryMovements(ctx context.Context, tenantID uuid.UUID, filter InventoryFilter) ([]domain.InventoryMovement, error) {
 	query := s.dbWithContext(ctx).
@@ -54,14 +67,8 @@ func (s *Service) ListInventoryMovements(ctx context.Context, tenantID uuid.UUID
 }
 
 func (s *Service) CreateInventoryMovement(ctx context.Context, tenantID uuid.UUID, input InventoryInput) (*domain.InventoryMovement, error) {
-	if input.Quantity <= 0 {
-		return nil, errors.New("quantity must be greater than zero")
-	}
-
-	switch input.Type {
-	case domain.InventoryMovementIn, domain.InventoryMovementOut, domain.InventoryMovementAdjustment:
-	default:
-		return nil, ErrInvalidInventoryType
+	if err := validateInventoryInput(input); err != nil {
+		return nil, err
 	}
 	if err := s.ensureTenantRecord(ctx, &domain.Product{}, tenantID, input.ProductID); err != nil {
 		return nil, err
@@ -72,21 +79,157 @@ func (s *Service) CreateInventoryMovement(ctx context.Context, tenantID uuid.UUI
 		}
 	}
 
+	var movement *domain.InventoryMovement
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		movement, err = s.applyInventoryMovement(tx, tenantID, input)
+		if err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityInventoryMovement, AuditActionCreate, movement.ID, nil, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// RebuildProductStock recalcula o saldo do produto a partir do histórico de movimentações.
+func (s *Service) RebuildProductStock(ctx context.Context, tenantID, productID uuid.UUID) (*StockRebuildResult, error) {
+	result := &StockRebuildResult{}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		product, err := lockProduct(tx, tenantID, productID)
+		if err != nil {
+			return err
+		}
+
+		var movements []domain.InventoryMovement
+		if err := tx.
+			Where("tenant_id = ? AND product_id = ?", tenantID, productID).
+			Order("created_at ASC, id ASC").
+			Find(&movements).Error; err != nil {
+			return err
+		}
+
+		balance := 0
+		for i := range movements {
+			balance = nextStockBalance(balance, movements[i].Type, movements[i].Quantity)
+			if movements[i].BalanceAfter == balance {
+				continue
+			}
+			if err := tx.Model(&domain.InventoryMovement{}).
+				Where("id = ?", movements[i].ID).
+				UpdateColumn("balance_after", balance).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&domain.Product{}).
+			Where("tenant_id = ? AND id = ?", tenantID, productID).
+			UpdateColumn("stock_qty", balance).Error; err != nil {
+			return err
+		}
+
+		result.PreviousQty = product.StockQty
+		result.RebuiltQty = balance
+		result.Movements = len(movements)
+		before := *product
+		product.StockQty = balance
+		result.Product = product
+		if before.StockQty == balance {
+			return nil
+		}
+		return s.audit(tx, tenantID, AuditEntityProduct, AuditActionUpdate, productID, before, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// applyInventoryMovement registra a movimentação e atualiza o saldo do produto
+// na mesma transação, mantendo a linha do produto bloqueada até o commit.
+func (s *Service) applyInventoryMovement(tx *gorm.DB, tenantID uuid.UUID, input InventoryInput) (*domain.InventoryMovement, error) {
+	product, err := lockProduct(tx, tenantID, input.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := nextStockBalance(product.StockQty, input.Type, input.Quantity)
+	flagged := false
+	if balance < 0 {
+		settings, err := s.companySettings(tx, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !settingBool(settings, domain.SettingAllowNegativeStock) {
+			return nil, ErrInsufficientStock
+		}
+		flagged = true
+	}
+
 	movement := &domain.InventoryMovement{
 		TenantModel: domain.TenantModel{
 			TenantID: tenantID,
 		},
-		ProductID: input.ProductID,
-		Type:      input.Type,
-		Quantity:  input.Quantity,
-		Reason:    input.Reason,
+		ProductID:    input.ProductID,
+		OrderID:      input.OrderID,
+		Type:         input.Type,
+		Quantity:     input.Quantity,
+		Reason:       input.Reason,
+		BalanceAfter: balance,
+		Flagged:      flagged,
 	}
-	if input.OrderID != nil {
-		movement.OrderID = input.OrderID
+	if err := tx.Create(movement).Error; err != nil {
+		return nil, err
 	}
 
-	if err := s.dbWithContext(ctx).Create(movement).Error; err != nil {
+	if err := tx.Model(&domain.Product{}).
+		Where("tenant_id = ? AND id = ?", tenantID, input.ProductID).
+		UpdateColumn("stock_qty", balance).Error; err != nil {
 		return nil, err
 	}
 	return movement, nil
 }
+
+func lockProduct(tx *gorm.DB, tenantID, productID uuid.UUID) (*domain.Product, error) {
+	var product domain.Product
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, productID).
+		First(&product).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// nextStockBalance aplica a movimentação ao saldo atual. Ajustes representam
+// contagem física e, por isso, substituem o saldo em vez de somá-lo.
+func nextStockBalance(current int, movementType string, quantity int) int {
+	switch movementType {
+	case domain.InventoryMovementIn:
+		return current + quantity
+	case domain.InventoryMovementOut:
+		return current - quantity
+	case domain.InventoryMovementAdjustment:
+		return quantity
+	default:
+		return current
+	}
+}
+
+func validateInventoryInput(input InventoryInput) error {
+	switch input.Type {
+	case domain.InventoryMovementIn, domain.InventoryMovementOut:
+		if input.Quantity <= 0 {
+			return errors.New("quantity must be greater than zero")
+		}
+	case domain.InventoryMovementAdjustment:
+		if input.Quantity < 0 {
+			return errors.New("quantity must not be negative")
+		}
+	default:
+		return ErrInvalidInventoryType
+	}
+	return nil
+}
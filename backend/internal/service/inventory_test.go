@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -104,6 +107,140 @@ func TestListInventoryMovementsFilters(t *testing.T) {
 	assert.Equal(t, domain.InventoryMovementOut, outMovements[0].Type)
 }
 
+func TestCreateInventoryMovementUpdatesStockBalance(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	product := seedProductRecord(t, tenant.ID, "Ledger Product", "INV-LEDGER")
+	ctx := context.Background()
+
+	in, err := testSvc.CreateInventoryMovement(ctx, tenant.ID, InventoryInput{
+		ProductID: product.ID,
+		Type:      domain.InventoryMovementIn,
+		Quantity:  10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, in.BalanceAfter)
+
+	out, err := testSvc.CreateInventoryMovement(ctx, tenant.ID, InventoryInput{
+		ProductID: product.ID,
+		Type:      domain.InventoryMovementOut,
+		Quantity:  3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, out.BalanceAfter)
+
+	adjustment, err := testSvc.CreateInventoryMovement(ctx, tenant.ID, InventoryInput{
+		ProductID: product.ID,
+		Type:      domain.InventoryMovementAdjustment,
+		Quantity:  4,
+		Reason:    "contagem física",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, adjustment.BalanceAfter)
+
+	reloaded, err := testSvc.GetProduct(ctx, tenant.ID, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, reloaded.StockQty)
+}
+
+func TestCreateInventoryMovementNegativeStockPolicy(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	product := seedProductRecord(t, tenant.ID, "Policy Product", "INV-POLICY")
+	seedInventoryStock(t, tenant.ID, product.ID, 2)
+	ctx := context.Background()
+
+	input := InventoryInput{
+		ProductID: product.ID,
+		Type:      domain.InventoryMovementOut,
+		Quantity:  5,
+	}
+	_, err := testSvc.CreateInventoryMovement(ctx, tenant.ID, input)
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	unchanged, err := testSvc.GetProduct(ctx, tenant.ID, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unchanged.StockQty)
+
+	require.NoError(t, testDB.Model(&domain.Company{}).
+		Where("id = ?", tenant.ID).
+		Update("settings", datatypes.JSONMap{domain.SettingAllowNegativeStock: true}).Error)
+
+	movement, err := testSvc.CreateInventoryMovement(ctx, tenant.ID, input)
+	require.NoError(t, err)
+	assert.True(t, movement.Flagged)
+	assert.Equal(t, -3, movement.BalanceAfter)
+}
+
+func TestCreateInventoryMovementConcurrentOutflows(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	product := seedProductRecord(t, tenant.ID, "Concurrent Product", "INV-CONC")
+	seedInventoryStock(t, tenant.ID, product.ID, 5)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		rejected  int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := testSvc.CreateInventoryMovement(context.Background(), tenant.ID, InventoryInput{
+				ProductID: product.ID,
+				Type:      domain.InventoryMovementOut,
+				Quantity:  1,
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			} else if assert.ErrorIs(t, err, ErrInsufficientStock) {
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, succeeded)
+	assert.Equal(t, 5, rejected)
+	final, err := testSvc.GetProduct(context.Background(), tenant.ID, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, final.StockQty)
+}
+
+func TestRebuildProductStockReplaysHistory(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	product, err := testSvc.CreateProduct(ctx, tenant.ID, ProductInput{
+		Name:     "Rebuild Product",
+		SKU:      "INV-REBUILD",
+		Price:    10,
+		StockQty: 8,
+	})
+	require.NoError(t, err)
+	_, err = testSvc.CreateInventoryMovement(ctx, tenant.ID, InventoryInput{
+		ProductID: product.ID,
+		Type:      domain.InventoryMovementOut,
+		Quantity:  3,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, testDB.Model(&domain.Product{}).
+		Where("id = ?", product.ID).
+		UpdateColumn("stock_qty", 42).Error)
+
+	result, err := testSvc.RebuildProductStock(ctx, tenant.ID, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 42, result.PreviousQty)
+	assert.Equal(t, 5, result.RebuiltQty)
+	assert.Equal(t, 2, result.Movements)
+	assert.Equal(t, 5, result.Product.StockQty)
+}
+
 func seedProductRecord(t *testing.T, tenantID uuid.UUID, name, sku string) *domain.Product {
 	t.Helper()
 	product := &domain.Product{
@@ -126,4 +263,7 @@ func seedInventoryStock(t *testing.T, tenantID, productID uuid.UUID, quantity in
 		Reason:      "seed stock",
 	}
 	require.NoError(t, testDB.Create(in).Error)
+	require.NoError(t, testDB.Model(&domain.Product{}).
+		Where("id = ?", productID).
+		UpdateColumn("stock_qty", gorm.Expr("stock_qty + ?", quantity)).Error)
 }
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/jobs"
+)
+
+// Tipos de tarefa processados pelo cmd/worker.
+const (
+	JobTypeExpireWaitlistOffers = "waitlist.expire_offers"
+	JobTypeDeliverWebhooks      = "webhooks.deliver"
+	JobTypeSendBookingReminders = "bookings.send_reminders"
+	JobTypePurgeLoginThrottles  = "auth.purge_login_throttles"
+)
+
+// PeriodicTask rotina periódica do service. Run recebe o instante da execução e devolve
+// quantos itens processou.
+type PeriodicTask struct {
+	Type     string
+	Interval time.Duration
+	Run      func(ctx context.Context, now time.Time) (int, error)
+}
+
+// PeriodicTasks lista as rotinas periódicas e seus intervalos, tanto para a fila do
+// cmd/worker quanto para os tickers do cmd/api.
+func (s *Service) PeriodicTasks() []PeriodicTask {
+	return []PeriodicTask{
+		{Type: JobTypeExpireWaitlistOffers, Interval: time.Minute, Run: s.ExpireWaitlistOffers},
+		{Type: JobTypeDeliverWebhooks, Interval: 10 * time.Second, Run: s.DeliverWebhooks},
+		{Type: JobTypeSendBookingReminders, Interval: time.Minute, Run: s.SendBookingReminders},
+		{Type: JobTypePurgeLoginThrottles, Interval: time.Hour, Run: s.PurgeLoginThrottles},
+	}
+}
+
+// RegisterJobs registra na fila as rotinas periódicas que, sem o worker, rodam em
+// tickers do cmd/api.
+func (s *Service) RegisterJobs(q *jobs.Queue) {
+	for _, task := range s.PeriodicTasks() {
+		s.registerPeriodicJob(q, task)
+	}
+}
+
+func (s *Service) registerPeriodicJob(q *jobs.Queue, task PeriodicTask) {
+	jobs.Register(q, task.Type, func(ctx context.Context, _ *domain.Job, _ struct{}) error {
+		count, err := task.Run(ctx, time.Now().UTC())
+		if count > 0 && s.logger != nil {
+			s.logger.Info("periodic job processed", zap.String("type", task.Type), zap.Int("count", count))
+		}
+		return err
+	})
+	q.Every(task.Type, task.Interval)
+}
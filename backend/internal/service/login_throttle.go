@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const (
+	defaultLoginMaxFailures   = 5
+	defaultLoginIPMaxFailures = 50
+	defaultLoginFailureWindow = 15 * time.Minute
+	defaultLoginLockout       = 15 * time.Minute
+
+	// A partir desta falha, cada nova tentativa espera o dobro da anterior, até loginMaxDelay.
+	loginDelayAfterFailures = 2
+	loginBaseDelay          = time.Second
+	loginMaxDelay           = 30 * time.Second
+)
+
+// ErrTooManyLoginAttempts sinaliza tentativa recusada pelo limitador; o erro concreto é *LoginThrottledError.
+var ErrTooManyLoginAttempts = errors.New("muitas tentativas de login; tente novamente mais tarde")
+
+// LoginThrottledError informa quanto tempo falta para a próxima tentativa e se o
+// e-mail ou IP está bloqueado (e não apenas em espera progressiva).
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string { return ErrTooManyLoginAttempts.Error() }
+
+func (e *LoginThrottledError) Unwrap() error { return ErrTooManyLoginAttempts }
+
+// loginLockAudit descreve o bloqueio no audit log do usuário.
+type loginLockAudit struct {
+	LockedUntil *time.Time `json:"locked_until"`
+	Failures    int        `json:"failures,omitempty"`
+	IPAddress   string     `json:"ip_address,omitempty"`
+}
+
+type loginThrottleKey struct {
+	scope       string
+	key         string
+	maxFailures int
+}
+
+// loginThrottleKeys devolve as chaves da tentativa: o e-mail e, quando conhecido, o IP do cliente.
+func (s *Service) loginThrottleKeys(ctx context.Context, email string) []loginThrottleKey {
+	keys := []loginThrottleKey{{
+		scope:       domain.LoginThrottleEmail,
+		key:         s.sanitizeEmail(email),
+		maxFailures: positiveOr(s.cfg.LoginMaxFailures, defaultLoginMaxFailures),
+	}}
+	if ip := auditActorFrom(ctx).IP; ip != "" {
+		keys = append(keys, loginThrottleKey{
+			scope:       domain.LoginThrottleIP,
+			key:         ip,
+			maxFailures: positiveOr(s.cfg.LoginIPMaxFailures, defaultLoginIPMaxFailures),
+		})
+	}
+	return keys
+}
+
+// loginRetryAfter calcula a espera imposta pela chave: o restante do bloqueio ou o
+// atraso progressivo desde a última falha dentro da janela.
+func (s *Service) loginRetryAfter(throttle *domain.LoginThrottle, now time.Time) (time.Duration, bool) {
+	if throttle.LockedUntil != nil {
+		if now.Before(*throttle.LockedUntil) {
+			return throttle.LockedUntil.Sub(now), true
+		}
+		return 0, false
+	}
+	if throttle.LastFailureAt == nil || now.Sub(*throttle.LastFailureAt) >= s.loginFailureWindow() {
+		return 0, false
+	}
+	return throttle.LastFailureAt.Add(loginDelay(throttle.Failures)).Sub(now), false
+}
+
+// loginAttempt tentativa de login reservada: já contada como falha em cada chave até
+// que o resultado seja conhecido.
+type loginAttempt struct {
+	keys       []loginThrottleKey
+	reservedAt time.Time
+	// lockedUntil é preenchido nas chaves bloqueadas por esta tentativa.
+	lockedUntil []*time.Time
+	// lastFailureAt guarda a última falha de cada chave antes da reserva.
+	lastFailureAt []*time.Time
+}
+
+// reserveLoginAttempt confere o limitador e contabiliza a tentativa como falha na mesma
+// transação, sob o lock das linhas. Assim, requisições simultâneas não passam todas
+// pela verificação antes de alguma falha ser registrada. A tentativa é devolvida com
+// releaseLoginAttempt quando a senha confere.
+func (s *Service) reserveLoginAttempt(ctx context.Context, email string, now time.Time) (*loginAttempt, error) {
+	keys := s.loginThrottleKeys(ctx, email)
+	attempt := &loginAttempt{
+		keys:          keys,
+		reservedAt:    now,
+		lockedUntil:   make([]*time.Time, len(keys)),
+		lastFailureAt: make([]*time.Time, len(keys)),
+	}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		throttles := make([]domain.LoginThrottle, len(keys))
+		var throttled *LoginThrottledError
+		for i, k := range keys {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&domain.LoginThrottle{Scope: k.scope, Key: k.key}).Error; err != nil {
+				return err
+			}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("scope = ? AND key = ?", k.scope, k.key).
+				First(&throttles[i]).Error; err != nil {
+				return err
+			}
+			wait, locked := s.loginRetryAfter(&throttles[i], now)
+			if wait > 0 && (throttled == nil || wait > throttled.RetryAfter) {
+				throttled = &LoginThrottledError{RetryAfter: wait, Locked: locked}
+			}
+		}
+		if throttled != nil {
+			return throttled
+		}
+
+		for i, k := range keys {
+			throttle := &throttles[i]
+			attempt.lastFailureAt[i] = throttle.LastFailureAt
+			// Bloqueio vencido ou janela expirada recomeçam a contagem.
+			expiredLock := throttle.LockedUntil != nil && !now.Before(*throttle.LockedUntil)
+			staleWindow := throttle.LastFailureAt == nil || now.Sub(*throttle.LastFailureAt) >= s.loginFailureWindow()
+			if expiredLock || staleWindow {
+				throttle.Failures = 0
+				throttle.LockedUntil = nil
+			}
+			throttle.Failures++
+			if throttle.LockedUntil == nil && throttle.Failures >= k.maxFailures {
+				until := now.Add(s.loginLockout())
+				throttle.LockedUntil = &until
+				attempt.lockedUntil[i] = &until
+			}
+			if err := tx.Model(throttle).Updates(map[string]interface{}{
+				"failures":        throttle.Failures,
+				"last_failure_at": now,
+				"locked_until":    throttle.LockedUntil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// releaseLoginAttempt devolve a tentativa reservada cujas credenciais conferiram,
+// desfazendo o bloqueio que ela tenha causado. A última falha volta ao valor anterior à
+// reserva, salvo se outra tentativa a tiver registrado depois, para que o acerto não
+// imponha espera progressiva à tentativa seguinte.
+func (s *Service) releaseLoginAttempt(ctx context.Context, attempt *loginAttempt) error {
+	db := s.dbWithContext(ctx)
+	for i, k := range attempt.keys {
+		updates := map[string]interface{}{
+			"failures": gorm.Expr("GREATEST(failures - 1, 0)"),
+			"last_failure_at": gorm.Expr("CASE WHEN last_failure_at = ? THEN CAST(? AS timestamptz) ELSE last_failure_at END",
+				attempt.reservedAt, attempt.lastFailureAt[i]),
+		}
+		if attempt.lockedUntil[i] != nil {
+			updates["locked_until"] = nil
+		}
+		if err := db.Model(&domain.LoginThrottle{}).
+			Where("scope = ? AND key = ?", k.scope, k.key).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failLoginAttempt confirma a falha reservada e registra os bloqueios causados por ela.
+// O bloqueio do e-mail é auditado nos usuários com esse e-mail.
+func (s *Service) failLoginAttempt(ctx context.Context, attempt *loginAttempt) error {
+	ip := auditActorFrom(ctx).IP
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		for i, k := range attempt.keys {
+			if attempt.lockedUntil[i] == nil {
+				continue
+			}
+			if s.logger != nil {
+				s.logger.Warn("login locked after repeated failures",
+					zap.String("scope", k.scope),
+					zap.String("key", k.key),
+					zap.Int("failures", k.maxFailures))
+			}
+			if k.scope != domain.LoginThrottleEmail {
+				continue
+			}
+			var users []domain.User
+			if err := tx.Where("lower(email) = ?", k.key).Find(&users).Error; err != nil {
+				return err
+			}
+			for _, user := range users {
+				if err := s.audit(tx, user.TenantID, AuditEntityUser, AuditActionLock, user.ID, nil, loginLockAudit{
+					LockedUntil: attempt.lockedUntil[i],
+					Failures:    k.maxFailures,
+					IPAddress:   ip,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// resetLoginFailures zera a contagem do e-mail após um login completo. A do IP é mantida,
+// para que um atacante não a zere entrando na própria conta.
+func (s *Service) resetLoginFailures(db *gorm.DB, email string) error {
+	return db.Unscoped().
+		Where("scope = ? AND key = ?", domain.LoginThrottleEmail, s.sanitizeEmail(email)).
+		Delete(&domain.LoginThrottle{}).Error
+}
+
+// UnlockUser remove o bloqueio de login do e-mail do usuário. Desbloqueios de contas
+// bloqueadas são auditados.
+func (s *Service) UnlockUser(ctx context.Context, tenantID, userID uuid.UUID) error {
+	now := time.Now().UTC()
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Where("tenant_id = ? AND id = ?", tenantID, userID).First(&user).Error; err != nil {
+			return err
+		}
+		var throttle domain.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ?", domain.LoginThrottleEmail, s.sanitizeEmail(user.Email)).
+			First(&throttle).Error; err != nil {
+			return ignoreNotFound(err)
+		}
+		if err := tx.Unscoped().Delete(&throttle).Error; err != nil {
+			return err
+		}
+		if throttle.LockedUntil == nil || !now.Before(*throttle.LockedUntil) {
+			return nil
+		}
+		return s.audit(tx, tenantID, AuditEntityUser, AuditActionUnlock, user.ID,
+			loginLockAudit{LockedUntil: throttle.LockedUntil}, loginLockAudit{})
+	})
+}
+
+// PurgeLoginThrottles remove contagens sem falhas recentes nem bloqueio ativo.
+func (s *Service) PurgeLoginThrottles(ctx context.Context, now time.Time) (int, error) {
+	result := s.dbWithContext(ctx).Unscoped().
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-s.loginFailureWindow()), now).
+		Delete(&domain.LoginThrottle{})
+	return int(result.RowsAffected), result.Error
+}
+
+// loginDelay devolve a espera exigida após failures falhas consecutivas.
+func loginDelay(failures int) time.Duration {
+	if failures < loginDelayAfterFailures {
+		return 0
+	}
+	delay := loginBaseDelay
+	for i := loginDelayAfterFailures; i < failures && delay < loginMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > loginMaxDelay {
+		delay = loginMaxDelay
+	}
+	return delay
+}
+
+func (s *Service) loginFailureWindow() time.Duration {
+	if s.cfg.LoginFailureWindow > 0 {
+		return s.cfg.LoginFailureWindow
+	}
+	return defaultLoginFailureWindow
+}
+
+func (s *Service) loginLockout() time.Duration {
+	if s.cfg.LoginLockoutDuration > 0 {
+		return s.cfg.LoginLockoutDuration
+	}
+	return defaultLoginLockout
+}
+
+func positiveOr(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
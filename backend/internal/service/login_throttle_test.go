@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/config"
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestLoginDelay(t *testing.T) {
+	assert.Zero(t, loginDelay(0))
+	assert.Zero(t, loginDelay(1))
+	assert.Equal(t, time.Second, loginDelay(2))
+	assert.Equal(t, 4*time.Second, loginDelay(4))
+	assert.Equal(t, loginMaxDelay, loginDelay(40))
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	svc := &Service{cfg: &config.Config{}}
+	now := time.Now().UTC()
+	lastFailure := now.Add(-500 * time.Millisecond)
+	lockedUntil := now.Add(time.Minute)
+	expired := now.Add(-time.Second)
+
+	wait, locked := svc.loginRetryAfter(&domain.LoginThrottle{Failures: 2, LastFailureAt: &lastFailure}, now)
+	assert.Equal(t, 500*time.Millisecond, wait)
+	assert.False(t, locked)
+
+	wait, locked = svc.loginRetryAfter(&domain.LoginThrottle{Failures: 5, LastFailureAt: &lastFailure, LockedUntil: &lockedUntil}, now)
+	assert.Equal(t, time.Minute, wait)
+	assert.True(t, locked)
+
+	wait, _ = svc.loginRetryAfter(&domain.LoginThrottle{Failures: 5, LastFailureAt: &lastFailure, LockedUntil: &expired}, now)
+	assert.Zero(t, wait, "bloqueio vencido libera a tentativa")
+
+	old := now.Add(-time.Hour)
+	wait, _ = svc.loginRetryAfter(&domain.LoginThrottle{Failures: 4, LastFailureAt: &old}, now)
+	assert.Zero(t, wait, "falhas fora da janela não atrasam")
+}
+
+// ageLoginFailures recua a última falha para dispensar a espera progressiva nos testes.
+func ageLoginFailures(t *testing.T) {
+	t.Helper()
+	require.NoError(t, testDB.Model(&domain.LoginThrottle{}).
+		Where("last_failure_at IS NOT NULL").
+		Update("last_failure_at", time.Now().UTC().Add(-time.Minute)).Error)
+}
+
+func requireThrottled(t *testing.T, err error) *LoginThrottledError {
+	t.Helper()
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "esperado bloqueio, obtido %v", err)
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	return throttled
+}
+
+func TestLoginLocksEmailAfterRepeatedFailures(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	svc.cfg.LoginMaxFailures = 3
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "locked@example.com")
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "198.51.100.10"})
+
+	_, _, err := svc.Login(ctx, user.Email, "errada-1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = svc.Login(ctx, user.Email, "errada-2")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// A partir da segunda falha, tentativas imediatas são recusadas sem conferir a senha.
+	_, _, err = svc.Login(ctx, user.Email, password)
+	throttled := requireThrottled(t, err)
+	assert.False(t, throttled.Locked)
+	assert.LessOrEqual(t, throttled.RetryAfter, time.Second)
+
+	ageLoginFailures(t)
+	_, _, err = svc.Login(ctx, user.Email, "errada-3")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	ageLoginFailures(t)
+	_, _, err = svc.Login(ctx, user.Email, password)
+	throttled = requireThrottled(t, err)
+	assert.True(t, throttled.Locked)
+	assert.Greater(t, throttled.RetryAfter, 10*time.Minute)
+
+	var lock domain.AuditLog
+	require.NoError(t, testDB.Where("entity_id = ? AND action = ?", user.ID, AuditActionLock).First(&lock).Error)
+	assert.Equal(t, tenant.ID, lock.TenantID)
+
+	require.NoError(t, svc.UnlockUser(context.Background(), tenant.ID, user.ID))
+	var unlocks int64
+	require.NoError(t, testDB.Model(&domain.AuditLog{}).Where("entity_id = ? AND action = ?", user.ID, AuditActionUnlock).Count(&unlocks).Error)
+	assert.Equal(t, int64(1), unlocks)
+
+	_, tokens, err := svc.Login(ctx, user.Email, password)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	// Sem bloqueio ativo, o desbloqueio não gera auditoria.
+	require.NoError(t, svc.UnlockUser(context.Background(), tenant.ID, user.ID))
+	require.NoError(t, testDB.Model(&domain.AuditLog{}).Where("entity_id = ? AND action = ?", user.ID, AuditActionUnlock).Count(&unlocks).Error)
+	assert.Equal(t, int64(1), unlocks)
+}
+
+func TestConcurrentLoginFailuresRespectLimit(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	svc.cfg.LoginMaxFailures = 2
+	tenant, _ := createTestTenant()
+	user, _ := seedAuthUser(t, svc, tenant.ID, "burst@example.com")
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "198.51.100.30"})
+
+	const attempts = 10
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = svc.Login(ctx, user.Email, "errada")
+		}(i)
+	}
+	wg.Wait()
+
+	// Cada tentativa é reservada antes da senha: só as duas primeiras chegam a conferi-la.
+	invalid := 0
+	for _, err := range errs {
+		if errors.Is(err, ErrInvalidCredentials) {
+			invalid++
+			continue
+		}
+		assert.True(t, requireThrottled(t, err).Locked)
+	}
+	assert.Equal(t, 2, invalid)
+
+	var throttle domain.LoginThrottle
+	require.NoError(t, testDB.Where("scope = ? AND key = ?", domain.LoginThrottleEmail, user.Email).First(&throttle).Error)
+	assert.Equal(t, 2, throttle.Failures)
+	require.NotNil(t, throttle.LockedUntil)
+
+	var locks int64
+	require.NoError(t, testDB.Model(&domain.AuditLog{}).Where("entity_id = ? AND action = ?", user.ID, AuditActionLock).Count(&locks).Error)
+	assert.Equal(t, int64(1), locks)
+}
+
+func TestLoginThrottlesByIPAcrossEmails(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	svc.cfg.LoginIPMaxFailures = 3
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "victim@example.com")
+	attacker := WithAuditActor(context.Background(), AuditActor{IP: "203.0.113.99"})
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, _, err := svc.Login(attacker, email, "qualquer")
+		assert.ErrorIs(t, err, ErrInvalidCredentials, "e-mails inexistentes também contam")
+		ageLoginFailures(t)
+	}
+
+	_, _, err := svc.Login(attacker, user.Email, password)
+	assert.True(t, requireThrottled(t, err).Locked)
+
+	other := WithAuditActor(context.Background(), AuditActor{IP: "192.0.2.1"})
+	_, _, err = svc.Login(other, user.Email, password)
+	require.NoError(t, err, "o bloqueio do IP não afeta outros clientes")
+}
+
+func TestSuccessfulLoginResetsEmailFailures(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "reset-failures@example.com")
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "198.51.100.20"})
+
+	_, _, err := svc.Login(ctx, user.Email, "errada")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = svc.Login(ctx, user.Email, password)
+	require.NoError(t, err)
+
+	var emailRows, ipRows int64
+	require.NoError(t, testDB.Model(&domain.LoginThrottle{}).Where("scope = ?", domain.LoginThrottleEmail).Count(&emailRows).Error)
+	require.NoError(t, testDB.Model(&domain.LoginThrottle{}).Where("scope = ?", domain.LoginThrottleIP).Count(&ipRows).Error)
+	assert.Zero(t, emailRows)
+	assert.Equal(t, int64(1), ipRows, "a contagem do IP é mantida")
+
+	purged, err := svc.PurgeLoginThrottles(context.Background(), time.Now().UTC().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestSuccessfulLoginDoesNotDelayNextAttempt(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "back-to-back@example.com")
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "198.51.100.40"})
+
+	// Duas falhas antigas deixam o IP no limiar da espera progressiva.
+	for _, email := range []string{"x@example.com", "y@example.com"} {
+		_, _, err := svc.Login(ctx, email, "qualquer")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		ageLoginFailures(t)
+	}
+
+	_, _, err := svc.Login(ctx, user.Email, password)
+	require.NoError(t, err)
+	_, tokens, err := svc.Login(ctx, user.Email, password)
+	require.NoError(t, err, "o acerto anterior não conta como falha recente")
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	var throttle domain.LoginThrottle
+	require.NoError(t, testDB.Where("scope = ? AND key = ?", domain.LoginThrottleIP, "198.51.100.40").First(&throttle).Error)
+	assert.Equal(t, 2, throttle.Failures)
+	require.NotNil(t, throttle.LastFailureAt)
+	assert.True(t, throttle.LastFailureAt.Before(time.Now().Add(-30*time.Second)))
+}
@@ -27,12 +27,26 @@ var (
 		&domain.Service{},
 		&domain.Product{},
 		&domain.AvailabilityRule{},
+		&domain.AvailabilityException{},
 		&domain.Booking{},
+		&domain.BookingSegment{},
+		&domain.WaitlistEntry{},
 		&domain.SalesOrder{},
 		&domain.SalesItem{},
 		&domain.Payment{},
+		&domain.Refund{},
 		&domain.InventoryMovement{},
 		&domain.AuditLog{},
+		&domain.WebhookSubscription{},
+		&domain.WebhookDelivery{},
+		&domain.BookingReminder{},
+		&domain.EmailTemplate{},
+		&domain.EmailTemplateVersion{},
+		&domain.UserSession{},
+		&domain.RefreshToken{},
+		&domain.UserToken{},
+		&domain.UserRecoveryCode{},
+		&domain.LoginThrottle{},
 	}
 )
 
@@ -133,6 +147,20 @@ func createTestTenant() (*domain.Company, error) {
 func clearAllData() {
 	tables := []string{
 		"availability_rules",
+		"availability_exceptions",
+		"waitlist_entries",
+		"booking_segments",
+		"booking_reminders",
+		"email_template_versions",
+		"email_templates",
+		"refresh_tokens",
+		"user_sessions",
+		"user_tokens",
+		"user_recovery_codes",
+		"login_throttles",
+		"webhook_deliveries",
+		"webhook_subscriptions",
+		"refunds",
 		"payments",
 		"sales_items",
 		"sales_orders",
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 6
+)
+
+var (
+	// ErrMFARequired sinaliza que o login depende do segundo fator; o erro concreto é *MFAChallenge.
+	ErrMFARequired        = errors.New("verificação em duas etapas necessária")
+	ErrInvalidMFACode     = errors.New("código de verificação inválido")
+	ErrMFAAlreadyEnabled  = errors.New("verificação em duas etapas já está ativa")
+	ErrMFANotEnabled      = errors.New("verificação em duas etapas não está ativa")
+	ErrMFASetupNotStarted = errors.New("cadastro do autenticador não iniciado")
+	ErrMFAEnforced        = errors.New("a empresa exige verificação em duas etapas para administradores")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAChallenge é devolvido pelo Login, como erro, quando a senha confere mas falta o
+// segundo fator. SetupRequired indica administrador sem autenticador em tenant que exige MFA.
+type MFAChallenge struct {
+	Token         string `json:"mfa_token"`
+	ExpiresIn     int64  `json:"expires_in"`
+	SetupRequired bool   `json:"setup_required"`
+}
+
+func (c *MFAChallenge) Error() string { return ErrMFARequired.Error() }
+
+func (c *MFAChallenge) Unwrap() error { return ErrMFARequired }
+
+// MFASetup dados para cadastrar o autenticador; URI é exibida como QR code.
+type MFASetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_url"`
+}
+
+// VerifyMFA conclui o login do desafio com um código TOTP ou de recuperação.
+// Códigos errados contam como falhas de login do e-mail e do IP.
+func (s *Service) VerifyMFA(ctx context.Context, mfaToken, code string) (*domain.User, *auth.TokenPair, error) {
+	var user *domain.User
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		user, err = s.lockChallengeUser(tx, mfaToken)
+		if err != nil {
+			return err
+		}
+		if user.MFAEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		return s.throttleSecondFactor(ctx, user, func() error {
+			return checkSecondFactor(tx, user, code)
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// SetupMFAWithChallenge inicia o cadastro do autenticador durante o login, para
+// administradores obrigados a usar MFA que ainda não o configuraram.
+func (s *Service) SetupMFAWithChallenge(ctx context.Context, mfaToken string) (*MFASetup, error) {
+	var setup *MFASetup
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := s.lockChallengeUser(tx, mfaToken)
+		if err != nil {
+			return err
+		}
+		setup, err = s.beginMFASetup(tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// EnableMFAWithChallenge confirma o autenticador cadastrado no login e conclui a autenticação.
+func (s *Service) EnableMFAWithChallenge(ctx context.Context, mfaToken, code string) (*auth.TokenPair, []string, error) {
+	var (
+		user  *domain.User
+		codes []string
+	)
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		user, err = s.lockChallengeUser(tx, mfaToken)
+		if err != nil {
+			return err
+		}
+		return s.throttleSecondFactor(ctx, user, func() error {
+			codes, err = s.enableMFA(tx, user, code)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := s.completeLogin(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, codes, nil
+}
+
+// StartMFASetup gera um novo segredo TOTP pendente para o usuário autenticado.
+func (s *Service) StartMFASetup(ctx context.Context, tenantID, userID uuid.UUID) (*MFASetup, error) {
+	var setup *MFASetup
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := lockUser(tx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		setup, err = s.beginMFASetup(tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// EnableMFA ativa o segredo pendente após conferir um código do autenticador e
+// devolve os códigos de recuperação, exibidos apenas nesta resposta.
+func (s *Service) EnableMFA(ctx context.Context, tenantID, userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := lockUser(tx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		return s.throttleSecondFactor(ctx, user, func() error {
+			codes, err = s.enableMFA(tx, user, code)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA desativa o MFA mediante um código válido. Administradores de tenants que
+// exigem MFA não podem desativá-lo. Códigos errados contam como falhas de login do e-mail.
+func (s *Service) DisableMFA(ctx context.Context, tenantID, userID uuid.UUID, code string) error {
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := lockUser(tx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		if user.MFAEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		enforced, err := s.mfaEnforced(tx, user)
+		if err != nil {
+			return err
+		}
+		if enforced {
+			return ErrMFAEnforced
+		}
+		if err := s.throttleSecondFactor(ctx, user, func() error {
+			return checkSecondFactor(tx, user, code)
+		}); err != nil {
+			return err
+		}
+
+		before := *user
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"mfa_secret":     "",
+			"mfa_enabled_at": nil,
+			"mfa_last_step":  0,
+		}).Error; err != nil {
+			return err
+		}
+		user.MFASecret = ""
+		user.MFAEnabledAt = nil
+		user.MFALastStep = 0
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&domain.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, user.TenantID, AuditEntityUser, AuditActionUpdate, user.ID, before, user)
+	})
+}
+
+// RegenerateRecoveryCodes substitui os códigos de recuperação mediante um código válido.
+// Códigos errados contam como falhas de login do e-mail.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, tenantID, userID uuid.UUID, code string) ([]string, error) {
+	var codes []string
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := lockUser(tx, tenantID, userID)
+		if err != nil {
+			return err
+		}
+		if user.MFAEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		if err := s.throttleSecondFactor(ctx, user, func() error {
+			return checkSecondFactor(tx, user, code)
+		}); err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// mfaChallenge decide se o login precisa do segundo fator e, nesse caso, emite o desafio.
+func (s *Service) mfaChallenge(db *gorm.DB, user *domain.User) (*MFAChallenge, error) {
+	setupRequired := false
+	if user.MFAEnabledAt == nil {
+		enforced, err := s.mfaEnforced(db, user)
+		if err != nil || !enforced {
+			return nil, err
+		}
+		setupRequired = true
+	}
+	token, err := s.jwt.GenerateMFAToken(user.ID.String(), user.TenantID.String(), user.Role)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAChallenge{
+		Token:         token,
+		ExpiresIn:     int64(s.jwt.MFATokenTTL().Seconds()),
+		SetupRequired: setupRequired,
+	}, nil
+}
+
+// mfaEnforced indica se o tenant exige MFA para o papel do usuário.
+func (s *Service) mfaEnforced(db *gorm.DB, user *domain.User) (bool, error) {
+	if user.Role != domain.UserRoleAdmin {
+		return false, nil
+	}
+	settings, err := s.companySettings(db, user.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return settingBool(settings, domain.SettingRequireAdminMFA), nil
+}
+
+func (s *Service) lockChallengeUser(tx *gorm.DB, mfaToken string) (*domain.User, error) {
+	claims, err := s.jwt.ValidateMFAToken(mfaToken)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	user, err := lockUser(tx, tenantID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func lockUser(tx *gorm.DB, tenantID, userID uuid.UUID) (*domain.User, error) {
+	var user domain.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND id = ?", tenantID, userID).
+		First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// beginMFASetup grava um segredo pendente, substituindo cadastros não concluídos.
+func (s *Service) beginMFASetup(tx *gorm.DB, user *domain.User) (*MFASetup, error) {
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Model(user).Update("mfa_secret", secret).Error; err != nil {
+		return nil, err
+	}
+	user.MFASecret = secret
+
+	var company domain.Company
+	if err := tx.Select("id", "name").First(&company, "id = ?", user.TenantID).Error; err != nil {
+		return nil, err
+	}
+	return &MFASetup{
+		Secret: secret,
+		URI:    auth.TOTPProvisioningURI(company.Name, user.Email, secret),
+	}, nil
+}
+
+func (s *Service) enableMFA(tx *gorm.DB, user *domain.User, code string) ([]string, error) {
+	if user.MFAEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFASetupNotStarted
+	}
+	now := time.Now().UTC()
+	step, ok := auth.ValidateTOTP(user.MFASecret, code, now)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	before := *user
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"mfa_enabled_at": now,
+		"mfa_last_step":  step,
+	}).Error; err != nil {
+		return nil, err
+	}
+	user.MFAEnabledAt = &now
+	user.MFALastStep = step
+	if err := s.audit(tx, user.TenantID, AuditEntityUser, AuditActionUpdate, user.ID, before, user); err != nil {
+		return nil, err
+	}
+	return replaceRecoveryCodes(tx, user)
+}
+
+// throttleSecondFactor executa a conferência do código sob o limitador de login do e-mail
+// do usuário: a tentativa é reservada antes, confirmada como falha se o código não
+// confere e devolvida nos demais casos. O limitador grava fora da transação de check,
+// para que a falha valha mesmo com ela desfeita.
+func (s *Service) throttleSecondFactor(ctx context.Context, user *domain.User, check func() error) error {
+	attempt, err := s.reserveLoginAttempt(ctx, user.Email, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	checkErr := check()
+	if errors.Is(checkErr, ErrInvalidMFACode) {
+		if err := s.failLoginAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		return checkErr
+	}
+	if err := s.releaseLoginAttempt(ctx, attempt); err != nil {
+		return err
+	}
+	return checkErr
+}
+
+// checkSecondFactor aceita um código TOTP ainda não usado ou um código de recuperação,
+// que é consumido.
+func checkSecondFactor(tx *gorm.DB, user *domain.User, code string) error {
+	now := time.Now().UTC()
+	if user.MFASecret == "" {
+		return ErrInvalidMFACode
+	}
+	if step, ok := auth.ValidateTOTP(user.MFASecret, code, now); ok {
+		if step <= user.MFALastStep {
+			return ErrInvalidMFACode
+		}
+		user.MFALastStep = step
+		return tx.Model(user).Update("mfa_last_step", step).Error
+	}
+
+	result := tx.Model(&domain.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes descarta os códigos anteriores e devolve os novos em claro.
+func replaceRecoveryCodes(tx *gorm.DB, user *domain.User) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&domain.UserRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]domain.UserRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := recoveryCodeEncoding.EncodeToString(buf)
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		records = append(records, domain.UserRecoveryCode{
+			TenantModel: domain.TenantModel{TenantID: user.TenantID},
+			UserID:      user.ID,
+			CodeHash:    hashRecoveryCode(code),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ignora caixa, espaços e hífens digitados pelo usuário.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return hashUserToken(normalized)
+}
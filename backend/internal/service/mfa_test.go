@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// totpCode gera o código do autenticador no passo informado.
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret, step)
+	require.NoError(t, err)
+	return code
+}
+
+func requireMFAChallenge(t *testing.T, err error) *MFAChallenge {
+	t.Helper()
+	var challenge *MFAChallenge
+	require.True(t, errors.As(err, &challenge), "esperado desafio MFA, obtido %v", err)
+	assert.ErrorIs(t, err, ErrMFARequired)
+	return challenge
+}
+
+func TestMFAEnrollmentAndLogin(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "mfa@example.com")
+	ctx := context.Background()
+
+	_, err := svc.EnableMFA(ctx, tenant.ID, user.ID, "123456")
+	assert.ErrorIs(t, err, ErrMFASetupNotStarted)
+
+	setup, err := svc.StartMFASetup(ctx, tenant.ID, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.URI, "otpauth://totp/Test%20Tenant:mfa@example.com")
+
+	_, err = svc.EnableMFA(ctx, tenant.ID, user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	step := auth.TOTPStep(time.Now())
+	codes, err := svc.EnableMFA(ctx, tenant.ID, user.ID, totpCode(t, setup.Secret, step))
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	_, err = svc.StartMFASetup(ctx, tenant.ID, user.ID)
+	assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+
+	_, tokens, err := svc.Login(ctx, user.Email, password)
+	assert.Nil(t, tokens)
+	challenge := requireMFAChallenge(t, err)
+	assert.False(t, challenge.SetupRequired)
+	assert.Equal(t, int64(300), challenge.ExpiresIn)
+
+	_, err = svc.jwt.ValidateAccessToken(challenge.Token)
+	assert.Error(t, err, "o desafio não serve como access token")
+
+	_, _, err = svc.VerifyMFA(ctx, challenge.Token, totpCode(t, setup.Secret, step))
+	assert.ErrorIs(t, err, ErrInvalidMFACode, "o código usado na ativação não é aceito de novo")
+	// Com o código errado da ativação, são duas falhas: dispensa a espera progressiva.
+	ageLoginFailures(t)
+
+	_, tokens, err = svc.VerifyMFA(ctx, challenge.Token, totpCode(t, setup.Secret, step+1))
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, tokens, err = svc.VerifyMFA(ctx, challenge.Token, codes[0])
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	_, _, err = svc.VerifyMFA(ctx, challenge.Token, codes[0])
+	assert.ErrorIs(t, err, ErrInvalidMFACode, "código de recuperação vale uma vez")
+
+	_, _, err = svc.VerifyMFA(ctx, "token-invalido", codes[1])
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestRecoveryCodesRegenerateAndDisable(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "recovery@example.com")
+	ctx := context.Background()
+
+	setup, err := svc.StartMFASetup(ctx, tenant.ID, user.ID)
+	require.NoError(t, err)
+	oldCodes, err := svc.EnableMFA(ctx, tenant.ID, user.ID, totpCode(t, setup.Secret, auth.TOTPStep(time.Now())))
+	require.NoError(t, err)
+
+	newCodes, err := svc.RegenerateRecoveryCodes(ctx, tenant.ID, user.ID, oldCodes[0])
+	require.NoError(t, err)
+	require.Len(t, newCodes, recoveryCodeCount)
+
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, oldCodes[1])
+	assert.ErrorIs(t, err, ErrInvalidMFACode, "códigos anteriores deixam de valer")
+
+	// Caixa e hífen são ignorados na digitação.
+	require.NoError(t, svc.DisableMFA(ctx, tenant.ID, user.ID, " "+strings.ToLower(strings.ReplaceAll(newCodes[0], "-", ""))))
+
+	var stored domain.User
+	require.NoError(t, testDB.First(&stored, "id = ?", user.ID).Error)
+	assert.Nil(t, stored.MFAEnabledAt)
+	assert.Empty(t, stored.MFASecret)
+	var remaining int64
+	require.NoError(t, testDB.Model(&domain.UserRecoveryCode{}).Where("user_id = ?", user.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+
+	_, tokens, err := svc.Login(ctx, user.Email, password)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, "123456")
+	assert.ErrorIs(t, err, ErrMFANotEnabled)
+}
+
+func TestTenantRequiresMFAForAdmins(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	require.NoError(t, testDB.Model(tenant).Update("settings", datatypes.JSONMap{domain.SettingRequireAdminMFA: true}).Error)
+	admin, adminPassword := seedAuthUser(t, svc, tenant.ID, "admin-mfa@example.com")
+	require.NoError(t, testDB.Model(admin).Update("role", domain.UserRoleAdmin).Error)
+	member, memberPassword := seedAuthUser(t, svc, tenant.ID, "member-mfa@example.com")
+	ctx := context.Background()
+
+	_, tokens, err := svc.Login(ctx, member.Email, memberPassword)
+	require.NoError(t, err, "a exigência vale apenas para administradores")
+	assert.NotEmpty(t, tokens.AccessToken)
+
+	_, _, err = svc.Login(ctx, admin.Email, adminPassword)
+	challenge := requireMFAChallenge(t, err)
+	assert.True(t, challenge.SetupRequired)
+
+	_, _, err = svc.VerifyMFA(ctx, challenge.Token, "123456")
+	assert.ErrorIs(t, err, ErrMFANotEnabled)
+
+	setup, err := svc.SetupMFAWithChallenge(ctx, challenge.Token)
+	require.NoError(t, err)
+	tokens, codes, err := svc.EnableMFAWithChallenge(ctx, challenge.Token, totpCode(t, setup.Secret, auth.TOTPStep(time.Now())))
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	err = svc.DisableMFA(ctx, tenant.ID, admin.ID, codes[0])
+	assert.ErrorIs(t, err, ErrMFAEnforced)
+
+	_, _, err = svc.Login(ctx, admin.Email, adminPassword)
+	challenge = requireMFAChallenge(t, err)
+	assert.False(t, challenge.SetupRequired)
+}
+
+func TestMFACodeChecksShareLoginThrottle(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	svc.cfg.LoginMaxFailures = 3
+	tenant, _ := createTestTenant()
+	user, _ := seedAuthUser(t, svc, tenant.ID, "mfa-throttle@example.com")
+	ctx := context.Background()
+
+	setup, err := svc.StartMFASetup(ctx, tenant.ID, user.ID)
+	require.NoError(t, err)
+	step := auth.TOTPStep(time.Now())
+	_, err = svc.EnableMFA(ctx, tenant.ID, user.ID, totpCode(t, setup.Secret, step))
+	require.NoError(t, err)
+
+	// Com um access token em mãos, os códigos ainda não podem ser testados à vontade.
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	_, err = svc.RegenerateRecoveryCodes(ctx, tenant.ID, user.ID, "111111")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, "222222")
+	throttled := requireThrottled(t, err)
+	assert.False(t, throttled.Locked)
+
+	ageLoginFailures(t)
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, "333333")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+	ageLoginFailures(t)
+	err = svc.DisableMFA(ctx, tenant.ID, user.ID, totpCode(t, setup.Secret, step+1))
+	assert.True(t, requireThrottled(t, err).Locked, "o bloqueio recusa até o código correto")
+	_, err = svc.RegenerateRecoveryCodes(ctx, tenant.ID, user.ID, totpCode(t, setup.Secret, step+1))
+	assert.True(t, requireThrottled(t, err).Locked)
+
+	var stored domain.User
+	require.NoError(t, testDB.First(&stored, "id = ?", user.ID).Error)
+	assert.NotNil(t, stored.MFAEnabledAt)
+}
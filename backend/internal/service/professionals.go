@@ -2,12 +2,36 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sort"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
 
+// ProfessionalInput payload de criação/edição de profissionais.
+type ProfessionalInput struct {
+	UserID      *uuid.UUID
+	Name        string
+	Specialties []string
+	MaxParallel int
+	Active      *bool
+}
+
+// AvailabilityRuleInput janela semanal de atendimento.
+type AvailabilityRuleInput struct {
+	Weekday   int
+	StartTime string
+	EndTime   string
+}
+
+var (
+	ErrProfessionalUserTaken   = errors.New("usuário já vinculado a outro profissional")
+	ErrInvalidAvailabilityRule = errors.New("regra de disponibilidade inválida")
+)
+
 // ListProfessionals retorna profissionais ativos.
 func (s *Service) ListProfessionals(ctx context.Context, tenantID uuid.UUID) ([]domain.Professional, error) {
 	var professionals []domain.Professional
@@ -20,3 +44,237 @@ func (s *Service) ListProfessionals(ctx context.Context, tenantID uuid.UUID) ([]
 	}
 	return professionals, nil
 }
+
+func (s *Service) GetProfessional(ctx context.Context, tenantID, professionalID uuid.UUID) (*domain.Professional, error) {
+	var professional domain.Professional
+	if err := s.dbWithContext(ctx).
+		Preload("Availability", func(db *gorm.DB) *gorm.DB {
+			return db.Order("weekday ASC, start_time ASC")
+		}).
+		Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+		First(&professional).Error; err != nil {
+		return nil, err
+	}
+	return &professional, nil
+}
+
+func (s *Service) CreateProfessional(ctx context.Context, tenantID uuid.UUID, input ProfessionalInput) (*domain.Professional, error) {
+	if err := s.ensureProfessionalUser(ctx, tenantID, input.UserID, nil); err != nil {
+		return nil, err
+	}
+
+	professional := &domain.Professional{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		UserID:      input.UserID,
+		Name:        input.Name,
+		Specialties: marshalTags(input.Specialties),
+		MaxParallel: input.MaxParallel,
+		Active:      true,
+	}
+	if professional.MaxParallel < 1 {
+		professional.MaxParallel = 1
+	}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(professional).Error; err != nil {
+			return err
+		}
+		// Active tem default no banco; o zero value precisa ser gravado explicitamente.
+		if input.Active != nil && !*input.Active {
+			if err := tx.Model(professional).Update("active", false).Error; err != nil {
+				return err
+			}
+		}
+		return s.audit(tx, tenantID, AuditEntityProfessional, AuditActionCreate, professional.ID, nil, professional)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProfessional(ctx, tenantID, professional.ID)
+}
+
+func (s *Service) UpdateProfessional(ctx context.Context, tenantID, professionalID uuid.UUID, input ProfessionalInput) (*domain.Professional, error) {
+	var before domain.Professional
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+		First(&before).Error; err != nil {
+		return nil, err
+	}
+	if err := s.ensureProfessionalUser(ctx, tenantID, input.UserID, &professionalID); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"user_id":      input.UserID,
+		"name":         input.Name,
+		"specialties":  marshalTags(input.Specialties),
+		"max_parallel": input.MaxParallel,
+	}
+	if input.MaxParallel < 1 {
+		updates["max_parallel"] = 1
+	}
+	if input.Active != nil {
+		updates["active"] = *input.Active
+	}
+
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.Professional{}).
+			Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		var after domain.Professional
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+			First(&after).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityProfessional, AuditActionUpdate, professionalID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProfessional(ctx, tenantID, professionalID)
+}
+
+// DeactivateProfessional remove o profissional da agenda sem apagar o histórico.
+func (s *Service) DeactivateProfessional(ctx context.Context, tenantID, professionalID uuid.UUID) error {
+	var before domain.Professional
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+		First(&before).Error; err != nil {
+		return err
+	}
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.Professional{}).
+			Where("tenant_id = ? AND id = ?", tenantID, professionalID).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+		after := before
+		after.Active = false
+		return s.audit(tx, tenantID, AuditEntityProfessional, AuditActionUpdate, professionalID, before, after)
+	})
+}
+
+// ReplaceAvailability substitui atomicamente o conjunto semanal de regras do profissional.
+func (s *Service) ReplaceAvailability(ctx context.Context, tenantID, professionalID uuid.UUID, inputs []AvailabilityRuleInput) ([]domain.AvailabilityRule, error) {
+	if err := validateAvailabilityRules(inputs); err != nil {
+		return nil, err
+	}
+	if err := s.ensureTenantRecord(ctx, &domain.Professional{}, tenantID, professionalID); err != nil {
+		return nil, err
+	}
+
+	rules := make([]domain.AvailabilityRule, len(inputs))
+	for i, input := range inputs {
+		rules[i] = domain.AvailabilityRule{
+			TenantModel: domain.TenantModel{
+				TenantID: tenantID,
+			},
+			ProfessionalID: professionalID,
+			Weekday:        input.Weekday,
+			StartTime:      input.StartTime,
+			EndTime:        input.EndTime,
+		}
+	}
+
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var previous []domain.AvailabilityRule
+		if err := tx.
+			Where("tenant_id = ? AND professional_id = ?", tenantID, professionalID).
+			Order("weekday ASC, start_time ASC").
+			Find(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND professional_id = ?", tenantID, professionalID).
+			Delete(&domain.AvailabilityRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) > 0 {
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+		return s.audit(tx, tenantID, AuditEntityAvailabilityRules, AuditActionUpdate, professionalID,
+			auditedAvailability(previous), auditedAvailability(rules))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// auditedAvailability resume as regras semanais para o diff da auditoria, ignorando
+// IDs que mudam a cada substituição.
+func auditedAvailability(rules []domain.AvailabilityRule) map[string]interface{} {
+	windows := make([]map[string]interface{}, len(rules))
+	for i, rule := range rules {
+		windows[i] = map[string]interface{}{
+			"weekday":    rule.Weekday,
+			"start_time": rule.StartTime,
+			"end_time":   rule.EndTime,
+		}
+	}
+	return map[string]interface{}{"rules": windows}
+}
+
+func (s *Service) ensureProfessionalUser(ctx context.Context, tenantID uuid.UUID, userID, professionalID *uuid.UUID) error {
+	if userID == nil {
+		return nil
+	}
+	if err := s.ensureTenantRecord(ctx, &domain.User{}, tenantID, *userID); err != nil {
+		return err
+	}
+
+	query := s.dbWithContext(ctx).
+		Model(&domain.Professional{}).
+		Where("tenant_id = ? AND user_id = ?", tenantID, *userID)
+	if professionalID != nil {
+		query = query.Where("id <> ?", *professionalID)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrProfessionalUserTaken
+	}
+	return nil
+}
+
+// validateAvailabilityRules exige horários válidos e janelas sem sobreposição no mesmo dia.
+func validateAvailabilityRules(inputs []AvailabilityRuleInput) error {
+	type window struct {
+		start, end int64
+	}
+	byWeekday := map[int][]window{}
+	for _, input := range inputs {
+		if input.Weekday < 0 || input.Weekday > 6 {
+			return ErrInvalidAvailabilityRule
+		}
+		start, err := parseClock(input.StartTime)
+		if err != nil {
+			return ErrInvalidAvailabilityRule
+		}
+		end, err := parseClock(input.EndTime)
+		if err != nil || end <= start {
+			return ErrInvalidAvailabilityRule
+		}
+		byWeekday[input.Weekday] = append(byWeekday[input.Weekday], window{start: int64(start), end: int64(end)})
+	}
+
+	for _, windows := range byWeekday {
+		sort.Slice(windows, func(i, j int) bool { return windows[i].start < windows[j].start })
+		for i := 1; i < len(windows); i++ {
+			if windows[i].start < windows[i-1].end {
+				return ErrInvalidAvailabilityRule
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestValidateAvailabilityRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []AvailabilityRuleInput
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"split day", []AvailabilityRuleInput{{1, "09:00", "12:00"}, {1, "13:00", "18:00"}}, false},
+		{"adjacent windows", []AvailabilityRuleInput{{2, "09:00", "12:00"}, {2, "12:00", "15:00"}}, false},
+		{"same window other days", []AvailabilityRuleInput{{1, "09:00", "18:00"}, {2, "09:00", "18:00"}}, false},
+		{"overlap", []AvailabilityRuleInput{{1, "09:00", "12:00"}, {1, "11:00", "15:00"}}, true},
+		{"end before start", []AvailabilityRuleInput{{1, "18:00", "09:00"}}, true},
+		{"invalid clock", []AvailabilityRuleInput{{1, "9h", "18:00"}}, true},
+		{"invalid weekday", []AvailabilityRuleInput{{7, "09:00", "18:00"}}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateAvailabilityRules(tt.rules)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidAvailabilityRule, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
+
+func TestProfessionalLifecycle(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	user, err := seedUserRecord(t, tenant.ID, "Pro User", "pro@example.com", "password", domain.UserRoleUser)
+	require.NoError(t, err)
+
+	pro, err := testSvc.CreateProfessional(ctx, tenant.ID, ProfessionalInput{
+		UserID:      &user.ID,
+		Name:        "Ana",
+		Specialties: []string{"Barbearia"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pro.MaxParallel)
+	assert.True(t, pro.Active)
+
+	_, err = testSvc.CreateProfessional(ctx, tenant.ID, ProfessionalInput{UserID: &user.ID, Name: "Outra"})
+	assert.ErrorIs(t, err, ErrProfessionalUserTaken)
+
+	updated, err := testSvc.UpdateProfessional(ctx, tenant.ID, pro.ID, ProfessionalInput{
+		UserID:      &user.ID,
+		Name:        "Ana Souza",
+		MaxParallel: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Ana Souza", updated.Name)
+	assert.Equal(t, 2, updated.MaxParallel)
+
+	rules, err := testSvc.ReplaceAvailability(ctx, tenant.ID, pro.ID, []AvailabilityRuleInput{
+		{Weekday: 1, StartTime: "09:00", EndTime: "18:00"},
+		{Weekday: 2, StartTime: "09:00", EndTime: "18:00"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	_, err = testSvc.ReplaceAvailability(ctx, tenant.ID, pro.ID, []AvailabilityRuleInput{
+		{Weekday: 3, StartTime: "10:00", EndTime: "16:00"},
+		{Weekday: 3, StartTime: "15:00", EndTime: "17:00"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidAvailabilityRule)
+
+	_, err = testSvc.ReplaceAvailability(ctx, tenant.ID, pro.ID, []AvailabilityRuleInput{
+		{Weekday: 3, StartTime: "10:00", EndTime: "16:00"},
+	})
+	require.NoError(t, err)
+
+	loaded, err := testSvc.GetProfessional(ctx, tenant.ID, pro.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Availability, 1)
+	assert.Equal(t, 3, loaded.Availability[0].Weekday)
+
+	require.NoError(t, testSvc.DeactivateProfessional(ctx, tenant.ID, pro.ID))
+	active, err := testSvc.ListProfessionals(ctx, tenant.ID)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// RefundInput dados para estornar um pagamento. Amount zero estorna o saldo restante do pagamento.
+type RefundInput struct {
+	PaymentID  uuid.UUID
+	Amount     float64
+	Reason     string
+	RefundedAt time.Time
+}
+
+var (
+	ErrInvalidRefundAmount  = errors.New("valor do estorno inválido")
+	ErrRefundExceedsPayment = errors.New("estorno excede o valor disponível do pagamento")
+)
+
+// RefundPayment registra o estorno vinculado ao pagamento original e abate o valor
+// do saldo pago do pedido, ajustando o status conforme refundedOrderStatus.
+func (s *Service) RefundPayment(ctx context.Context, tenantID, orderID uuid.UUID, input RefundInput) (*domain.Refund, error) {
+	if input.Amount < 0 {
+		return nil, ErrInvalidRefundAmount
+	}
+	if input.RefundedAt.IsZero() {
+		input.RefundedAt = time.Now().UTC()
+	}
+
+	refund := &domain.Refund{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		OrderID:    orderID,
+		PaymentID:  input.PaymentID,
+		Reason:     input.Reason,
+		RefundedAt: input.RefundedAt,
+	}
+
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var order domain.SalesOrder
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			First(&order).Error; err != nil {
+			return err
+		}
+
+		var payment domain.Payment
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND order_id = ? AND id = ?", tenantID, orderID, input.PaymentID).
+			First(&payment).Error; err != nil {
+			return err
+		}
+
+		available := toCents(payment.Amount) - toCents(payment.RefundedAmount)
+		amount := toCents(input.Amount)
+		if amount == 0 {
+			amount = available
+		}
+		if amount <= 0 {
+			return ErrInvalidRefundAmount
+		}
+		if amount > available {
+			return ErrRefundExceedsPayment
+		}
+
+		refund.Amount = fromCents(amount)
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityRefund, AuditActionCreate, refund.ID, nil, refund); err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Payment{}).
+			Where("tenant_id = ? AND id = ?", tenantID, payment.ID).
+			Update("refunded_amount", fromCents(toCents(payment.RefundedAmount)+amount)).Error; err != nil {
+			return err
+		}
+
+		amountPaid := toCents(order.AmountPaid) - amount
+		order.AmountPaid = fromCents(amountPaid)
+		if err := tx.Model(&domain.SalesOrder{}).
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			Update("amount_paid", order.AmountPaid).Error; err != nil {
+			return err
+		}
+
+		to := refundedOrderStatus(&order, amountPaid)
+		if to == "" {
+			return nil
+		}
+		if err := tx.
+			Where("tenant_id = ? AND order_id = ?", tenantID, orderID).
+			Find(&order.Items).Error; err != nil {
+			return err
+		}
+		if to == domain.SalesOrderStatusConfirmed {
+			// paid -> confirmed não é oferecida na API; só o estorno reabre o saldo.
+			return s.applySalesOrderTransition(tx, &order, to)
+		}
+		return s.transitionSalesOrder(tx, &order, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// refundedOrderStatus define o status do pedido após o estorno ("" mantém o atual). Sem
+// nenhum valor pago, pedidos que já baixaram estoque (confirmed, paid) são cancelados e o
+// estoque volta; um pedido pago que volta a ter saldo devedor retorna a confirmed.
+func refundedOrderStatus(order *domain.SalesOrder, amountPaid int64) string {
+	holdsStock := order.Status == domain.SalesOrderStatusConfirmed || order.Status == domain.SalesOrderStatusPaid
+	switch {
+	case amountPaid <= 0 && holdsStock:
+		return domain.SalesOrderStatusCanceled
+	case order.Status == domain.SalesOrderStatusPaid && amountPaid < toCents(order.Total):
+		return domain.SalesOrderStatusConfirmed
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestRefundedOrderStatus(t *testing.T) {
+	tests := []struct {
+		status     string
+		amountPaid int64
+		want       string
+	}{
+		{domain.SalesOrderStatusPaid, 0, domain.SalesOrderStatusCanceled},
+		{domain.SalesOrderStatusConfirmed, 0, domain.SalesOrderStatusCanceled},
+		{domain.SalesOrderStatusDraft, 0, ""},
+		{domain.SalesOrderStatusPaid, 7000, domain.SalesOrderStatusConfirmed},
+		{domain.SalesOrderStatusPaid, 10000, ""},
+		{domain.SalesOrderStatusConfirmed, 3000, ""},
+		{domain.SalesOrderStatusCanceled, 0, ""},
+	}
+	for _, tt := range tests {
+		order := &domain.SalesOrder{Status: tt.status, Total: 100}
+		assert.Equal(t, tt.want, refundedOrderStatus(order, tt.amountPaid), "%s com %d centavos pagos", tt.status, tt.amountPaid)
+	}
+}
+
+func TestRefundPaymentPartialReopensBalance(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Refund Client", "refund@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Coloração", 60)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "service", RefID: service.ID, Quantity: 1, UnitPrice: 100},
+	})
+	payment, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 100, PaidAt: time.Now()})
+	require.NoError(t, err)
+
+	refund, err := testSvc.RefundPayment(ctx, tenant.ID, order.ID, RefundInput{PaymentID: payment.ID, Amount: 30, Reason: "desconto posterior"})
+	require.NoError(t, err)
+	assert.InDelta(t, 30, refund.Amount, 0.001)
+
+	reloaded := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusConfirmed, reloaded.Status, "o saldo reaberto devolve o pedido a confirmed")
+	assert.InDelta(t, 70, reloaded.AmountPaid, 0.001)
+	assert.InDelta(t, 30, reloaded.BalanceDue, 0.001)
+
+	_, err = testSvc.RefundPayment(ctx, tenant.ID, order.ID, RefundInput{PaymentID: payment.ID, Amount: 80})
+	require.ErrorIs(t, err, ErrRefundExceedsPayment)
+
+	_, stats, err := testSvc.GetClient(ctx, tenant.ID, client.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 70, stats.TotalSpent, 0.001)
+
+	dashboard, err := testSvc.DashboardDaily(ctx, tenant.ID, time.Now(), nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 70, dashboard.Revenue, 0.001)
+}
+
+func TestRefundPaymentFullCancelsOrderAndRestoresStock(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Full Refund Client", "fullrefund@example.com", nil)
+	product := seedProductRecord(t, tenant.ID, "Máscara", "SALE-REFUND")
+	seedInventoryStock(t, tenant.ID, product.ID, 6)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: product.ID, Quantity: 2, UnitPrice: 45},
+	})
+	payment, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "credit", Amount: 90, PaidAt: time.Now()})
+	require.NoError(t, err)
+	assertProductStock(t, tenant.ID, product.ID, 4)
+
+	refund, err := testSvc.RefundPayment(ctx, tenant.ID, order.ID, RefundInput{PaymentID: payment.ID})
+	require.NoError(t, err)
+	assert.InDelta(t, 90, refund.Amount, 0.001)
+
+	reloaded := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusCanceled, reloaded.Status)
+	assert.InDelta(t, 0, reloaded.AmountPaid, 0.001)
+	assertProductStock(t, tenant.ID, product.ID, 6)
+}
+
+func TestRefundPaymentFullOnConfirmedOrderRestoresStock(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Deposit Client", "deposit@example.com", nil)
+	product := seedProductRecord(t, tenant.ID, "Condicionador", "SALE-DEPOSIT")
+	seedInventoryStock(t, tenant.ID, product.ID, 5)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: product.ID, Quantity: 3, UnitPrice: 20},
+	})
+	confirmed := domain.SalesOrderStatusConfirmed
+	_, err := testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &confirmed})
+	require.NoError(t, err)
+	assertProductStock(t, tenant.ID, product.ID, 2)
+
+	payment, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 20, PaidAt: time.Now()})
+	require.NoError(t, err)
+	_, err = testSvc.RefundPayment(ctx, tenant.ID, order.ID, RefundInput{PaymentID: payment.ID})
+	require.NoError(t, err)
+
+	reloaded := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusCanceled, reloaded.Status)
+	assertProductStock(t, tenant.ID, product.ID, 5)
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+const (
+	reminderMaxAttempts   = 3
+	reminderRetryInterval = 5 * time.Minute
+)
+
+// Antecedências (em minutos) e canais usados quando a empresa não configura os seus.
+var (
+	defaultReminderOffsets  = []int{24 * 60, 2 * 60}
+	defaultReminderChannels = []string{notify.ChannelEmail}
+)
+
+// RegisterNotifier habilita um canal de lembretes; canais sem notifier registrado
+// têm seus lembretes marcados como skipped.
+func (s *Service) RegisterNotifier(n notify.Notifier) {
+	if s.notifiers == nil {
+		s.notifiers = make(map[string]notify.Notifier)
+	}
+	s.notifiers[n.Channel()] = n
+}
+
+// registerConfiguredNotifiers habilita os canais configurados no ambiente.
+func (s *Service) registerConfiguredNotifiers() {
+	if s.cfg == nil {
+		return
+	}
+	if s.cfg.SMTPHost != "" {
+		s.RegisterNotifier(notify.NewSMTP(notify.SMTPConfig{
+			Host:     s.cfg.SMTPHost,
+			Port:     s.cfg.SMTPPort,
+			Username: s.cfg.SMTPUsername,
+			Password: s.cfg.SMTPPassword,
+			From:     s.cfg.SMTPFrom,
+		}))
+	}
+	if s.cfg.SMSGatewayURL != "" {
+		s.RegisterNotifier(notify.NewSMS(s.cfg.SMSGatewayURL, s.cfg.SMSGatewayToken))
+	}
+	if s.cfg.WhatsAppGatewayURL != "" {
+		s.RegisterNotifier(notify.NewWhatsApp(s.cfg.WhatsAppGatewayURL, s.cfg.WhatsAppGatewayToken))
+	}
+}
+
+// ListBookingReminders lista os lembretes já processados para o agendamento.
+func (s *Service) ListBookingReminders(ctx context.Context, tenantID, bookingID uuid.UUID) ([]domain.BookingReminder, error) {
+	if err := s.ensureTenantRecord(ctx, &domain.Booking{}, tenantID, bookingID); err != nil {
+		return nil, err
+	}
+	var reminders []domain.BookingReminder
+	err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND booking_id = ?", tenantID, bookingID).
+		Order("offset_minutes DESC, channel ASC").
+		Find(&reminders).Error
+	return reminders, err
+}
+
+// SendBookingReminders envia os lembretes devidos das empresas com reminders_enabled.
+// Cada agendamento recebe apenas o lembrete da menor antecedência já alcançada, por canal;
+// a linha em booking_reminders é reservada antes do envio, o que torna o processo
+// idempotente entre execuções e instâncias. Retorna a quantidade de lembretes enviados.
+func (s *Service) SendBookingReminders(ctx context.Context, now time.Time) (int, error) {
+	var companies []domain.Company
+	if err := s.dbWithContext(ctx).
+		Select("id", "settings").
+		Where("settings @> ?", fmt.Sprintf(`{%q: true}`, domain.SettingRemindersEnabled)).
+		Find(&companies).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range companies {
+		count, err := s.sendCompanyReminders(ctx, &companies[i], now)
+		sent += count
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (s *Service) sendCompanyReminders(ctx context.Context, company *domain.Company, now time.Time) (int, error) {
+	offsets := reminderOffsets(settingInts(company.Settings, domain.SettingReminderOffsets))
+	channels := reminderChannels(settingStrings(company.Settings, domain.SettingReminderChannels))
+	if len(offsets) == 0 || len(channels) == 0 {
+		return 0, nil
+	}
+
+	var bookings []domain.Booking
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND status IN ?", company.ID, []string{domain.BookingStatusPending, domain.BookingStatusConfirmed}).
+		Where("start_at > ? AND start_at <= ?", now, now.Add(time.Duration(offsets[0])*time.Minute)).
+		Order("start_at ASC").
+		Find(&bookings).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range bookings {
+		offset := dueReminderOffset(offsets, bookings[i].StartAt, now)
+		for _, channel := range channels {
+			reminder, err := s.claimBookingReminder(ctx, &bookings[i], offset, channel, now)
+			if err != nil {
+				return sent, err
+			}
+			if reminder == nil {
+				continue
+			}
+			ok, err := s.deliverBookingReminder(ctx, &bookings[i], reminder)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// claimBookingReminder reserva o lembrete para envio: cria a linha ou retoma uma falha
+// (ou reserva abandonada) após reminderRetryInterval. Retorna nil se não houver o que enviar.
+func (s *Service) claimBookingReminder(ctx context.Context, booking *domain.Booking, offset int, channel string, now time.Time) (*domain.BookingReminder, error) {
+	db := s.dbWithContext(ctx)
+	reminder := domain.BookingReminder{
+		TenantModel:   domain.TenantModel{TenantID: booking.TenantID},
+		BookingID:     booking.ID,
+		OffsetMinutes: offset,
+		Channel:       channel,
+		Status:        domain.BookingReminderPending,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminder)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return &reminder, nil
+	}
+
+	result = db.Model(&domain.BookingReminder{}).
+		Where("booking_id = ? AND offset_minutes = ? AND channel = ?", booking.ID, offset, channel).
+		Where("(status = ? AND attempts < ?) OR status = ?", domain.BookingReminderFailed, reminderMaxAttempts, domain.BookingReminderPending).
+		Where("updated_at <= ?", now.Add(-reminderRetryInterval)).
+		Update("status", domain.BookingReminderPending)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	err := db.Where("booking_id = ? AND offset_minutes = ? AND channel = ?", booking.ID, offset, channel).
+		First(&reminder).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// deliverBookingReminder envia o lembrete reservado e registra o resultado.
+func (s *Service) deliverBookingReminder(ctx context.Context, booking *domain.Booking, reminder *domain.BookingReminder) (bool, error) {
+	db := s.dbWithContext(ctx)
+	var data EmailTemplateData
+	if err := s.loadBookingEmailData(db, booking, &data); err != nil {
+		return false, err
+	}
+
+	recipient := reminderRecipient(data.Client, reminder.Channel)
+	notifier := s.notifiers[reminder.Channel]
+	updates := map[string]interface{}{"recipient": recipient}
+	switch {
+	case recipient == "":
+		updates["status"] = domain.BookingReminderSkipped
+		updates["last_error"] = notify.ErrNoRecipient.Error()
+	case notifier == nil:
+		updates["status"] = domain.BookingReminderSkipped
+		updates["last_error"] = fmt.Sprintf("canal %s não configurado", reminder.Channel)
+	default:
+		updates["attempts"] = reminder.Attempts + 1
+		if err := s.sendReminder(ctx, db, notifier, recipient, &data); err != nil {
+			updates["status"] = domain.BookingReminderFailed
+			updates["last_error"] = err.Error()
+		} else {
+			updates["status"] = domain.BookingReminderSent
+			updates["last_error"] = ""
+			updates["sent_at"] = time.Now().UTC()
+		}
+	}
+	if err := db.Model(&domain.BookingReminder{}).
+		Where("id = ?", reminder.ID).
+		Updates(updates).Error; err != nil {
+		return false, err
+	}
+	return updates["status"] == domain.BookingReminderSent, nil
+}
+
+func (s *Service) sendReminder(ctx context.Context, db *gorm.DB, notifier notify.Notifier, recipient string, data *EmailTemplateData) error {
+	msg, err := s.renderEmail(db, data.Booking.TenantID, domain.EmailTemplateBookingReminder, data)
+	if err != nil {
+		return err
+	}
+	msg.To = recipient
+	return notifier.Send(ctx, msg)
+}
+
+func reminderRecipient(client *domain.Client, channel string) string {
+	if channel == notify.ChannelEmail {
+		return strings.TrimSpace(client.Email)
+	}
+	return strings.TrimSpace(client.Phone)
+}
+
+// dueReminderOffset devolve a menor antecedência já alcançada; offsets em ordem decrescente.
+func dueReminderOffset(offsets []int, startAt, now time.Time) int {
+	due := offsets[0]
+	for _, offset := range offsets {
+		if !startAt.Add(-time.Duration(offset) * time.Minute).After(now) {
+			due = offset
+		}
+	}
+	return due
+}
+
+// reminderOffsets normaliza a configuração: positivos, sem repetição, em ordem decrescente.
+func reminderOffsets(configured []int) []int {
+	if configured == nil {
+		configured = defaultReminderOffsets
+	}
+	seen := make(map[int]bool)
+	offsets := make([]int, 0, len(configured))
+	for _, offset := range configured {
+		if offset <= 0 || seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		offsets = append(offsets, offset)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	return offsets
+}
+
+// reminderChannels mantém apenas os canais conhecidos, sem repetição.
+func reminderChannels(configured []string) []string {
+	if configured == nil {
+		configured = defaultReminderChannels
+	}
+	seen := make(map[string]bool)
+	channels := make([]string, 0, len(configured))
+	for _, channel := range configured {
+		channel = strings.ToLower(strings.TrimSpace(channel))
+		switch channel {
+		case notify.ChannelEmail, notify.ChannelSMS, notify.ChannelWhatsApp:
+		default:
+			continue
+		}
+		if seen[channel] {
+			continue
+		}
+		seen[channel] = true
+		channels = append(channels, channel)
+	}
+	return channels
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+func TestReminderOffsetsAndChannels(t *testing.T) {
+	assert.Equal(t, []int{1440, 120}, reminderOffsets(nil))
+	assert.Equal(t, []int{60, 30}, reminderOffsets([]int{30, 60, 30, -5, 0}))
+	assert.Empty(t, reminderOffsets([]int{}))
+
+	assert.Equal(t, []string{notify.ChannelEmail}, reminderChannels(nil))
+	assert.Equal(t, []string{notify.ChannelWhatsApp, notify.ChannelSMS}, reminderChannels([]string{"WhatsApp", "sms", "fax", "sms"}))
+}
+
+func TestDueReminderOffset(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	offsets := []int{1440, 120}
+
+	assert.Equal(t, 1440, dueReminderOffset(offsets, now.Add(20*time.Hour), now))
+	assert.Equal(t, 120, dueReminderOffset(offsets, now.Add(2*time.Hour), now))
+	assert.Equal(t, 120, dueReminderOffset(offsets, now.Add(30*time.Minute), now))
+}
+
+// recordingNotifier guarda as mensagens enviadas e pode simular falhas.
+type recordingNotifier struct {
+	mu      sync.Mutex
+	channel string
+	fail    bool
+	sent    []notify.Message
+}
+
+func (n *recordingNotifier) Channel() string { return n.channel }
+
+func (n *recordingNotifier) Send(_ context.Context, msg notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail {
+		return errors.New("falha simulada")
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func TestSendBookingRemindersIsIdempotent(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	require.NoError(t, testDB.Model(tenant).Update("settings", datatypes.JSONMap{
+		domain.SettingRemindersEnabled: true,
+		domain.SettingReminderChannels: []interface{}{"email", "sms"},
+	}).Error)
+
+	client := seedClientRecord(t, tenant.ID, "Lembrete Client", "lembrete@example.com", nil)
+	professional := seedProfessionalRecord(t, tenant.ID, "Profissional")
+	svcRecord := seedServiceRecord(t, tenant.ID, "Corte", 30)
+	now := time.Now().UTC().Truncate(time.Minute)
+	booking := &domain.Booking{
+		TenantModel:    domain.TenantModel{TenantID: tenant.ID},
+		ClientID:       client.ID,
+		ProfessionalID: professional.ID,
+		ServiceID:      svcRecord.ID,
+		Status:         domain.BookingStatusConfirmed,
+		StartAt:        now.Add(90 * time.Minute),
+		EndAt:          now.Add(120 * time.Minute),
+	}
+	require.NoError(t, testDB.Create(booking).Error)
+
+	email := &recordingNotifier{channel: notify.ChannelEmail}
+	svc := New(testSvc.cfg, testSvc.repo, testSvc.jwt, testSvc.logger)
+	svc.RegisterNotifier(email)
+
+	sent, err := svc.SendBookingReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, email.sent, 1)
+	assert.Equal(t, "lembrete@example.com", email.sent[0].To)
+	assert.Contains(t, email.sent[0].Body, "Corte")
+
+	sent, err = svc.SendBookingReminders(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Len(t, email.sent, 1)
+
+	reminders, err := svc.ListBookingReminders(ctx, tenant.ID, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+	statuses := map[string]string{}
+	for _, reminder := range reminders {
+		assert.Equal(t, 120, reminder.OffsetMinutes)
+		statuses[reminder.Channel] = reminder.Status
+	}
+	assert.Equal(t, domain.BookingReminderSent, statuses[notify.ChannelEmail])
+	assert.Equal(t, domain.BookingReminderSkipped, statuses[notify.ChannelSMS], "canal sem notifier não envia")
+}
+
+func TestSendBookingRemindersRetriesFailures(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	require.NoError(t, testDB.Model(tenant).Update("settings", datatypes.JSONMap{
+		domain.SettingRemindersEnabled: true,
+		domain.SettingReminderOffsets:  []interface{}{60},
+	}).Error)
+
+	client := seedClientRecord(t, tenant.ID, "Retry Client", "retry@example.com", nil)
+	professional := seedProfessionalRecord(t, tenant.ID, "Profissional")
+	svcRecord := seedServiceRecord(t, tenant.ID, "Barba", 30)
+	now := time.Now().UTC().Truncate(time.Minute)
+	booking := &domain.Booking{
+		TenantModel:    domain.TenantModel{TenantID: tenant.ID},
+		ClientID:       client.ID,
+		ProfessionalID: professional.ID,
+		ServiceID:      svcRecord.ID,
+		Status:         domain.BookingStatusPending,
+		StartAt:        now.Add(45 * time.Minute),
+		EndAt:          now.Add(75 * time.Minute),
+	}
+	require.NoError(t, testDB.Create(booking).Error)
+
+	email := &recordingNotifier{channel: notify.ChannelEmail, fail: true}
+	svc := New(testSvc.cfg, testSvc.repo, testSvc.jwt, testSvc.logger)
+	svc.RegisterNotifier(email)
+
+	sent, err := svc.SendBookingReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	// Antes do intervalo de nova tentativa, a falha não é reenviada.
+	email.fail = false
+	sent, err = svc.SendBookingReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+
+	sent, err = svc.SendBookingReminders(ctx, time.Now().UTC().Add(reminderRetryInterval))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	var reminder domain.BookingReminder
+	require.NoError(t, testDB.Where("booking_id = ?", booking.ID).First(&reminder).Error)
+	assert.Equal(t, domain.BookingReminderSent, reminder.Status)
+	assert.Equal(t, 2, reminder.Attempts)
+	assert.NotNil(t, reminder.SentAt)
+}
+
+func TestSendBookingRemindersRespectsSettings(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+
+	client := seedClientRecord(t, tenant.ID, "Off Client", "off@example.com", nil)
+	professional := seedProfessionalRecord(t, tenant.ID, "Profissional")
+	svcRecord := seedServiceRecord(t, tenant.ID, "Corte", 30)
+	now := time.Now().UTC()
+	require.NoError(t, testDB.Create(&domain.Booking{
+		TenantModel:    domain.TenantModel{TenantID: tenant.ID},
+		ClientID:       client.ID,
+		ProfessionalID: professional.ID,
+		ServiceID:      svcRecord.ID,
+		Status:         domain.BookingStatusConfirmed,
+		StartAt:        now.Add(time.Hour),
+		EndAt:          now.Add(90 * time.Minute),
+	}).Error)
+
+	email := &recordingNotifier{channel: notify.ChannelEmail}
+	svc := New(testSvc.cfg, testSvc.repo, testSvc.jwt, testSvc.logger)
+	svc.RegisterNotifier(email)
+
+	sent, err := svc.SendBookingReminders(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, email.sent)
+}
@@ -4,15 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
 
+const (
+	salesItemTypeService = "service"
+	salesItemTypeProduct = "product"
+)
+
+var (
+	ErrInvalidPaymentAmount = errors.New("valor do pagamento deve ser maior que zero")
+	ErrOverpayment          = errors.New("pagamento excede o saldo em aberto do pedido")
+	ErrOrderNotPayable      = errors.New("pedido cancelado não aceita pagamentos")
+)
+
 // SalesOrderFilter filtros de listagem.
 type SalesOrderFilter struct {
 	Status   string
@@ -82,7 +96,16 @@ func (s *Service) ListSalesOrders(ctx context.Context, tenantID uuid.UUID, filte
 	return orders, nil
 }
 
+// CreateSalesOrder cria o pedido em rascunho. Com BookingID e sem itens, gera um item
+// por serviço do agendamento ao preço atual.
 func (s *Service) CreateSalesOrder(ctx context.Context, tenantID uuid.UUID, input SalesOrderInput) (*domain.SalesOrder, error) {
+	if len(input.Items) == 0 && input.BookingID != nil {
+		items, err := s.bookingSalesItems(ctx, tenantID, *input.BookingID)
+		if err != nil {
+			return nil, err
+		}
+		input.Items = items
+	}
 	if len(input.Items) == 0 {
 		return nil, errors.New("ao menos um item é obrigatório")
 	}
@@ -112,36 +135,7 @@ func (s *Service) CreateSalesOrder(ctx context.Context, tenantID uuid.UUID, inpu
 	}
 
 	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
-		var total float64
-		if err := tx.Create(order).Error; err != nil {
-			return err
-		}
-
-		for _, item := range input.Items {
-			if item.Quantity <= 0 {
-				return errors.New("quantidade inválida em item")
-			}
-			salesItem := domain.SalesItem{
-				TenantModel: domain.TenantModel{
-					TenantID: tenantID,
-				},
-				OrderID:   order.ID,
-				ItemType:  item.Type,
-				ItemRefID: item.RefID,
-				Quantity:  item.Quantity,
-				UnitPrice: item.UnitPrice,
-			}
-			total += float64(item.Quantity) * item.UnitPrice
-			if err := tx.Create(&salesItem).Error; err != nil {
-				return err
-			}
-		}
-
-		order.Total = total - input.Discount
-		if order.Total < 0 {
-			order.Total = 0
-		}
-		return tx.Model(order).Updates(map[string]interface{}{"total": order.Total}).Error
+		return s.createSalesOrderTx(tx, order, input.Items)
 	})
 	if err != nil {
 		return nil, err
@@ -155,47 +149,230 @@ func (s *Service) CreateSalesOrder(ctx context.Context, tenantID uuid.UUID, inpu
 	return order, nil
 }
 
+// createSalesOrderTx grava o pedido com seus itens e calcula o total, já descontado
+// order.Discount.
+func (s *Service) createSalesOrderTx(tx *gorm.DB, order *domain.SalesOrder, items []SalesItemInput) error {
+	var total float64
+	if err := tx.Create(order).Error; err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return errors.New("quantidade inválida em item")
+		}
+		salesItem := domain.SalesItem{
+			TenantModel: domain.TenantModel{
+				TenantID: order.TenantID,
+			},
+			OrderID:   order.ID,
+			ItemType:  item.Type,
+			ItemRefID: item.RefID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		}
+		total += float64(item.Quantity) * item.UnitPrice
+		if err := tx.Create(&salesItem).Error; err != nil {
+			return err
+		}
+		order.Items = append(order.Items, salesItem)
+	}
+
+	order.Total = total - order.Discount
+	if order.Total < 0 {
+		order.Total = 0
+	}
+	if err := tx.Model(order).Updates(map[string]interface{}{"total": order.Total}).Error; err != nil {
+		return err
+	}
+	if err := s.audit(tx, order.TenantID, AuditEntitySalesOrder, AuditActionCreate, order.ID, nil, order); err != nil {
+		return err
+	}
+	return s.publish(tx, order.TenantID, EventSalesOrderCreated, order)
+}
+
 func (s *Service) UpdateSalesOrder(ctx context.Context, tenantID, orderID uuid.UUID, input SalesOrderUpdateInput) (*domain.SalesOrder, error) {
 	var order domain.SalesOrder
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			First(&order).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND order_id = ?", tenantID, orderID).
+			Find(&order.Items).Error; err != nil {
+			return err
+		}
+
+		// A mudança de status é auditada pelo hook de transição; aqui fica só o restante.
+		if input.Status != nil {
+			if err := s.transitionSalesOrder(tx, &order, *input.Status); err != nil {
+				return err
+			}
+		}
+		before := order
+		if input.Notes != nil {
+			if err := tx.
+				Model(&domain.SalesOrder{}).
+				Where("tenant_id = ? AND id = ?", tenantID, orderID).
+				Update("notes", *input.Notes).Error; err != nil {
+				return err
+			}
+		}
+
+		var after domain.SalesOrder
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			Preload("Items").
+			First(&after).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntitySalesOrder, AuditActionUpdate, orderID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := s.dbWithContext(ctx).
 		Where("tenant_id = ? AND id = ?", tenantID, orderID).
 		Preload("Items").
 		First(&order).Error; err != nil {
 		return nil, err
 	}
+	return &order, nil
+}
 
-	updates := map[string]interface{}{}
-	if input.Status != nil {
-		updates["status"] = *input.Status
+// syncOrderStock baixa o estoque dos itens de produto quando o pedido é
+// confirmado/pago e devolve o que foi baixado quando ele é cancelado.
+func (s *Service) syncOrderStock(tx *gorm.DB, order *domain.SalesOrder, _, to string) error {
+	switch to {
+	case domain.SalesOrderStatusConfirmed, domain.SalesOrderStatusPaid:
+		return s.deductOrderStock(tx, order)
+	case domain.SalesOrderStatusCanceled:
+		return s.restoreOrderStock(tx, order)
+	default:
+		return nil
 	}
-	if input.Notes != nil {
-		updates["notes"] = *input.Notes
+}
+
+// auditOrderTransition registra a mudança de status no audit log, seja qual for o caminho
+// que a provocou (atualização, pagamento ou estorno).
+func (s *Service) auditOrderTransition(tx *gorm.DB, order *domain.SalesOrder, from, to string) error {
+	before, after := *order, *order
+	before.Status = from
+	after.Status = to
+	return s.audit(tx, order.TenantID, AuditEntitySalesOrder, AuditActionUpdate, order.ID, before, after)
+}
+
+func (s *Service) deductOrderStock(tx *gorm.DB, order *domain.SalesOrder) error {
+	committed, err := orderCommittedStock(tx, order)
+	if err != nil {
+		return err
+	}
+	if len(committed) > 0 {
+		// Estoque já baixado em transição anterior (ex.: confirmed -> paid).
+		return nil
 	}
 
-	if len(updates) == 0 {
-		return &order, nil
+	quantities := map[uuid.UUID]int{}
+	for _, item := range order.Items {
+		if item.ItemType != salesItemTypeProduct {
+			continue
+		}
+		quantities[item.ItemRefID] += item.Quantity
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.SalesOrder{}).
-		Where("tenant_id = ? AND id = ?", tenantID, orderID).
-		Updates(updates).Error; err != nil {
-		return nil, err
+	for _, productID := range sortedProductIDs(quantities) {
+		if _, err := s.applyInventoryMovement(tx, order.TenantID, InventoryInput{
+			ProductID: productID,
+			OrderID:   &order.ID,
+			Type:      domain.InventoryMovementOut,
+			Quantity:  quantities[productID],
+			Reason:    "baixa automática da venda",
+		}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, orderID).
-		Preload("Items").
-		First(&order).Error; err != nil {
+func (s *Service) restoreOrderStock(tx *gorm.DB, order *domain.SalesOrder) error {
+	committed, err := orderCommittedStock(tx, order)
+	if err != nil {
+		return err
+	}
+
+	for _, productID := range sortedProductIDs(committed) {
+		if _, err := s.applyInventoryMovement(tx, order.TenantID, InventoryInput{
+			ProductID: productID,
+			OrderID:   &order.ID,
+			Type:      domain.InventoryMovementIn,
+			Quantity:  committed[productID],
+			Reason:    "estorno de venda cancelada",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderCommittedStock retorna, por produto, a quantidade ainda baixada pelo pedido
+// (saídas menos estornos vinculados ao order_id).
+func orderCommittedStock(tx *gorm.DB, order *domain.SalesOrder) (map[uuid.UUID]int, error) {
+	type row struct {
+		ProductID uuid.UUID
+		Net       int
+	}
+	var rows []row
+	if err := tx.
+		Model(&domain.InventoryMovement{}).
+		Select("product_id, SUM(CASE WHEN type = ? THEN quantity WHEN type = ? THEN -quantity ELSE 0 END) AS net",
+			domain.InventoryMovementOut, domain.InventoryMovementIn).
+		Where("tenant_id = ? AND order_id = ?", order.TenantID, order.ID).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
 		return nil, err
 	}
-	return &order, nil
+
+	committed := map[uuid.UUID]int{}
+	for _, r := range rows {
+		if r.Net > 0 {
+			committed[r.ProductID] = r.Net
+		}
+	}
+	return committed, nil
+}
+
+// toCents evita erros de arredondamento ao comparar valores monetários.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// sortedProductIDs garante ordem estável de bloqueio entre transações concorrentes.
+func sortedProductIDs(quantities map[uuid.UUID]int) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
 }
 
+// AddPayment registra um pagamento parcial ou total e concilia o saldo do pedido.
+// Quando o saldo zera, o pedido passa automaticamente para paid.
 func (s *Service) AddPayment(ctx context.Context, tenantID, orderID uuid.UUID, input PaymentInput) (*domain.Payment, error) {
-	if err := s.ensureTenantRecord(ctx, &domain.SalesOrder{}, tenantID, orderID); err != nil {
-		return nil, err
+	if input.Amount <= 0 {
+		return nil, ErrInvalidPaymentAmount
 	}
+
 	payment := &domain.Payment{
 		TenantModel: domain.TenantModel{
 			TenantID: tenantID,
@@ -210,7 +387,57 @@ func (s *Service) AddPayment(ctx context.Context, tenantID, orderID uuid.UUID, i
 		payment.Details = datatypes.JSONMap{}
 	}
 
-	if err := s.dbWithContext(ctx).Create(payment).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var order domain.SalesOrder
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			First(&order).Error; err != nil {
+			return err
+		}
+		if order.Status == domain.SalesOrderStatusCanceled {
+			return ErrOrderNotPayable
+		}
+
+		amountPaid := toCents(order.AmountPaid) + toCents(input.Amount)
+		if amountPaid > toCents(order.Total) {
+			settings, err := s.companySettings(tx, tenantID)
+			if err != nil {
+				return err
+			}
+			if !settingBool(settings, domain.SettingAllowOverpayment) {
+				return ErrOverpayment
+			}
+			payment.Flagged = true
+		}
+
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityPayment, AuditActionCreate, payment.ID, nil, payment); err != nil {
+			return err
+		}
+		if err := s.publish(tx, tenantID, EventPaymentCreated, payment); err != nil {
+			return err
+		}
+		order.AmountPaid = fromCents(amountPaid)
+		if err := tx.Model(&domain.SalesOrder{}).
+			Where("tenant_id = ? AND id = ?", tenantID, orderID).
+			Update("amount_paid", order.AmountPaid).Error; err != nil {
+			return err
+		}
+
+		if amountPaid < toCents(order.Total) || order.Status == domain.SalesOrderStatusPaid {
+			return nil
+		}
+		if err := tx.
+			Where("tenant_id = ? AND order_id = ?", tenantID, orderID).
+			Find(&order.Items).Error; err != nil {
+			return err
+		}
+		return s.transitionSalesOrder(tx, &order, domain.SalesOrderStatusPaid)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return payment, nil
@@ -240,11 +467,11 @@ func (s *Service) ListPayments(ctx context.Context, tenantID uuid.UUID, filter P
 func (s *Service) ensureSalesItems(ctx context.Context, tenantID uuid.UUID, items []SalesItemInput) error {
 	for _, item := range items {
 		switch item.Type {
-		case "service":
+		case salesItemTypeService:
 			if err := s.ensureTenantRecord(ctx, &domain.Service{}, tenantID, item.RefID); err != nil {
 				return err
 			}
-		case "product":
+		case salesItemTypeProduct:
 			if err := s.ensureTenantRecord(ctx, &domain.Product{}, tenantID, item.RefID); err != nil {
 				return err
 			}
@@ -276,13 +503,17 @@ func (s *Service) AdminCreateSalesOrder(ctx context.Context, input AdminSalesOrd
 }
 
 func (s *Service) AdminUpdateSalesOrder(ctx context.Context, orderID uuid.UUID, input SalesOrderUpdateInput) (*domain.SalesOrder, error) {
-	var order domain.SalesOrder
-	if err := s.dbWithContext(ctx).First(&order, "id = ?", orderID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.SalesOrder{}, orderID)
+	if err != nil {
 		return nil, err
 	}
-	return s.UpdateSalesOrder(ctx, order.TenantID, orderID, input)
+	return s.UpdateSalesOrder(ctx, tenantID, orderID, input)
 }
 
 func (s *Service) AdminDeleteSalesOrder(ctx context.Context, orderID uuid.UUID) error {
-	return s.dbWithContext(ctx).Delete(&domain.SalesOrder{}, "id = ?", orderID).Error
+	tenantID, err := s.recordTenant(ctx, &domain.SalesOrder{}, orderID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	return s.deleteTenantRecord(ctx, tenantID, orderID, &domain.SalesOrder{}, AuditEntitySalesOrder)
 }
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+// InvalidTransitionError indica uma mudança de status não permitida pela máquina de estados.
+type InvalidTransitionError struct {
+	Entity string
+	From   string
+	To     string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("transição de %s inválida: %q -> %q", e.Entity, e.From, e.To)
+}
+
+// SalesOrderTransitionHook é executado dentro da transação sempre que um pedido muda de status.
+// Retornar erro desfaz a transição.
+type SalesOrderTransitionHook func(tx *gorm.DB, order *domain.SalesOrder, from, to string) error
+
+var salesOrderTransitions = map[string][]string{
+	domain.SalesOrderStatusDraft: {
+		domain.SalesOrderStatusConfirmed,
+		domain.SalesOrderStatusPaid,
+		domain.SalesOrderStatusCanceled,
+	},
+	domain.SalesOrderStatusConfirmed: {
+		domain.SalesOrderStatusPaid,
+		domain.SalesOrderStatusCanceled,
+	},
+	domain.SalesOrderStatusPaid: {
+		domain.SalesOrderStatusCanceled,
+	},
+	domain.SalesOrderStatusCanceled: {},
+}
+
+// OnSalesOrderTransition registra um hook adicional para transições de pedidos.
+func (s *Service) OnSalesOrderTransition(hook SalesOrderTransitionHook) {
+	s.orderHooks = append(s.orderHooks, hook)
+}
+
+func canTransitionSalesOrder(from, to string) bool {
+	for _, allowed := range salesOrderTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionSalesOrder valida a transição, executa os hooks e persiste o novo status.
+// Deve ser chamada com o pedido já bloqueado e seus itens carregados.
+func (s *Service) transitionSalesOrder(tx *gorm.DB, order *domain.SalesOrder, to string) error {
+	from := order.Status
+	if from == to {
+		return nil
+	}
+	if !canTransitionSalesOrder(from, to) {
+		return &InvalidTransitionError{Entity: "pedido", From: from, To: to}
+	}
+	return s.applySalesOrderTransition(tx, order, to)
+}
+
+// applySalesOrderTransition executa os hooks e persiste o novo status sem consultar a
+// máquina de estados. Reservada a transições internas, como a reabertura por estorno.
+func (s *Service) applySalesOrderTransition(tx *gorm.DB, order *domain.SalesOrder, to string) error {
+	from := order.Status
+	for _, hook := range s.orderHooks {
+		if err := hook(tx, order, from, to); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&domain.SalesOrder{}).
+		Where("tenant_id = ? AND id = ?", order.TenantID, order.ID).
+		Update("status", to).Error; err != nil {
+		return err
+	}
+	order.Status = to
+	return nil
+}
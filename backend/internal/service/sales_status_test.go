@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestCanTransitionSalesOrder(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{domain.SalesOrderStatusDraft, domain.SalesOrderStatusConfirmed, true},
+		{domain.SalesOrderStatusDraft, domain.SalesOrderStatusPaid, true},
+		{domain.SalesOrderStatusConfirmed, domain.SalesOrderStatusPaid, true},
+		{domain.SalesOrderStatusPaid, domain.SalesOrderStatusCanceled, true},
+		{domain.SalesOrderStatusConfirmed, domain.SalesOrderStatusDraft, false},
+		{domain.SalesOrderStatusPaid, domain.SalesOrderStatusConfirmed, false},
+		{domain.SalesOrderStatusCanceled, domain.SalesOrderStatusDraft, false},
+		{domain.SalesOrderStatusDraft, "shipped", false},
+	}
+
+	for _, tt := range tests {
+		if got := canTransitionSalesOrder(tt.from, tt.to); got != tt.want {
+			t.Fatalf("canTransitionSalesOrder(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateSalesOrderRejectsInvalidTransition(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Transition Client", "transition@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Barba", 20)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "service", RefID: service.ID, Quantity: 1, UnitPrice: 40},
+	})
+
+	canceled := domain.SalesOrderStatusCanceled
+	_, err := testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &canceled})
+	require.NoError(t, err)
+
+	draft := domain.SalesOrderStatusDraft
+	_, err = testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &draft})
+	var transitionErr *InvalidTransitionError
+	require.True(t, errors.As(err, &transitionErr))
+	assert.Equal(t, domain.SalesOrderStatusCanceled, transitionErr.From)
+	assert.Equal(t, domain.SalesOrderStatusDraft, transitionErr.To)
+
+	var reloaded domain.SalesOrder
+	require.NoError(t, testDB.First(&reloaded, "id = ?", order.ID).Error)
+	assert.Equal(t, domain.SalesOrderStatusCanceled, reloaded.Status)
+}
+
+func TestUpdateSalesOrderRunsTransitionHooks(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Hook Client", "hook@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Escova", 30)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "service", RefID: service.ID, Quantity: 1, UnitPrice: 60},
+	})
+
+	svc := New(testSvc.cfg, testSvc.repo, nil, nil)
+	var calls []string
+	svc.OnSalesOrderTransition(func(_ *gorm.DB, _ *domain.SalesOrder, from, to string) error {
+		calls = append(calls, from+"->"+to)
+		if to == domain.SalesOrderStatusCanceled {
+			return errors.New("hook falhou")
+		}
+		return nil
+	})
+
+	confirmed := domain.SalesOrderStatusConfirmed
+	_, err := svc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &confirmed})
+	require.NoError(t, err)
+
+	canceled := domain.SalesOrderStatusCanceled
+	_, err = svc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &canceled})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"draft->confirmed", "confirmed->canceled"}, calls)
+	var reloaded domain.SalesOrder
+	require.NoError(t, testDB.First(&reloaded, "id = ?", order.ID).Error)
+	assert.Equal(t, domain.SalesOrderStatusConfirmed, reloaded.Status)
+}
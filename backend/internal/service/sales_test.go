@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestUpdateSalesOrderDeductsAndRestoresStock(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Stock Client", "stock@example.com", nil)
+	product := seedProductRecord(t, tenant.ID, "Shampoo", "SALE-STOCK")
+	seedInventoryStock(t, tenant.ID, product.ID, 10)
+	service := seedServiceRecord(t, tenant.ID, "Corte", 30)
+
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: product.ID, Quantity: 3, UnitPrice: 20},
+		{Type: "service", RefID: service.ID, Quantity: 1, UnitPrice: 50},
+	})
+
+	confirmed := domain.SalesOrderStatusConfirmed
+	_, err := testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &confirmed})
+	require.NoError(t, err)
+	assertProductStock(t, tenant.ID, product.ID, 7)
+
+	movements, err := testSvc.ListInventoryMovements(ctx, tenant.ID, InventoryFilter{ProductID: &product.ID, Type: domain.InventoryMovementOut})
+	require.NoError(t, err)
+	require.Len(t, movements, 1)
+	require.NotNil(t, movements[0].OrderID)
+	assert.Equal(t, order.ID, *movements[0].OrderID)
+
+	paid := domain.SalesOrderStatusPaid
+	_, err = testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &paid})
+	require.NoError(t, err)
+	assertProductStock(t, tenant.ID, product.ID, 7)
+
+	canceled := domain.SalesOrderStatusCanceled
+	_, err = testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &canceled})
+	require.NoError(t, err)
+	assertProductStock(t, tenant.ID, product.ID, 10)
+}
+
+func TestUpdateSalesOrderRollsBackOnInsufficientStock(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Rollback Client", "rollback@example.com", nil)
+	available := seedProductRecord(t, tenant.ID, "Pomada", "SALE-OK")
+	seedInventoryStock(t, tenant.ID, available.ID, 5)
+	scarce := seedProductRecord(t, tenant.ID, "Cera", "SALE-SCARCE")
+	seedInventoryStock(t, tenant.ID, scarce.ID, 1)
+
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: available.ID, Quantity: 2, UnitPrice: 10},
+		{Type: "product", RefID: scarce.ID, Quantity: 4, UnitPrice: 10},
+	})
+
+	confirmed := domain.SalesOrderStatusConfirmed
+	_, err := testSvc.UpdateSalesOrder(ctx, tenant.ID, order.ID, SalesOrderUpdateInput{Status: &confirmed})
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	assertProductStock(t, tenant.ID, available.ID, 5)
+	assertProductStock(t, tenant.ID, scarce.ID, 1)
+
+	var reloaded domain.SalesOrder
+	require.NoError(t, testDB.First(&reloaded, "id = ?", order.ID).Error)
+	assert.Equal(t, domain.SalesOrderStatusDraft, reloaded.Status)
+}
+
+func seedSalesOrderWithItems(t *testing.T, tenantID, clientID uuid.UUID, items []SalesItemInput) *domain.SalesOrder {
+	t.Helper()
+	order, err := testSvc.CreateSalesOrder(context.Background(), tenantID, SalesOrderInput{
+		ClientID: clientID,
+		Items:    items,
+	})
+	require.NoError(t, err)
+	return order
+}
+
+func assertProductStock(t *testing.T, tenantID, productID uuid.UUID, expected int) {
+	t.Helper()
+	product, err := testSvc.GetProduct(context.Background(), tenantID, productID)
+	require.NoError(t, err)
+	assert.Equal(t, expected, product.StockQty)
+}
+
+func TestAddPaymentSettlesOrderWhenBalanceReachesZero(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Payment Client", "payment@example.com", nil)
+	product := seedProductRecord(t, tenant.ID, "Condicionador", "SALE-PAY")
+	seedInventoryStock(t, tenant.ID, product.ID, 5)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "product", RefID: product.ID, Quantity: 2, UnitPrice: 50},
+	})
+
+	_, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 40, PaidAt: time.Now()})
+	require.NoError(t, err)
+
+	partial := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusDraft, partial.Status)
+	assert.InDelta(t, 40, partial.AmountPaid, 0.001)
+	assert.InDelta(t, 60, partial.BalanceDue, 0.001)
+
+	_, err = testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "cash", Amount: 60, PaidAt: time.Now()})
+	require.NoError(t, err)
+
+	settled := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusPaid, settled.Status)
+	assert.InDelta(t, 0, settled.BalanceDue, 0.001)
+	assertProductStock(t, tenant.ID, product.ID, 3)
+}
+
+func TestAddPaymentOverpaymentPolicy(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	client := seedClientRecord(t, tenant.ID, "Overpay Client", "overpay@example.com", nil)
+	service := seedServiceRecord(t, tenant.ID, "Hidratação", 40)
+	order := seedSalesOrderWithItems(t, tenant.ID, client.ID, []SalesItemInput{
+		{Type: "service", RefID: service.ID, Quantity: 1, UnitPrice: 80},
+	})
+
+	_, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 100, PaidAt: time.Now()})
+	require.ErrorIs(t, err, ErrOverpayment)
+	assert.InDelta(t, 0, reloadSalesOrder(t, tenant.ID, order.ID).AmountPaid, 0.001)
+
+	require.NoError(t, testDB.Model(&domain.Company{}).
+		Where("id = ?", tenant.ID).
+		Update("settings", datatypes.JSONMap{domain.SettingAllowOverpayment: true}).Error)
+
+	payment, err := testSvc.AddPayment(ctx, tenant.ID, order.ID, PaymentInput{Method: "pix", Amount: 100, PaidAt: time.Now()})
+	require.NoError(t, err)
+	assert.True(t, payment.Flagged)
+
+	overpaid := reloadSalesOrder(t, tenant.ID, order.ID)
+	assert.Equal(t, domain.SalesOrderStatusPaid, overpaid.Status)
+	assert.InDelta(t, -20, overpaid.BalanceDue, 0.001)
+}
+
+func reloadSalesOrder(t *testing.T, tenantID, orderID uuid.UUID) *domain.SalesOrder {
+	t.Helper()
+	var order domain.SalesOrder
+	require.NoError(t, testDB.Where("tenant_id = ? AND id = ?", tenantID, orderID).First(&order).Error)
+	return &order
+}
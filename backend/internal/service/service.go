@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/google/uuid"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/kusmin/gestao_updev/backend/internal/auth"
 	"github.com/kusmin/gestao_updev/backend/internal/config"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
 	"github.com/kusmin/gestao_updev/backend/internal/repository"
 )
 
@@ -23,16 +25,31 @@ type Service struct {
 	jwt    *auth.JWTManager
 	cfg    *config.Config
 	logger *zap.Logger
+
+	orderHooks    []SalesOrderTransitionHook
+	bookingHooks  []BookingTransitionHook
+	eventHandlers map[string][]EventHandler
+	webhookClient *http.Client
+	notifiers     map[string]notify.Notifier
 }
 
 // New instancia o service layer.
 func New(cfg *config.Config, repo *repository.Repository, jwt *auth.JWTManager, logger *zap.Logger) *Service {
-	return &Service{
+	svc := &Service{
 		repo:   repo,
 		jwt:    jwt,
 		cfg:    cfg,
 		logger: logger,
 	}
+	svc.webhookClient = svc.newWebhookClient()
+	svc.OnSalesOrderTransition(svc.syncOrderStock)
+	svc.OnSalesOrderTransition(svc.auditOrderTransition)
+	svc.OnSalesOrderTransition(svc.publishOrderTransition)
+	svc.OnBookingTransition(svc.releaseBookingSlot)
+	svc.OnBookingTransition(svc.publishBookingTransition)
+	svc.Subscribe(EventAll, svc.enqueueWebhooks)
+	svc.registerConfiguredNotifiers()
+	return svc
 }
 
 func (s *Service) dbWithContext(ctx context.Context) *gorm.DB {
@@ -62,3 +79,26 @@ func (s *Service) ensureTenantRecord(ctx context.Context, model interface{}, ten
 		Where("tenant_id = ? AND id = ?", tenantID, recordID).
 		Take(model).Error
 }
+
+// recordTenant devolve o tenant de um registro, para que as rotas administrativas
+// reutilizem os casos de uso por tenant.
+func (s *Service) recordTenant(ctx context.Context, model interface{}, recordID uuid.UUID) (uuid.UUID, error) {
+	var tenantIDs []uuid.UUID
+	if err := s.dbWithContext(ctx).
+		Model(model).
+		Where("id = ?", recordID).
+		Pluck("tenant_id", &tenantIDs).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(tenantIDs) == 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return tenantIDs[0], nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/auth"
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const sessionUserAgentMaxLen = 255
+
+// ErrSessionNotFound sinaliza sessão inexistente, de outro usuário ou já encerrada.
+var ErrSessionNotFound = errors.New("sessão não encontrada")
+
+// SessionView sessão ativa exibida ao usuário; Current marca a sessão da própria requisição.
+type SessionView struct {
+	ID         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// startSession abre a sessão do login e emite o primeiro par de tokens da família.
+func (s *Service) startSession(ctx context.Context, db *gorm.DB, user *domain.User) (*auth.TokenPair, error) {
+	actor := auditActorFrom(ctx)
+	if len(actor.UserAgent) > sessionUserAgentMaxLen {
+		actor.UserAgent = actor.UserAgent[:sessionUserAgentMaxLen]
+	}
+	now := time.Now().UTC()
+	session := &domain.UserSession{
+		TenantModel: domain.TenantModel{TenantID: user.TenantID},
+		UserID:      user.ID,
+		Device:      deviceFromUserAgent(actor.UserAgent),
+		UserAgent:   actor.UserAgent,
+		IPAddress:   actor.IP,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(s.jwt.RefreshTTL()),
+	}
+	if err := db.Create(session).Error; err != nil {
+		return nil, err
+	}
+	return s.issueSessionTokens(db, user, session)
+}
+
+// issueSessionTokens gera o par de tokens e persiste apenas o hash do refresh token.
+func (s *Service) issueSessionTokens(db *gorm.DB, user *domain.User, session *domain.UserSession) (*auth.TokenPair, error) {
+	pair, err := s.jwt.GenerateTokens(user.ID.String(), user.TenantID.String(), user.Role, session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	token := &domain.RefreshToken{
+		TenantModel: domain.TenantModel{TenantID: session.TenantID},
+		SessionID:   session.ID,
+		TokenHash:   s.jwt.HashRefreshToken(pair.RefreshToken),
+		ExpiresAt:   session.ExpiresAt,
+	}
+	if err := db.Create(token).Error; err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshTokens troca o refresh token por um novo par. Cada refresh token vale para uma
+// única troca; reapresentar um token já usado indica vazamento e revoga a sessão inteira.
+func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
+	hash := s.jwt.HashRefreshToken(refreshToken)
+	now := time.Now().UTC()
+
+	var (
+		pair       *auth.TokenPair
+		reusedFrom *domain.UserSession
+	)
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var token domain.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hash).
+			First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidCredentials
+			}
+			return err
+		}
+		session, err := lockSession(tx, token.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.RevokedAt != nil || !now.Before(session.ExpiresAt) {
+			return ErrInvalidCredentials
+		}
+		if token.UsedAt != nil {
+			reusedFrom = session
+			return s.revokeSession(tx, session, domain.SessionRevokedReuse, now)
+		}
+		if !now.Before(token.ExpiresAt) {
+			return ErrInvalidCredentials
+		}
+
+		var user domain.User
+		if err := tx.Where("id = ? AND tenant_id = ?", session.UserID, session.TenantID).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidCredentials
+			}
+			return err
+		}
+		if !user.Active {
+			return ErrInvalidCredentials
+		}
+
+		if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		session.LastUsedAt = now
+		session.ExpiresAt = now.Add(s.jwt.RefreshTTL())
+		if err := tx.Model(session).Updates(map[string]interface{}{
+			"last_used_at": session.LastUsedAt,
+			"expires_at":   session.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		pair, err = s.issueSessionTokens(tx, &user, session)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reusedFrom != nil {
+		if s.logger != nil {
+			s.logger.Warn("refresh token reuse detected; session revoked",
+				zap.String("tenant_id", reusedFrom.TenantID.String()),
+				zap.String("user_id", reusedFrom.UserID.String()),
+				zap.String("session_id", reusedFrom.ID.String()))
+		}
+		return nil, ErrInvalidCredentials
+	}
+	return pair, nil
+}
+
+// Logout revoga a sessão do refresh token informado. Tokens desconhecidos ou de
+// sessões já encerradas são ignorados, tornando a operação idempotente.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	hash := s.jwt.HashRefreshToken(refreshToken)
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var token domain.RefreshToken
+		if err := tx.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+			return ignoreNotFound(err)
+		}
+		session, err := lockSession(tx, token.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.RevokedAt != nil {
+			return nil
+		}
+		return s.revokeSession(tx, session, domain.SessionRevokedLogout, time.Now().UTC())
+	})
+}
+
+// LogoutAll revoga todas as sessões ativas do usuário e devolve quantas foram encerradas.
+func (s *Service) LogoutAll(ctx context.Context, tenantID, userID uuid.UUID) (int, error) {
+	revoked := 0
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var sessions []domain.UserSession
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND user_id = ? AND revoked_at IS NULL", tenantID, userID).
+			Find(&sessions).Error; err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for i := range sessions {
+			if err := s.revokeSession(tx, &sessions[i], domain.SessionRevokedLogoutAll, now); err != nil {
+				return err
+			}
+		}
+		revoked = len(sessions)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revoked, nil
+}
+
+// ListUserSessions lista as sessões ativas do usuário, da mais recente para a mais antiga.
+// currentSessionID (opcional) identifica a sessão da requisição.
+func (s *Service) ListUserSessions(ctx context.Context, tenantID, userID, currentSessionID uuid.UUID) ([]SessionView, error) {
+	var sessions []domain.UserSession
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", tenantID, userID, time.Now().UTC()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, SessionView{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentSessionID != uuid.Nil && session.ID == currentSessionID,
+		})
+	}
+	return views, nil
+}
+
+// RevokeUserSession encerra uma sessão ativa do usuário.
+func (s *Service) RevokeUserSession(ctx context.Context, tenantID, userID, sessionID uuid.UUID) error {
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var session domain.UserSession
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND tenant_id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, tenantID, userID).
+			First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		return s.revokeSession(tx, &session, domain.SessionRevokedManual, time.Now().UTC())
+	})
+}
+
+// SessionActive indica se a sessão existe, não foi revogada e não expirou. O middleware
+// de autenticação a consulta a cada requisição, para que a revogação valha também para
+// os access tokens já emitidos.
+func (s *Service) SessionActive(ctx context.Context, sessionID string) (bool, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return false, nil
+	}
+	var count int64
+	if err := s.dbWithContext(ctx).Model(&domain.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now().UTC()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func lockSession(tx *gorm.DB, sessionID uuid.UUID) (*domain.UserSession, error) {
+	var session domain.UserSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", sessionID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *Service) revokeSession(tx *gorm.DB, session *domain.UserSession, reason string, now time.Time) error {
+	before := *session
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"revoked_at":     now,
+		"revoked_reason": reason,
+	}).Error; err != nil {
+		return err
+	}
+	return s.audit(tx, session.TenantID, AuditEntitySession, AuditActionUpdate, session.ID, &before, session)
+}
+
+// deviceFromUserAgent resume o user agent em "Navegador (Sistema)" para exibição.
+func deviceFromUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return ""
+	}
+	ua := strings.ToLower(userAgent)
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	system := ""
+	switch {
+	case strings.Contains(ua, "iphone"):
+		system = "iPhone"
+	case strings.Contains(ua, "ipad"):
+		system = "iPad"
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		system = "macOS"
+	case strings.Contains(ua, "cros"):
+		system = "ChromeOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " (" + system + ")"
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	// Clientes fora do navegador (apps, curl): usa o produto, como em "curl/8.4.0".
+	product := strings.Fields(userAgent)[0]
+	if name, _, ok := strings.Cut(product, "/"); ok {
+		product = name
+	}
+	if len(product) > 64 {
+		product = product[:64]
+	}
+	return product
+}
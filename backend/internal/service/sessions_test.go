@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const chromeWindowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+
+func TestDeviceFromUserAgent(t *testing.T) {
+	cases := map[string]string{
+		chromeWindowsUA: "Chrome (Windows)",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1": "Safari (iPhone)",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0":     "Edge (macOS)",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0":                                                                  "Firefox (Linux)",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36":                   "Chrome (Android)",
+		"curl/8.4.0": "curl",
+		"   ":        "",
+		"":           "",
+	}
+	for ua, expected := range cases {
+		assert.Equal(t, expected, deviceFromUserAgent(ua), ua)
+	}
+}
+
+func TestListUserSessionsShowsActiveSessions(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "sessions@example.com")
+
+	ctx := WithAuditActor(context.Background(), AuditActor{IP: "203.0.113.7", UserAgent: chromeWindowsUA})
+	_, current, err := svc.Login(ctx, user.Email, password)
+	require.NoError(t, err)
+	_, other, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	_, revoked, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	require.NoError(t, svc.Logout(context.Background(), revoked.RefreshToken))
+
+	claims, err := svc.jwt.ValidateAccessToken(current.AccessToken)
+	require.NoError(t, err)
+	currentID := uuid.MustParse(claims.SessionID)
+
+	sessions, err := svc.ListUserSessions(context.Background(), tenant.ID, user.ID, currentID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	var found *SessionView
+	for i := range sessions {
+		if sessions[i].ID == currentID {
+			found = &sessions[i]
+		} else {
+			assert.False(t, sessions[i].Current)
+		}
+	}
+	require.NotNil(t, found)
+	assert.True(t, found.Current)
+	assert.Equal(t, "Chrome (Windows)", found.Device)
+	assert.Equal(t, "203.0.113.7", found.IPAddress)
+	assert.False(t, found.CreatedAt.IsZero())
+
+	// A troca do refresh token atualiza o último uso e a sessão passa a ser a mais recente.
+	_, err = svc.RefreshTokens(context.Background(), other.RefreshToken)
+	require.NoError(t, err)
+	sessions, err = svc.ListUserSessions(context.Background(), tenant.ID, user.ID, uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.NotEqual(t, currentID, sessions[0].ID)
+	assert.True(t, sessions[0].LastUsedAt.After(sessions[1].LastUsedAt))
+}
+
+func TestRevokeUserSession(t *testing.T) {
+	clearAllData()
+	svc := newAuthTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "revoke@example.com")
+	other, _ := seedAuthUser(t, svc, tenant.ID, "other-revoke@example.com")
+
+	_, tokens, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+	claims, err := svc.jwt.ValidateAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	sessionID := uuid.MustParse(claims.SessionID)
+	active, err := svc.SessionActive(context.Background(), claims.SessionID)
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	err = svc.RevokeUserSession(context.Background(), tenant.ID, other.ID, sessionID)
+	assert.ErrorIs(t, err, ErrSessionNotFound, "sessão de outro usuário")
+
+	otherTenant, _ := createTestTenant()
+	err = svc.RevokeUserSession(context.Background(), otherTenant.ID, user.ID, sessionID)
+	assert.ErrorIs(t, err, ErrSessionNotFound, "sessão de outro tenant")
+
+	require.NoError(t, svc.RevokeUserSession(context.Background(), tenant.ID, user.ID, sessionID))
+	err = svc.RevokeUserSession(context.Background(), tenant.ID, user.ID, sessionID)
+	assert.ErrorIs(t, err, ErrSessionNotFound, "sessão já encerrada")
+
+	var session domain.UserSession
+	require.NoError(t, testDB.First(&session, "id = ?", sessionID).Error)
+	assert.Equal(t, domain.SessionRevokedManual, session.RevokedReason)
+
+	active, err = svc.SessionActive(context.Background(), claims.SessionID)
+	require.NoError(t, err)
+	assert.False(t, active, "access token da sessão revogada deixa de valer")
+
+	_, err = svc.RefreshTokens(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+const (
+	userTokenBytes              = 32
+	defaultPasswordResetTTL     = time.Hour
+	defaultEmailVerificationTTL = 48 * time.Hour
+
+	passwordResetPath     = "/reset-password"
+	emailVerificationPath = "/verify-email"
+)
+
+var (
+	// ErrInvalidToken sinaliza token de e-mail inexistente, expirado ou já utilizado.
+	ErrInvalidToken         = errors.New("token inválido ou expirado")
+	ErrEmailAlreadyVerified = errors.New("e-mail já verificado")
+)
+
+// RequestPasswordReset envia um link de redefinição para cada conta ativa com o e-mail.
+// A resposta não revela se a conta existe; links anteriores ainda não usados deixam de valer.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	var users []domain.User
+	if err := s.dbWithContext(ctx).
+		Where("lower(email) = ? AND active = ?", s.sanitizeEmail(email), true).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	for i := range users {
+		user := &users[i]
+		var token string
+		err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+			var err error
+			token, err = s.issueUserToken(tx, user, domain.UserTokenPasswordReset, s.passwordResetTTL())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		s.sendAccountEmail(ctx, user, domain.EmailTemplatePasswordReset, s.accountLink(passwordResetPath, token))
+	}
+	return nil
+}
+
+// ResetPassword troca a senha usando o token recebido por e-mail, encerra todas as
+// sessões do usuário e remove o bloqueio de login do e-mail. Como o link prova a posse
+// do e-mail, ele também é marcado como verificado.
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), s.cfg.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := consumeUserToken(tx, token, domain.UserTokenPasswordReset)
+		if err != nil {
+			return err
+		}
+		before := *user
+		now := time.Now().UTC()
+		updates := map[string]interface{}{"password_hash": string(passwordHash)}
+		if user.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = now
+		}
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		user.PasswordHash = string(passwordHash)
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+		}
+		if err := s.audit(tx, user.TenantID, AuditEntityUser, AuditActionUpdate, user.ID, before, auditedUser{*user, true}); err != nil {
+			return err
+		}
+		// A nova senha encerra também o bloqueio por tentativas erradas.
+		if err := s.resetLoginFailures(tx, user.Email); err != nil {
+			return err
+		}
+
+		var sessions []domain.UserSession
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND user_id = ? AND revoked_at IS NULL", user.TenantID, user.ID).
+			Find(&sessions).Error; err != nil {
+			return err
+		}
+		for i := range sessions {
+			if err := s.revokeSession(tx, &sessions[i], domain.SessionRevokedPassword, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// VerifyEmail confirma o e-mail do usuário com o token recebido por e-mail.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	return s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		user, err := consumeUserToken(tx, token, domain.UserTokenEmailVerification)
+		if err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+		before := *user
+		now := time.Now().UTC()
+		if err := tx.Model(user).Update("email_verified_at", now).Error; err != nil {
+			return err
+		}
+		user.EmailVerifiedAt = &now
+		return s.audit(tx, user.TenantID, AuditEntityUser, AuditActionUpdate, user.ID, before, user)
+	})
+}
+
+// SendEmailVerification reenvia o link de verificação ao usuário.
+func (s *Service) SendEmailVerification(ctx context.Context, tenantID, userID uuid.UUID) error {
+	var user domain.User
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, userID).
+		First(&user).Error; err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	var token string
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		token, err = s.issueUserToken(tx, &user, domain.UserTokenEmailVerification, s.emailVerificationTTL())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.sendEmailVerification(ctx, &user, token)
+	return nil
+}
+
+func (s *Service) sendEmailVerification(ctx context.Context, user *domain.User, token string) {
+	s.sendAccountEmail(ctx, user, domain.EmailTemplateEmailVerification, s.accountLink(emailVerificationPath, token))
+}
+
+// issueUserToken invalida os tokens pendentes da mesma finalidade e emite um novo.
+// Devolve o token em claro, que só existe no e-mail enviado.
+func (s *Service) issueUserToken(tx *gorm.DB, user *domain.User, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	if err := tx.Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, purpose).
+		Update("used_at", now).Error; err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, userTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	record := &domain.UserToken{
+		TenantModel: domain.TenantModel{TenantID: user.TenantID},
+		UserID:      user.ID,
+		Purpose:     purpose,
+		TokenHash:   hashUserToken(token),
+		ExpiresAt:   now.Add(ttl),
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken marca o token como usado e devolve o usuário dono dele.
+func consumeUserToken(tx *gorm.DB, token, purpose string) (*domain.User, error) {
+	var record domain.UserToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ? AND purpose = ?", hashUserToken(token), purpose).
+		First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if record.UsedAt != nil || !now.Before(record.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	var user domain.User
+	if err := tx.Where("tenant_id = ? AND id = ?", record.TenantID, record.UserID).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrInvalidToken
+	}
+	if err := tx.Model(&record).Update("used_at", now).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sendAccountEmail envia o e-mail transacional pelo notifier de e-mail registrado.
+// Falhas são apenas registradas: o fluxo que originou o envio já foi concluído.
+func (s *Service) sendAccountEmail(ctx context.Context, user *domain.User, key, link string) {
+	logger := s.logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	fields := []zap.Field{
+		zap.String("template", key),
+		zap.String("tenant_id", user.TenantID.String()),
+		zap.String("user_id", user.ID.String()),
+	}
+
+	notifier, ok := s.notifiers[notify.ChannelEmail]
+	if !ok {
+		logger.Warn("email notifier not configured; account email not sent", fields...)
+		return
+	}
+	msg, err := s.renderEmail(s.dbWithContext(ctx), user.TenantID, key, &EmailTemplateData{User: emailTemplateUser(user), Link: link})
+	if err != nil {
+		logger.Error("failed to render account email", append(fields, zap.Error(err))...)
+		return
+	}
+	msg.To = user.Email
+	if err := notifier.Send(ctx, msg); err != nil {
+		logger.Error("failed to send account email", append(fields, zap.Error(err))...)
+	}
+}
+
+func (s *Service) accountLink(path, token string) string {
+	return strings.TrimRight(s.cfg.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *Service) passwordResetTTL() time.Duration {
+	if s.cfg.PasswordResetTTL > 0 {
+		return s.cfg.PasswordResetTTL
+	}
+	return defaultPasswordResetTTL
+}
+
+func (s *Service) emailVerificationTTL() time.Duration {
+	if s.cfg.EmailVerificationTTL > 0 {
+		return s.cfg.EmailVerificationTTL
+	}
+	return defaultEmailVerificationTTL
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+	"github.com/kusmin/gestao_updev/backend/internal/notify"
+)
+
+func newMailTestService(t *testing.T) (*Service, *notify.Memory) {
+	t.Helper()
+	svc := newAuthTestService(t)
+	svc.cfg.AppBaseURL = "https://app.example.com/"
+	mailbox := notify.NewMemory(notify.ChannelEmail)
+	svc.RegisterNotifier(mailbox)
+	return svc, mailbox
+}
+
+// tokenFromMessage extrai o token do link enviado no corpo do e-mail.
+func tokenFromMessage(t *testing.T, msg notify.Message) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(msg.Body, "token=")
+	require.True(t, ok, "e-mail sem link: %s", msg.Body)
+	if end := strings.IndexAny(rest, " \n\"<"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	clearAllData()
+	svc, mailbox := newMailTestService(t)
+	tenant, _ := createTestTenant()
+	user, password := seedAuthUser(t, svc, tenant.ID, "reset@example.com")
+
+	_, login, err := svc.Login(context.Background(), user.Email, password)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), " RESET@example.com "))
+	messages := mailbox.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, user.Email, messages[0].To)
+	assert.Contains(t, messages[0].Body, "https://app.example.com/reset-password?token=")
+	token := tokenFromMessage(t, messages[0])
+
+	var stored domain.UserToken
+	require.NoError(t, testDB.Where("user_id = ?", user.ID).First(&stored).Error)
+	assert.NotEqual(t, token, stored.TokenHash, "apenas o hash é persistido")
+
+	require.NoError(t, svc.ResetPassword(context.Background(), token, "nova-senha-123"))
+
+	_, _, err = svc.Login(context.Background(), user.Email, password)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, _, err = svc.Login(context.Background(), user.Email, "nova-senha-123")
+	require.NoError(t, err)
+
+	_, err = svc.RefreshTokens(context.Background(), login.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "sessões anteriores são encerradas")
+
+	var updated domain.User
+	require.NoError(t, testDB.First(&updated, "id = ?", user.ID).Error)
+	assert.NotNil(t, updated.EmailVerifiedAt)
+
+	err = svc.ResetPassword(context.Background(), token, "outra-senha-123")
+	assert.ErrorIs(t, err, ErrInvalidToken, "o token vale uma única vez")
+}
+
+func TestPasswordResetRejectsExpiredAndReplacedTokens(t *testing.T) {
+	clearAllData()
+	svc, mailbox := newMailTestService(t)
+	tenant, _ := createTestTenant()
+	user, _ := seedAuthUser(t, svc, tenant.ID, "expired@example.com")
+
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), user.Email))
+	first := tokenFromMessage(t, mailbox.Messages()[0])
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), user.Email))
+	second := tokenFromMessage(t, mailbox.Messages()[1])
+
+	err := svc.ResetPassword(context.Background(), first, "nova-senha-123")
+	assert.ErrorIs(t, err, ErrInvalidToken, "um novo pedido invalida o link anterior")
+
+	require.NoError(t, testDB.Model(&domain.UserToken{}).
+		Where("token_hash = ?", hashUserToken(second)).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	err = svc.ResetPassword(context.Background(), second, "nova-senha-123")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	err = svc.ResetPassword(context.Background(), "inexistente", "nova-senha-123")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRequestPasswordResetUnknownEmail(t *testing.T) {
+	clearAllData()
+	svc, mailbox := newMailTestService(t)
+
+	require.NoError(t, svc.RequestPasswordReset(context.Background(), "ninguem@example.com"))
+	assert.Empty(t, mailbox.Messages())
+}
+
+func TestSignupSendsEmailVerification(t *testing.T) {
+	clearAllData()
+	svc, mailbox := newMailTestService(t)
+
+	result, err := svc.Signup(context.Background(), SignupInput{
+		CompanyName:     "Verifica",
+		CompanyDocument: "11222333000181",
+		UserName:        "Dona",
+		UserEmail:       "dona@example.com",
+		UserPassword:    "senha-forte-1",
+	})
+	require.NoError(t, err)
+
+	messages := mailbox.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "dona@example.com", messages[0].To)
+	assert.Contains(t, messages[0].Body, "https://app.example.com/verify-email?token=")
+	token := tokenFromMessage(t, messages[0])
+
+	require.NoError(t, svc.VerifyEmail(context.Background(), token))
+	var user domain.User
+	require.NoError(t, testDB.First(&user, "id = ?", result.UserID).Error)
+	assert.NotNil(t, user.EmailVerifiedAt)
+
+	assert.ErrorIs(t, svc.VerifyEmail(context.Background(), token), ErrInvalidToken)
+	err = svc.SendEmailVerification(context.Background(), user.TenantID, user.ID)
+	assert.ErrorIs(t, err, ErrEmailAlreadyVerified)
+}
+
+func TestCreateUserSendsEmailVerification(t *testing.T) {
+	clearAllData()
+	svc, mailbox := newMailTestService(t)
+	tenant, _ := createTestTenant()
+
+	user, err := svc.CreateUser(context.Background(), tenant.ID, CreateUserInput{
+		Name:     "Membro",
+		Email:    "membro@example.com",
+		Password: "senha-forte-1",
+		Role:     "member",
+	})
+	require.NoError(t, err)
+	require.Len(t, mailbox.Messages(), 1)
+	first := tokenFromMessage(t, mailbox.Messages()[0])
+
+	require.NoError(t, svc.SendEmailVerification(context.Background(), tenant.ID, user.ID))
+	require.Len(t, mailbox.Messages(), 2)
+	second := tokenFromMessage(t, mailbox.Messages()[1])
+
+	assert.ErrorIs(t, svc.VerifyEmail(context.Background(), first), ErrInvalidToken, "o reenvio invalida o link anterior")
+	require.NoError(t, svc.VerifyEmail(context.Background(), second))
+}
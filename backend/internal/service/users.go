@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"github.com/kusmin/gestao_updev/backend/internal/domain"
 )
@@ -34,6 +35,12 @@ type UpdateUserInput struct {
 	Password *string
 }
 
+// auditedUser acrescenta ao diff a troca de senha, já que o hash não é serializado.
+type auditedUser struct {
+	domain.User
+	PasswordChanged bool `json:"password_changed,omitempty"`
+}
+
 // ListUsers retorna usuários do tenant com paginação.
 func (s *Service) ListUsers(ctx context.Context, tenantID uuid.UUID, filter UsersFilter) ([]domain.User, int64, error) {
 	var users []domain.User
@@ -127,18 +134,30 @@ func (s *Service) CreateUser(ctx context.Context, tenantID uuid.UUID, input Crea
 		Active:       true,
 	}
 
-	if err := s.dbWithContext(ctx).Create(user).Error; err != nil {
+	var verificationToken string
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityUser, AuditActionCreate, user.ID, nil, user); err != nil {
+			return err
+		}
+		verificationToken, err = s.issueUserToken(tx, user, domain.UserTokenEmailVerification, s.emailVerificationTTL())
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.sendEmailVerification(ctx, user, verificationToken)
 	return user, nil
 }
 
 // UpdateUser altera campos selecionados de um usuário existente.
 func (s *Service) UpdateUser(ctx context.Context, tenantID, userID uuid.UUID, input UpdateUserInput) (*domain.User, error) {
-	var user domain.User
+	var before, user domain.User
 	if err := s.dbWithContext(ctx).
 		Where("tenant_id = ? AND id = ?", tenantID, userID).
-		First(&user).Error; err != nil {
+		First(&before).Error; err != nil {
 		return nil, err
 	}
 
@@ -164,19 +183,25 @@ func (s *Service) UpdateUser(ctx context.Context, tenantID, userID uuid.UUID, in
 	}
 
 	if len(updates) == 0 {
-		return &user, nil
+		return &before, nil
 	}
 
-	if err := s.dbWithContext(ctx).
-		Model(&domain.User{}).
-		Where("tenant_id = ? AND id = ?", tenantID, userID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, userID).
-		First(&user).Error; err != nil {
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.User{}).
+			Where("tenant_id = ? AND id = ?", tenantID, userID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, userID).
+			First(&user).Error; err != nil {
+			return err
+		}
+		_, passwordChanged := updates["password_hash"]
+		return s.audit(tx, tenantID, AuditEntityUser, AuditActionUpdate, userID, before, auditedUser{user, passwordChanged})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -185,83 +210,28 @@ func (s *Service) UpdateUser(ctx context.Context, tenantID, userID uuid.UUID, in
 
 // DeleteUser realiza soft delete do usuário.
 func (s *Service) DeleteUser(ctx context.Context, tenantID, userID uuid.UUID) error {
-	return s.dbWithContext(ctx).
-		Where("tenant_id = ? AND id = ?", tenantID, userID).
-		Delete(&domain.User{}).Error
+	return s.deleteTenantRecord(ctx, tenantID, userID, &domain.User{}, AuditEntityUser)
 }
 
 // AdminCreateUser adds a new user to a specific tenant.
 func (s *Service) AdminCreateUser(ctx context.Context, input CreateUserInput, tenantID uuid.UUID) (*domain.User, error) {
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(input.Password), s.cfg.BcryptCost)
-	if err != nil {
-		return nil, err
-	}
-
-	user := &domain.User{
-		TenantModel: domain.TenantModel{
-			TenantID: tenantID,
-		},
-		Name:         input.Name,
-		Email:        s.sanitizeEmail(input.Email),
-		Phone:        input.Phone,
-		Role:         input.Role,
-		PasswordHash: string(passwordHash),
-		Active:       true,
-	}
-
-	if err := s.dbWithContext(ctx).Create(user).Error; err != nil {
-		return nil, err
-	}
-	return user, nil
+	return s.CreateUser(ctx, tenantID, input)
 }
 
 // AdminUpdateUser updates selected fields of an existing user.
 func (s *Service) AdminUpdateUser(ctx context.Context, userID uuid.UUID, input UpdateUserInput) (*domain.User, error) {
-	var user domain.User
-	if err := s.dbWithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
-		return nil, err
-	}
-
-	updates := map[string]interface{}{}
-	if input.Name != nil {
-		updates["name"] = *input.Name
-	}
-	if input.Phone != nil {
-		updates["phone"] = *input.Phone
-	}
-	if input.Role != nil {
-		updates["role"] = *input.Role
-	}
-	if input.Active != nil {
-		updates["active"] = *input.Active
-	}
-	if input.Password != nil && *input.Password != "" {
-		hash, err := bcrypt.GenerateFromPassword([]byte(*input.Password), s.cfg.BcryptCost)
-		if err != nil {
-			return nil, err
-		}
-		updates["password_hash"] = string(hash)
-	}
-
-	if len(updates) == 0 {
-		return &user, nil
-	}
-
-	if err := s.dbWithContext(ctx).
-		Model(&domain.User{}).
-		Where("id = ?", userID).
-		Updates(updates).Error; err != nil {
-		return nil, err
-	}
-
-	if err := s.dbWithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+	tenantID, err := s.recordTenant(ctx, &domain.User{}, userID)
+	if err != nil {
 		return nil, err
 	}
-
-	return &user, nil
+	return s.UpdateUser(ctx, tenantID, userID, input)
 }
 
 // AdminDeleteUser soft deletes a user.
 func (s *Service) AdminDeleteUser(ctx context.Context, userID uuid.UUID) error {
-	return s.dbWithContext(ctx).Delete(&domain.User{}, "id = ?", userID).Error
+	tenantID, err := s.recordTenant(ctx, &domain.User{}, userID)
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	return s.DeleteUser(ctx, tenantID, userID)
 }
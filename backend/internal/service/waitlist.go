@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const defaultWaitlistHold = 30 * time.Minute
+
+var (
+	ErrInvalidWaitlistEntry     = errors.New("entrada de lista de espera inválida")
+	ErrWaitlistOfferUnavailable = errors.New("oferta da lista de espera indisponível ou expirada")
+)
+
+// WaitlistInput payload de inclusão na lista de espera.
+type WaitlistInput struct {
+	ClientID       uuid.UUID
+	ServiceID      uuid.UUID
+	ProfessionalID *uuid.UUID
+	DesiredFrom    time.Time
+	DesiredTo      time.Time
+	Notes          string
+}
+
+// ListWaitlist lista as entradas em ordem de chegada.
+func (s *Service) ListWaitlist(ctx context.Context, tenantID uuid.UUID, status string) ([]domain.WaitlistEntry, error) {
+	query := s.dbWithContext(ctx).Where("tenant_id = ?", tenantID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var entries []domain.WaitlistEntry
+	if err := query.Order("created_at ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *Service) CreateWaitlistEntry(ctx context.Context, tenantID uuid.UUID, input WaitlistInput) (*domain.WaitlistEntry, error) {
+	if !input.DesiredTo.After(input.DesiredFrom) {
+		return nil, ErrInvalidWaitlistEntry
+	}
+	if err := s.ensureTenantRecord(ctx, &domain.Client{}, tenantID, input.ClientID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureTenantRecord(ctx, &domain.Service{}, tenantID, input.ServiceID); err != nil {
+		return nil, err
+	}
+	if input.ProfessionalID != nil {
+		if err := s.ensureTenantRecord(ctx, &domain.Professional{}, tenantID, *input.ProfessionalID); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &domain.WaitlistEntry{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		ClientID:       input.ClientID,
+		ServiceID:      input.ServiceID,
+		ProfessionalID: input.ProfessionalID,
+		DesiredFrom:    input.DesiredFrom.UTC(),
+		DesiredTo:      input.DesiredTo.UTC(),
+		Status:         domain.WaitlistStatusWaiting,
+		Notes:          input.Notes,
+	}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityWaitlistEntry, AuditActionCreate, entry.ID, nil, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CancelWaitlistEntry retira o cliente da fila. Se havia uma vaga reservada para ele,
+// ela é repassada ao próximo cliente elegível.
+func (s *Service) CancelWaitlistEntry(ctx context.Context, tenantID, entryID uuid.UUID) (*domain.WaitlistEntry, error) {
+	var entry domain.WaitlistEntry
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, entryID).
+			First(&entry).Error; err != nil {
+			return err
+		}
+		if entry.Status != domain.WaitlistStatusWaiting && entry.Status != domain.WaitlistStatusOffered {
+			return &InvalidTransitionError{Entity: "lista de espera", From: entry.Status, To: domain.WaitlistStatusCanceled}
+		}
+
+		before := entry
+		previous := entry.Status
+		if err := tx.Model(&entry).Update("status", domain.WaitlistStatusCanceled).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityWaitlistEntry, AuditActionUpdate, entryID, before, entry); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if previous == domain.WaitlistStatusOffered && entry.OfferExpiresAt != nil && entry.OfferExpiresAt.After(now) {
+			return s.offerWaitlistSlot(tx, tenantID, *entry.OfferProfessionalID, entry.ServiceID, *entry.OfferStartAt, *entry.OfferEndAt, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// AcceptWaitlistOffer converte a vaga reservada em agendamento enquanto a reserva é válida.
+func (s *Service) AcceptWaitlistOffer(ctx context.Context, tenantID, entryID uuid.UUID) (*domain.Booking, error) {
+	var booking *domain.Booking
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		var entry domain.WaitlistEntry
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, entryID).
+			First(&entry).Error; err != nil {
+			return err
+		}
+		if entry.Status != domain.WaitlistStatusOffered || entry.OfferExpiresAt == nil || !entry.OfferExpiresAt.After(time.Now().UTC()) {
+			return ErrWaitlistOfferUnavailable
+		}
+
+		var err error
+		booking, err = s.newBooking(ctx, tenantID, BookingInput{
+			ClientID:       entry.ClientID,
+			ProfessionalID: *entry.OfferProfessionalID,
+			ServiceID:      entry.ServiceID,
+			StartAt:        *entry.OfferStartAt,
+			EndAt:          entry.OfferEndAt,
+			Notes:          entry.Notes,
+		})
+		if err != nil {
+			return err
+		}
+
+		// A reserva deixa de ocupar a agenda antes da verificação de capacidade.
+		before := entry
+		if err := tx.Model(&entry).Update("status", domain.WaitlistStatusBooked).Error; err != nil {
+			return err
+		}
+		if err := s.checkSchedule(ctx, tx, tenantID, booking.ProfessionalID, booking.StartAt, booking.EndAt, nil, false); err != nil {
+			return err
+		}
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		if err := s.audit(tx, tenantID, AuditEntityBooking, AuditActionCreate, booking.ID, nil, booking); err != nil {
+			return err
+		}
+		if err := s.publish(tx, tenantID, EventBookingCreated, booking); err != nil {
+			return err
+		}
+		if err := tx.Model(&entry).Update("booking_id", booking.ID).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityWaitlistEntry, AuditActionUpdate, entryID, before, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// ExpireWaitlistOffers encerra reservas vencidas e repassa cada vaga ao próximo da fila.
+func (s *Service) ExpireWaitlistOffers(ctx context.Context, now time.Time) (int, error) {
+	var expired []domain.WaitlistEntry
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND offer_expires_at <= ?", domain.WaitlistStatusOffered, now).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		for _, entry := range expired {
+			if err := tx.Model(&domain.WaitlistEntry{}).
+				Where("id = ?", entry.ID).
+				Update("status", domain.WaitlistStatusExpired).Error; err != nil {
+				return err
+			}
+			if err := s.offerWaitlistSlot(tx, entry.TenantID, *entry.OfferProfessionalID, entry.ServiceID, *entry.OfferStartAt, *entry.OfferEndAt, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// releaseBookingSlot oferece à lista de espera o horário de agendamentos cancelados ou sem comparecimento.
+func (s *Service) releaseBookingSlot(tx *gorm.DB, booking *domain.Booking, from, to string) error {
+	if !isInactiveBookingStatus(to) || isInactiveBookingStatus(from) {
+		return nil
+	}
+	return s.releaseBookingSlots(tx, booking)
+}
+
+// releaseBookingSlots oferece cada etapa do agendamento (ou o agendamento inteiro, quando
+// simples) à lista de espera, com os horários atuais do agendamento recebido.
+func (s *Service) releaseBookingSlots(tx *gorm.DB, booking *domain.Booking) error {
+	if booking.Segments == nil {
+		if err := loadSegments(tx, booking); err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	if len(booking.Segments) == 0 {
+		return s.offerWaitlistSlot(tx, booking.TenantID, booking.ProfessionalID, booking.ServiceID, booking.StartAt, booking.EndAt, now)
+	}
+	for _, segment := range booking.Segments {
+		if err := s.offerWaitlistSlot(tx, booking.TenantID, segment.ProfessionalID, segment.ServiceID, segment.StartAt, segment.EndAt, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offerWaitlistSlot reserva o horário liberado para a entrada mais antiga compatível: mesmo
+// serviço, profissional indiferente ou igual e janela desejada que contenha o horário.
+func (s *Service) offerWaitlistSlot(tx *gorm.DB, tenantID, professionalID, serviceID uuid.UUID, start, end, now time.Time) error {
+	if !start.After(now) {
+		return nil
+	}
+
+	var entries []domain.WaitlistEntry
+	if err := tx.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("tenant_id = ? AND status = ? AND service_id = ?", tenantID, domain.WaitlistStatusWaiting, serviceID).
+		Where("professional_id IS NULL OR professional_id = ?", professionalID).
+		Where("desired_from <= ? AND desired_to >= ?", start, end).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&entries).Error; err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	settings, err := s.companySettings(tx, tenantID)
+	if err != nil {
+		return err
+	}
+	hold := time.Duration(settingInt(settings, domain.SettingWaitlistHold)) * time.Minute
+	if hold <= 0 {
+		hold = defaultWaitlistHold
+	}
+
+	return tx.Model(&domain.WaitlistEntry{}).
+		Where("id = ?", entries[0].ID).
+		Updates(map[string]interface{}{
+			"status":                domain.WaitlistStatusOffered,
+			"offer_professional_id": professionalID,
+			"offer_start_at":        start,
+			"offer_end_at":          end,
+			"offer_expires_at":      now.Add(hold),
+		}).Error
+}
+
+// activeHolds devolve as reservas vigentes do profissional no intervalo como agendamentos
+// sintéticos, para que contem na capacidade da agenda.
+func activeHolds(db *gorm.DB, tenantID, professionalID uuid.UUID, start, end, now time.Time) ([]domain.Booking, error) {
+	var entries []domain.WaitlistEntry
+	if err := db.
+		Where("tenant_id = ? AND status = ? AND offer_professional_id = ? AND offer_expires_at > ?",
+			tenantID, domain.WaitlistStatusOffered, professionalID, now).
+		Where("offer_start_at < ? AND offer_end_at > ?", end, start).
+		Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	holds := make([]domain.Booking, len(entries))
+	for i, entry := range entries {
+		holds[i] = domain.Booking{StartAt: *entry.OfferStartAt, EndAt: *entry.OfferEndAt}
+	}
+	return holds, nil
+}
+
+func isInactiveBookingStatus(status string) bool {
+	for _, inactive := range bookingInactiveStatuses {
+		if status == inactive {
+			return true
+		}
+	}
+	return false
+}
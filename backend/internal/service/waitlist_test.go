@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestWaitlistOffersFreedSlot(t *testing.T) {
+	setupTest(t)
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	holder := seedClientRecord(t, tenant.ID, "Holder", "holder@example.com", nil)
+	first := seedClientRecord(t, tenant.ID, "First", "first@example.com", nil)
+	second := seedClientRecord(t, tenant.ID, "Second", "second@example.com", nil)
+	pro := seedProfessionalRecord(t, tenant.ID, "Pro Waitlist")
+	service := seedServiceRecord(t, tenant.ID, "Corte", 60)
+
+	start := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+	booking, err := testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       holder.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        start,
+	})
+	require.NoError(t, err)
+
+	window := WaitlistInput{ServiceID: service.ID, DesiredFrom: start.Add(-2 * time.Hour), DesiredTo: start.Add(2 * time.Hour)}
+	window.ClientID = first.ID
+	firstEntry, err := testSvc.CreateWaitlistEntry(ctx, tenant.ID, window)
+	require.NoError(t, err)
+	window.ClientID = second.ID
+	secondEntry, err := testSvc.CreateWaitlistEntry(ctx, tenant.ID, window)
+	require.NoError(t, err)
+
+	_, err = testSvc.CancelBooking(ctx, tenant.ID, booking.ID, "")
+	require.NoError(t, err)
+
+	var offered domain.WaitlistEntry
+	require.NoError(t, testDB.First(&offered, "id = ?", firstEntry.ID).Error)
+	require.Equal(t, domain.WaitlistStatusOffered, offered.Status)
+	require.NotNil(t, offered.OfferExpiresAt)
+	assert.True(t, offered.OfferStartAt.Equal(start))
+
+	// A vaga reservada não pode ser tomada por outro cliente.
+	_, err = testSvc.CreateBooking(ctx, tenant.ID, BookingInput{
+		ClientID:       holder.ID,
+		ProfessionalID: pro.ID,
+		ServiceID:      service.ID,
+		StartAt:        start,
+	})
+	require.ErrorIs(t, err, ErrBookingConflict)
+
+	// Reserva vencida passa para o próximo da fila.
+	expired, err := testSvc.ExpireWaitlistOffers(ctx, offered.OfferExpiresAt.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	var next domain.WaitlistEntry
+	require.NoError(t, testDB.First(&next, "id = ?", secondEntry.ID).Error)
+	require.Equal(t, domain.WaitlistStatusOffered, next.Status)
+
+	accepted, err := testSvc.AcceptWaitlistOffer(ctx, tenant.ID, secondEntry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, accepted.ClientID)
+	assert.True(t, accepted.StartAt.Equal(start))
+
+	_, err = testSvc.AcceptWaitlistOffer(ctx, tenant.ID, firstEntry.ID)
+	assert.ErrorIs(t, err, ErrWaitlistOfferUnavailable)
+}
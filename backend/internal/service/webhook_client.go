@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// errWebhookTargetForbidden recusa destinos de webhook na rede interna.
+var errWebhookTargetForbidden = errors.New("destino do webhook não permitido")
+
+// webhookBlockedNetworks complementa os intervalos reconhecidos por net.IP (loopback,
+// privados, link-local) com os demais endereços que não devem receber webhooks.
+var webhookBlockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "esta rede"
+	"100.64.0.0/10", // CGNAT, usado também por metadados de nuvem
+	"192.0.0.0/24",  // atribuições de protocolo IETF
+	"198.18.0.0/15", // testes de desempenho
+	"240.0.0.0/4",   // reservado
+	"64:ff9b::/96",  // NAT64, pode apontar para IPv4 interno
+)
+
+// newWebhookClient monta o cliente das entregas. O destino é conferido na conexão, já
+// com o IP resolvido, para que uma troca de DNS não leve a entrega à rede interna;
+// redirecionamentos não são seguidos e proxies de ambiente são ignorados.
+func (s *Service) newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: s.webhookDialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *Service) webhookDialControl(_, address string, _ syscall.RawConn) error {
+	if s.cfg.WebhookAllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", errWebhookTargetForbidden, host)
+	}
+	return nil
+}
+
+// validateWebhookURL exige HTTPS fora do ambiente de desenvolvimento e recusa de
+// antemão hosts internos informados como IP ou localhost.
+func (s *Service) validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return ErrInvalidWebhook
+	}
+	switch parsed.Scheme {
+	case "https":
+	case "http":
+		if !s.cfg.IsDevelopment() {
+			return ErrInvalidWebhook
+		}
+	default:
+		return ErrInvalidWebhook
+	}
+	if s.cfg.WebhookAllowPrivate {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidWebhook
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicWebhookIP(ip) {
+		return ErrInvalidWebhook
+	}
+	return nil
+}
+
+func isPublicWebhookIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range webhookBlockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+const (
+	webhookMaxAttempts   = 8
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = 6 * time.Hour
+	webhookBatchSize     = 50
+	webhookDeliveryLease = 2 * time.Minute
+	webhookTimeout       = 10 * time.Second
+)
+
+// Cabeçalhos enviados em cada entrega de webhook.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var ErrInvalidWebhook = errors.New("webhook inválido")
+
+// errWebhookDisabled encerra a entrega sem novas tentativas.
+var errWebhookDisabled = errors.New("assinatura de webhook removida ou inativa")
+
+// WebhookInput dados editáveis da assinatura. Sem Secret, a criação gera um segredo
+// e a atualização mantém o atual.
+type WebhookInput struct {
+	URL         string
+	Secret      string
+	EventTypes  []string
+	Description string
+	Active      *bool
+}
+
+// WebhookDeliveryFilter filtros da listagem de entregas (status dead = dead-letter).
+type WebhookDeliveryFilter struct {
+	Status         string
+	SubscriptionID *uuid.UUID
+	Page           int
+	PerPage        int
+}
+
+// ListWebhooks lista as assinaturas do tenant sem expor os segredos.
+func (s *Service) ListWebhooks(ctx context.Context, tenantID uuid.UUID) ([]domain.WebhookSubscription, error) {
+	var subscriptions []domain.WebhookSubscription
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// CreateWebhook cadastra a assinatura. O segredo só é devolvido nesta resposta.
+func (s *Service) CreateWebhook(ctx context.Context, tenantID uuid.UUID, input WebhookInput) (*domain.WebhookSubscription, error) {
+	if err := s.validateWebhook(input); err != nil {
+		return nil, err
+	}
+	secret := input.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	subscription := &domain.WebhookSubscription{
+		TenantModel: domain.TenantModel{
+			TenantID: tenantID,
+		},
+		URL:         input.URL,
+		Secret:      secret,
+		EventTypes:  marshalTags(input.EventTypes),
+		Description: input.Description,
+		Active:      input.Active == nil || *input.Active,
+	}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Create(subscription).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityWebhookSubscription, AuditActionCreate, subscription.ID, nil, subscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *Service) UpdateWebhook(ctx context.Context, tenantID, webhookID uuid.UUID, input WebhookInput) (*domain.WebhookSubscription, error) {
+	if err := s.validateWebhook(input); err != nil {
+		return nil, err
+	}
+	var before, subscription domain.WebhookSubscription
+	if err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", tenantID, webhookID).
+		First(&before).Error; err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"url":         input.URL,
+		"event_types": marshalTags(input.EventTypes),
+		"description": input.Description,
+	}
+	if input.Secret != "" {
+		updates["secret"] = input.Secret
+	}
+	if input.Active != nil {
+		updates["active"] = *input.Active
+	}
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Model(&domain.WebhookSubscription{}).
+			Where("tenant_id = ? AND id = ?", tenantID, webhookID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("tenant_id = ? AND id = ?", tenantID, webhookID).
+			First(&subscription).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityWebhookSubscription, AuditActionUpdate, webhookID, before, subscription)
+	})
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return &subscription, nil
+}
+
+// DeleteWebhook remove a assinatura; entregas pendentes dela vão para dead.
+func (s *Service) DeleteWebhook(ctx context.Context, tenantID, webhookID uuid.UUID) error {
+	return s.deleteTenantRecord(ctx, tenantID, webhookID, &domain.WebhookSubscription{}, AuditEntityWebhookSubscription)
+}
+
+func (s *Service) ListWebhookDeliveries(ctx context.Context, tenantID uuid.UUID, filter WebhookDeliveryFilter) ([]domain.WebhookDelivery, error) {
+	page, perPage := s.clampPagination(filter.Page, filter.PerPage)
+	query := s.dbWithContext(ctx).Where("tenant_id = ?", tenantID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SubscriptionID != nil {
+		query = query.Where("subscription_id = ?", *filter.SubscriptionID)
+	}
+
+	var deliveries []domain.WebhookDelivery
+	if err := query.
+		Order("created_at DESC").
+		Limit(perPage).
+		Offset((page - 1) * perPage).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery devolve uma entrega dead à fila, com as tentativas zeradas.
+func (s *Service) RetryWebhookDelivery(ctx context.Context, tenantID, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("tenant_id = ? AND id = ?", tenantID, deliveryID).
+			First(&delivery).Error; err != nil {
+			return err
+		}
+		if delivery.Status != domain.WebhookDeliveryDead {
+			return &InvalidTransitionError{Entity: "entrega de webhook", From: delivery.Status, To: domain.WebhookDeliveryPending}
+		}
+		before := delivery
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.Attempts = 0
+		delivery.NextAttemptAt = time.Now().UTC()
+		if err := tx.Model(&delivery).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+		}).Error; err != nil {
+			return err
+		}
+		return s.audit(tx, tenantID, AuditEntityWebhookDelivery, AuditActionUpdate, deliveryID, before, delivery)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// DeliverWebhooks envia as entregas pendentes vencidas. O lote é reservado com SKIP LOCKED
+// e um lease em next_attempt_at, para que várias instâncias possam despachar em paralelo.
+func (s *Service) DeliverWebhooks(ctx context.Context, now time.Time) (int, error) {
+	var deliveries []domain.WebhookDelivery
+	err := s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(webhookBatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&domain.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(webhookDeliveryLease)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		if err := s.attemptWebhookDelivery(ctx, &deliveries[i], now); err != nil {
+			return i, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attemptWebhookDelivery envia a entrega e registra o resultado: delivered em respostas 2xx,
+// nova tentativa com backoff exponencial em falhas e dead ao esgotar as tentativas.
+func (s *Service) attemptWebhookDelivery(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) error {
+	attempts := delivery.Attempts + 1
+	statusCode, err := s.sendWebhook(ctx, delivery)
+
+	updates := map[string]interface{}{
+		"attempts":         attempts,
+		"last_status_code": statusCode,
+	}
+	switch {
+	case err == nil:
+		updates["status"] = domain.WebhookDeliveryDelivered
+		updates["delivered_at"] = now
+		updates["last_error"] = ""
+	case errors.Is(err, errWebhookDisabled) || attempts >= webhookMaxAttempts:
+		updates["status"] = domain.WebhookDeliveryDead
+		updates["last_error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(webhookBackoff(attempts))
+		updates["last_error"] = err.Error()
+	}
+	return s.dbWithContext(ctx).
+		Model(&domain.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(updates).Error
+}
+
+func (s *Service) sendWebhook(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	var subscription domain.WebhookSubscription
+	err := s.dbWithContext(ctx).
+		Where("tenant_id = ? AND id = ?", delivery.TenantID, delivery.SubscriptionID).
+		First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !subscription.Active) {
+		return 0, errWebhookDisabled
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, time.Now().Unix(), body))
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("resposta HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// enqueueWebhooks grava na outbox uma entrega por assinatura ativa interessada no evento.
+func (s *Service) enqueueWebhooks(tx *gorm.DB, event Event) error {
+	var subscriptions []domain.WebhookSubscription
+	if err := tx.
+		Where("tenant_id = ? AND active = ?", event.TenantID, true).
+		Where("event_types @> ? OR event_types @> ?", marshalTags([]string{event.Type}), marshalTags([]string{EventAll})).
+		Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, subscription := range subscriptions {
+		delivery := domain.WebhookDelivery{
+			TenantModel: domain.TenantModel{
+				TenantID: event.TenantID,
+			},
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  event.OccurredAt,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignWebhookPayload monta o cabeçalho de assinatura no formato "t=<unix>,v1=<hex>", em que
+// v1 é o HMAC-SHA256 de "<unix>.<corpo>" com o segredo da assinatura.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// webhookBackoff dobra o intervalo a cada tentativa, até webhookMaxBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func (s *Service) validateWebhook(input WebhookInput) error {
+	if err := s.validateWebhookURL(input.URL); err != nil {
+		return err
+	}
+	if len(input.EventTypes) == 0 {
+		return ErrInvalidWebhook
+	}
+	for _, eventType := range input.EventTypes {
+		if !isEventType(eventType) {
+			return ErrInvalidWebhook
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
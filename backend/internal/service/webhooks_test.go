@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kusmin/gestao_updev/backend/internal/config"
+	"github.com/kusmin/gestao_updev/backend/internal/domain"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxBackoff, webhookBackoff(20))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"type":"client.created"}`)
+	signature := SignWebhookPayload("segredo", 1700000000, body)
+
+	assert.True(t, strings.HasPrefix(signature, "t=1700000000,v1="))
+	assert.Equal(t, signature, SignWebhookPayload("segredo", 1700000000, body))
+	assert.NotEqual(t, signature, SignWebhookPayload("outro", 1700000000, body))
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	dev := &Service{cfg: &config.Config{AppEnv: "development"}}
+	prod := &Service{cfg: &config.Config{AppEnv: "production"}}
+
+	assert.NoError(t, prod.validateWebhookURL("https://hooks.example.com/updev"))
+	assert.NoError(t, dev.validateWebhookURL("http://hooks.example.com/updev"))
+	assert.ErrorIs(t, prod.validateWebhookURL("http://hooks.example.com/updev"), ErrInvalidWebhook, "HTTPS obrigatório fora do desenvolvimento")
+	for _, target := range []string{
+		"https://localhost:8080/hook",
+		"https://127.0.0.1/hook",
+		"https://10.1.2.3/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[::1]/hook",
+		"https://100.100.100.200/hook",
+		"ftp://hooks.example.com",
+	} {
+		assert.ErrorIs(t, prod.validateWebhookURL(target), ErrInvalidWebhook, target)
+	}
+}
+
+func TestWebhookClientRefusesInternalTargets(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	// A conexão é recusada mesmo para assinaturas gravadas antes da validação do cadastro.
+	svc := New(&config.Config{}, nil, nil, nil)
+	_, err := svc.webhookClient.Post(server.URL, "application/json", nil)
+	assert.ErrorIs(t, err, errWebhookTargetForbidden)
+
+	svc.cfg.WebhookAllowPrivate = true
+	resp, err := svc.webhookClient.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode, "redirecionamentos não são seguidos")
+	assert.False(t, redirected)
+}
+
+// webhookReceiver registra as entregas recebidas e valida a assinatura.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	status   int
+	received []map[string]interface{}
+	headers  []http.Header
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	signature := req.Header.Get(WebhookSignatureHeader)
+	timestamp, _ := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+	if signature != SignWebhookPayload(r.secret, timestamp, body) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var payload map[string]interface{}
+	_ = json.Unmarshal(body, &payload)
+	r.received = append(r.received, payload)
+	r.headers = append(r.headers, req.Header.Clone())
+	w.WriteHeader(r.status)
+}
+
+func TestWebhookDeliveryFlow(t *testing.T) {
+	setupTest(t)
+	// O receptor de teste escuta em 127.0.0.1.
+	testSvc.cfg.WebhookAllowPrivate = true
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	receiver := &webhookReceiver{secret: "segredo-teste", status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	subscription, err := testSvc.CreateWebhook(ctx, tenant.ID, WebhookInput{
+		URL:        server.URL,
+		Secret:     receiver.secret,
+		EventTypes: []string{EventClientCreated},
+	})
+	require.NoError(t, err)
+
+	client, err := testSvc.CreateClient(ctx, tenant.ID, ClientInput{Name: "Webhook Client", Email: "webhook@example.com"})
+	require.NoError(t, err)
+	// Eventos não assinados não geram entregas.
+	_, err = testSvc.UpdateClient(ctx, tenant.ID, client.ID, ClientInput{Name: "Renamed"})
+	require.NoError(t, err)
+
+	processed, err := testSvc.DeliverWebhooks(ctx, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	require.Len(t, receiver.received, 1)
+	assert.Equal(t, EventClientCreated, receiver.received[0]["type"])
+	assert.Equal(t, EventClientCreated, receiver.headers[0].Get(WebhookEventHeader))
+	data := receiver.received[0]["data"].(map[string]interface{})
+	assert.Equal(t, client.ID.String(), data["id"])
+
+	deliveries, err := testSvc.ListWebhookDeliveries(ctx, tenant.ID, WebhookDeliveryFilter{SubscriptionID: &subscription.ID})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.WebhookDeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+}
+
+func TestWebhookDeliveryRetriesUntilDead(t *testing.T) {
+	setupTest(t)
+	// O receptor de teste escuta em 127.0.0.1.
+	testSvc.cfg.WebhookAllowPrivate = true
+	tenant, _ := createTestTenant()
+	ctx := context.Background()
+	receiver := &webhookReceiver{secret: "segredo-falha", status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	_, err := testSvc.CreateWebhook(ctx, tenant.ID, WebhookInput{
+		URL:        server.URL,
+		Secret:     receiver.secret,
+		EventTypes: []string{EventAll},
+	})
+	require.NoError(t, err)
+	_, err = testSvc.CreateClient(ctx, tenant.ID, ClientInput{Name: "Retry Client"})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	_, err = testSvc.DeliverWebhooks(ctx, now)
+	require.NoError(t, err)
+
+	var delivery domain.WebhookDelivery
+	require.NoError(t, testDB.First(&delivery, "tenant_id = ?", tenant.ID).Error)
+	assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.LastStatusCode)
+	assert.WithinDuration(t, now.Add(webhookBackoff(1)), delivery.NextAttemptAt, time.Second)
+
+	// Antes do backoff a entrega não é reenviada.
+	processed, err := testSvc.DeliverWebhooks(ctx, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	for i := 1; i < webhookMaxAttempts; i++ {
+		now = now.Add(webhookMaxBackoff)
+		_, err = testSvc.DeliverWebhooks(ctx, now)
+		require.NoError(t, err)
+	}
+	assert.Len(t, receiver.received, webhookMaxAttempts)
+
+	dead, err := testSvc.ListWebhookDeliveries(ctx, tenant.ID, WebhookDeliveryFilter{Status: domain.WebhookDeliveryDead})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+
+	retried, err := testSvc.RetryWebhookDelivery(ctx, tenant.ID, dead[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryPending, retried.Status)
+
+	_, err = testSvc.RetryWebhookDelivery(ctx, tenant.ID, dead[0].ID)
+	var transitionErr *InvalidTransitionError
+	assert.ErrorAs(t, err, &transitionErr)
+}
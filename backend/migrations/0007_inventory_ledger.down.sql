@@ -0,0 +1,37 @@
+DROP INDEX IF EXISTS idx_inventory_product_created;
+
+ALTER TABLE inventory_movements
+    DROP COLUMN IF EXISTS flagged,
+    DROP COLUMN IF EXISTS balance_after;
+
+CREATE OR REPLACE FUNCTION apply_inventory_movement() RETURNS TRIGGER AS $$
+DECLARE
+    current_stock INT;
+BEGIN
+    SELECT stock_qty INTO current_stock FROM products WHERE id = NEW.product_id FOR UPDATE;
+    IF current_stock IS NULL THEN
+        RAISE EXCEPTION 'Produto % não encontrado para movimento de estoque', NEW.product_id;
+    END IF;
+
+    IF NEW.type = 'in' THEN
+        current_stock := current_stock + NEW.quantity;
+    ELSIF NEW.type = 'out' THEN
+        IF current_stock < NEW.quantity THEN
+            RAISE EXCEPTION 'Estoque insuficiente para produto %', NEW.product_id;
+        END IF;
+        current_stock := current_stock - NEW.quantity;
+    ELSIF NEW.type = 'adjustment' THEN
+        current_stock := NEW.quantity;
+    ELSE
+        RAISE EXCEPTION 'Tipo de movimento inválido: %', NEW.type;
+    END IF;
+
+    UPDATE products SET stock_qty = current_stock, updated_at = NOW() WHERE id = NEW.product_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_inventory_movements
+AFTER INSERT ON inventory_movements
+FOR EACH ROW
+EXECUTE FUNCTION apply_inventory_movement();
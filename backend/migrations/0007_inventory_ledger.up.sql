@@ -0,0 +1,10 @@
+-- O saldo de estoque passa a ser mantido pela camada de serviço, que bloqueia a
+-- linha do produto e aplica a política de estoque negativo do tenant.
+DROP TRIGGER IF EXISTS trg_inventory_movements ON inventory_movements;
+DROP FUNCTION IF EXISTS apply_inventory_movement;
+
+ALTER TABLE inventory_movements
+    ADD COLUMN IF NOT EXISTS balance_after INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_inventory_product_created ON inventory_movements (tenant_id, product_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS flagged;
+
+ALTER TABLE sales_orders
+    DROP COLUMN IF EXISTS amount_paid;
@@ -0,0 +1,15 @@
+ALTER TABLE sales_orders
+    ADD COLUMN IF NOT EXISTS amount_paid NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE sales_orders so
+SET amount_paid = p.total
+FROM (
+    SELECT order_id, SUM(amount) AS total
+    FROM payments
+    WHERE deleted_at IS NULL
+    GROUP BY order_id
+) p
+WHERE p.order_id = so.id;
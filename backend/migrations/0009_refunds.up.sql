@@ -0,0 +1,25 @@
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS refunded_amount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    order_id UUID NOT NULL,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    reason TEXT,
+    refunded_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT fk_refunds_sales_orders_tenant FOREIGN KEY (tenant_id, order_id) REFERENCES sales_orders(tenant_id, id)
+);
+
+CREATE INDEX idx_refunds_order ON refunds (order_id);
+CREATE INDEX idx_refunds_payment ON refunds (payment_id);
+CREATE INDEX idx_refunds_tenant_refunded_at ON refunds (tenant_id, refunded_at);
+
+CREATE TRIGGER set_timestamp_refunds
+BEFORE UPDATE ON refunds
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
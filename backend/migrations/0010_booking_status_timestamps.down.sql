@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_bookings_client_start;
+DROP INDEX IF EXISTS idx_bookings_professional_start;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS no_show_at,
+    DROP COLUMN IF EXISTS canceled_at,
+    DROP COLUMN IF EXISTS completed_at,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS confirmed_at;
@@ -0,0 +1,9 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS confirmed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS no_show_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_professional_start ON bookings (tenant_id, professional_id, start_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_client_start ON bookings (tenant_id, client_id, start_at);
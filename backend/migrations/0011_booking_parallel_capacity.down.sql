@@ -0,0 +1,2 @@
+-- Falha se já existirem agendamentos paralelos no mesmo horário.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bookings_conflict ON bookings (tenant_id, professional_id, start_at) WHERE deleted_at IS NULL;
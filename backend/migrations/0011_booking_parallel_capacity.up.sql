@@ -0,0 +1,4 @@
+-- A capacidade por profissional (max_parallel) passa a ser verificada pelo service
+-- sob advisory lock; o índice único impedia atendimentos simultâneos. As consultas de
+-- sobreposição usam idx_bookings_professional_start (0010).
+DROP INDEX IF EXISTS idx_bookings_conflict;
@@ -0,0 +1,22 @@
+CREATE TABLE availability_exceptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    professional_id UUID,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('blocked', 'open')),
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT chk_availability_exceptions_range CHECK (end_at > start_at),
+    CONSTRAINT fk_availability_exceptions_professionals_tenant FOREIGN KEY (tenant_id, professional_id) REFERENCES professionals(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_availability_exceptions_tenant_range ON availability_exceptions (tenant_id, start_at, end_at);
+CREATE INDEX idx_availability_exceptions_professional ON availability_exceptions (professional_id);
+
+CREATE TRIGGER set_timestamp_availability_exceptions
+BEFORE UPDATE ON availability_exceptions
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
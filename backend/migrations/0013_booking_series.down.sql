@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookings_series;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS series_id;
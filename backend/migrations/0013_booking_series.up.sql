@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS series_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_series ON bookings (tenant_id, series_id, start_at)
+    WHERE series_id IS NOT NULL;
@@ -0,0 +1,32 @@
+CREATE TABLE waitlist_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    client_id UUID NOT NULL,
+    service_id UUID NOT NULL,
+    professional_id UUID,
+    desired_from TIMESTAMPTZ NOT NULL,
+    desired_to TIMESTAMPTZ NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'waiting',
+    notes TEXT,
+    offer_professional_id UUID,
+    offer_start_at TIMESTAMPTZ,
+    offer_end_at TIMESTAMPTZ,
+    offer_expires_at TIMESTAMPTZ,
+    booking_id UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT chk_waitlist_entries_window CHECK (desired_to > desired_from),
+    CONSTRAINT fk_waitlist_entries_clients_tenant FOREIGN KEY (tenant_id, client_id) REFERENCES clients(tenant_id, id),
+    CONSTRAINT fk_waitlist_entries_services_tenant FOREIGN KEY (tenant_id, service_id) REFERENCES services(tenant_id, id),
+    CONSTRAINT fk_waitlist_entries_professionals_tenant FOREIGN KEY (tenant_id, professional_id) REFERENCES professionals(tenant_id, id),
+    CONSTRAINT fk_waitlist_entries_bookings_tenant FOREIGN KEY (tenant_id, booking_id) REFERENCES bookings(tenant_id, id)
+);
+
+CREATE INDEX idx_waitlist_entries_queue ON waitlist_entries (tenant_id, service_id, status, created_at);
+CREATE INDEX idx_waitlist_entries_offers ON waitlist_entries (status, offer_expires_at) WHERE status = 'offered';
+
+CREATE TRIGGER set_timestamp_waitlist_entries
+BEFORE UPDATE ON waitlist_entries
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,24 @@
+CREATE TABLE booking_segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    booking_id UUID NOT NULL,
+    position INT NOT NULL,
+    service_id UUID NOT NULL,
+    professional_id UUID NOT NULL,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT uq_booking_segments_position UNIQUE (booking_id, position),
+    CONSTRAINT fk_booking_segments_bookings_tenant FOREIGN KEY (tenant_id, booking_id) REFERENCES bookings(tenant_id, id) ON DELETE CASCADE,
+    CONSTRAINT fk_booking_segments_services_tenant FOREIGN KEY (tenant_id, service_id) REFERENCES services(tenant_id, id),
+    CONSTRAINT fk_booking_segments_professionals_tenant FOREIGN KEY (tenant_id, professional_id) REFERENCES professionals(tenant_id, id)
+);
+
+CREATE INDEX idx_booking_segments_professional_start ON booking_segments (tenant_id, professional_id, start_at);
+
+CREATE TRIGGER set_timestamp_booking_segments
+BEFORE UPDATE ON booking_segments
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_audit_logs_entity_id;
+DROP INDEX IF EXISTS idx_audit_logs_actor;
+DROP INDEX IF EXISTS idx_audit_logs_tenant_created;
+
+DELETE FROM audit_logs WHERE actor_id IS NULL;
+
+ALTER TABLE audit_logs
+    DROP COLUMN deleted_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN request_id,
+    DROP COLUMN entity_id,
+    ALTER COLUMN actor_id SET NOT NULL;
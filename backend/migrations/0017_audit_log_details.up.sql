@@ -0,0 +1,10 @@
+ALTER TABLE audit_logs
+    ALTER COLUMN actor_id DROP NOT NULL,
+    ADD COLUMN entity_id UUID,
+    ADD COLUMN request_id VARCHAR(64),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_audit_logs_tenant_created ON audit_logs (tenant_id, created_at);
+CREATE INDEX idx_audit_logs_actor ON audit_logs (actor_id, created_at);
+CREATE INDEX idx_audit_logs_entity_id ON audit_logs (entity_id);
@@ -0,0 +1,24 @@
+CREATE TABLE booking_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    booking_id UUID NOT NULL,
+    offset_minutes INT NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    recipient VARCHAR(160),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT uq_booking_reminders_booking_offset_channel UNIQUE (booking_id, offset_minutes, channel),
+    CONSTRAINT fk_booking_reminders_bookings_tenant FOREIGN KEY (tenant_id, booking_id) REFERENCES bookings(tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_booking_reminders_retry ON booking_reminders (updated_at) WHERE status IN ('pending', 'failed');
+
+CREATE TRIGGER set_timestamp_booking_reminders
+BEFORE UPDATE ON booking_reminders
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
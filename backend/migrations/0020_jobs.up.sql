@@ -0,0 +1,28 @@
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID REFERENCES companies(id),
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    unique_key VARCHAR(160),
+    locked_at TIMESTAMPTZ,
+    locked_by VARCHAR(64),
+    last_error TEXT,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX uq_jobs_unique_key ON jobs (unique_key) WHERE status = 'pending' OR status = 'running';
+CREATE INDEX idx_jobs_ready ON jobs (run_at) WHERE status = 'pending';
+CREATE INDEX idx_jobs_running ON jobs (tenant_id, locked_at) WHERE status = 'running';
+CREATE INDEX idx_jobs_tenant_id ON jobs (tenant_id);
+
+CREATE TRIGGER set_timestamp_jobs
+BEFORE UPDATE ON jobs
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
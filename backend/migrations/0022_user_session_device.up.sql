@@ -0,0 +1 @@
+ALTER TABLE user_sessions ADD COLUMN device VARCHAR(64);
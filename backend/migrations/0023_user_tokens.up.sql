@@ -0,0 +1,23 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT uq_user_tokens_token_hash UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_user_tokens_tenant_id ON user_tokens (tenant_id);
+CREATE INDEX idx_user_tokens_user_pending ON user_tokens (user_id, purpose) WHERE used_at IS NULL;
+
+CREATE TRIGGER set_timestamp_user_tokens
+BEFORE UPDATE ON user_tokens
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,24 @@
+ALTER TABLE users
+    ADD COLUMN mfa_secret VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN mfa_enabled_at TIMESTAMPTZ,
+    ADD COLUMN mfa_last_step BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES companies(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT uq_user_recovery_codes_code_hash UNIQUE (code_hash)
+);
+
+CREATE INDEX idx_user_recovery_codes_tenant_id ON user_recovery_codes (tenant_id);
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes (user_id);
+
+CREATE TRIGGER set_timestamp_user_recovery_codes
+BEFORE UPDATE ON user_recovery_codes
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();
@@ -0,0 +1,19 @@
+CREATE TABLE login_throttles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(16) NOT NULL,
+    key VARCHAR(160) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT uq_login_throttles_scope_key UNIQUE (scope, key)
+);
+
+CREATE INDEX idx_login_throttles_last_failure_at ON login_throttles (last_failure_at);
+
+CREATE TRIGGER set_timestamp_login_throttles
+BEFORE UPDATE ON login_throttles
+FOR EACH ROW
+EXECUTE FUNCTION trigger_set_timestamp();